@@ -0,0 +1,260 @@
+package agg
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
+	"github.com/banbox/banexg/utils"
+	"go.uber.org/zap"
+	"sort"
+	"sync"
+)
+
+/*
+KlineHole
+a missing span of klines: [Start, End)
+缺失的K线区间：[Start, End)
+*/
+type KlineHole struct {
+	Start int64
+	End   int64
+}
+
+/*
+FetchOHLCVRange
+fetch klines in [start, end) page by page; limit is the page size, 0 uses the exchange default
+分页获取[start, end)范围内的K线；limit是每页数量，0使用交易所默认值
+*/
+func FetchOHLCVRange(exg banexg.BanExchange, symbol, timeFrame string, start, end int64, limit int,
+	params map[string]interface{}) ([]*banexg.Kline, *errs.Error) {
+	if _, err := safeTFSecs(timeFrame); err != nil {
+		return nil, err
+	}
+	it := banexg.IterOHLCV(context.Background(), exg, symbol, timeFrame, start, end-1, limit, params)
+	return it.All()
+}
+
+/*
+MergeKlines
+merge two kline lists, sorted by time and deduplicated; news overwrite olds for same time
+合并两个K线列表，按时间排序并去重；相同时间时news覆盖olds
+*/
+func MergeKlines(olds, news []*banexg.Kline) []*banexg.Kline {
+	byTime := make(map[int64]*banexg.Kline, len(olds)+len(news))
+	for _, k := range olds {
+		byTime[k.Time] = k
+	}
+	for _, k := range news {
+		byTime[k.Time] = k
+	}
+	res := make([]*banexg.Kline, 0, len(byTime))
+	for _, k := range byTime {
+		res = append(res, k)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Time < res[j].Time
+	})
+	return res
+}
+
+/*
+FindHoles
+find missing spans of sorted klines in [start, end). Time outside trading sessions is ignored,
+spans shorter than min1mHole minutes are regarded as no trading instead of holes.
+查找已排序K线在[start, end)中的缺失区间。忽略非交易时段，
+少于min1mHole分钟的缺失视为无交易而非空洞。
+*/
+func FindHoles(klines []*banexg.Kline, timeFrame string, start, end int64, market *banexg.Market, min1mHole int) []KlineHole {
+	tfSecs, err := safeTFSecs(timeFrame)
+	if err != nil {
+		return nil
+	}
+	tfMSecs := int64(tfSecs) * 1000
+	minHole := max(int64(min1mHole)*60000, tfMSecs)
+	var times [][2]int64
+	if market != nil && tfMSecs < dayMSecs {
+		times = market.GetTradeTimes()
+	}
+	var res []KlineHole
+	var holeStart, holeEnd int64
+	endHole := func() {
+		if holeEnd-holeStart >= minHole {
+			res = append(res, KlineHole{Start: holeStart, End: holeEnd})
+		}
+		holeStart, holeEnd = 0, 0
+	}
+	checkGap := func(from, to int64) {
+		for t := from; t < to; t += tfMSecs {
+			if !inTradeTimes(times, t) {
+				// 非交易时段打断空洞
+				endHole()
+				continue
+			}
+			if holeEnd != t {
+				endHole()
+				holeStart = t
+			}
+			holeEnd = t + tfMSecs
+		}
+	}
+	cur := start
+	for _, k := range klines {
+		if k.Time < cur {
+			continue
+		}
+		if k.Time >= end {
+			break
+		}
+		checkGap(cur, k.Time)
+		endHole()
+		cur = k.Time + tfMSecs
+	}
+	checkGap(cur, end)
+	endHole()
+	return res
+}
+
+func inTradeTimes(times [][2]int64, stamp int64) bool {
+	if len(times) == 0 {
+		return true
+	}
+	tod := stamp % dayMSecs
+	for _, rg := range times {
+		if tod >= rg[0] && tod < rg[1] || tod+dayMSecs >= rg[0] && tod+dayMSecs < rg[1] {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+FillHoles
+find holes of klines in [start, end), re-request them and merge. Return merged klines and holes which still missing.
+查找klines在[start, end)中的空洞，重新请求并合并。返回合并后的K线和仍然缺失的空洞。
+*/
+func FillHoles(exg banexg.BanExchange, symbol, timeFrame string, klines []*banexg.Kline, start, end int64,
+	params map[string]interface{}) ([]*banexg.Kline, []KlineHole, *errs.Error) {
+	market, err := exg.GetMarket(symbol)
+	if err != nil {
+		return klines, nil, err
+	}
+	min1mHole := exg.Info().Min1mHole
+	holes := FindHoles(klines, timeFrame, start, end, market, min1mHole)
+	if len(holes) == 0 {
+		return klines, nil, nil
+	}
+	for _, h := range holes {
+		page, err := FetchOHLCVRange(exg, symbol, timeFrame, h.Start, h.End, 0, params)
+		if err != nil {
+			return klines, holes, err
+		}
+		klines = MergeKlines(klines, page)
+	}
+	return klines, FindHoles(klines, timeFrame, start, end, market, min1mHole), nil
+}
+
+/*
+WatchOHLCVsFilled
+subscribe klines via WatchOHLCVs and output closed klines only. When holes (see FindHoles) are found after
+reconnect, missing klines are fetched by FetchOHLCV and output in order before the live one.
+Klines are never dropped: the output blocks when full, while the ws channel keeps being read into a queue.
+通过WatchOHLCVs订阅K线，仅输出已完成的K线。重连后发现空洞(见FindHoles)时，
+通过FetchOHLCV获取缺失的K线，并按顺序在实时K线前输出。
+K线不会丢弃：输出通道满时阻塞，websocket通道仍持续读取到队列中。
+*/
+func WatchOHLCVsFilled(exg banexg.BanExchange, jobs [][2]string, params map[string]interface{}) (chan *banexg.PairTFKline, *errs.Error) {
+	if len(jobs) == 0 {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "jobs is required for WatchOHLCVsFilled")
+	}
+	var args = utils.SafeParams(params)
+	chanCap := utils.PopMapVal(args, banexg.ParamChanCap, 100)
+	tfMSecs := make(map[string]int64)
+	markets := make(map[string]*banexg.Market)
+	for _, job := range jobs {
+		secs, err := safeTFSecs(job[1])
+		if err != nil {
+			return nil, err
+		}
+		tfMSecs[job[1]] = int64(secs) * 1000
+		market, err := exg.GetMarket(job[0])
+		if err != nil {
+			return nil, err
+		}
+		markets[job[0]] = market
+	}
+	min1mHole := exg.Info().Min1mHole
+	klines, err := exg.WatchOHLCVs(jobs, args)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *banexg.PairTFKline, chanCap)
+	// 读取websocket到队列，避免补全请求期间阻塞websocket通道
+	var lock sync.Mutex
+	var queue []*banexg.PairTFKline
+	var done bool
+	notify := make(chan struct{}, 1)
+	wake := func() {
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	}
+	go func() {
+		for k := range klines {
+			lock.Lock()
+			queue = append(queue, k)
+			lock.Unlock()
+			wake()
+		}
+		lock.Lock()
+		done = true
+		lock.Unlock()
+		wake()
+	}()
+	lives := make(map[string]*banexg.PairTFKline)
+	handle := func(k *banexg.PairTFKline) {
+		tfMS, ok := tfMSecs[k.TimeFrame]
+		if !ok {
+			return
+		}
+		key := k.Symbol + "_" + k.TimeFrame
+		last, ok := lives[key]
+		if ok && k.Time < last.Time {
+			return
+		}
+		lives[key] = k
+		if !ok || k.Time == last.Time {
+			return
+		}
+		// 收到新K线，之前的K线已完成
+		out <- last
+		holes := FindHoles(nil, k.TimeFrame, last.Time+tfMS, k.Time, markets[k.Symbol], min1mHole)
+		for _, h := range holes {
+			gaps, err := FetchOHLCVRange(exg, k.Symbol, k.TimeFrame, h.Start, h.End, 0, args)
+			if err != nil {
+				log.Warn("fill kline gap fail", zap.String("pair", k.Symbol), zap.String("tf", k.TimeFrame),
+					zap.Error(err))
+			}
+			for _, g := range gaps {
+				out <- &banexg.PairTFKline{Kline: *g, Symbol: k.Symbol, TimeFrame: k.TimeFrame}
+			}
+		}
+	}
+	go func() {
+		defer close(out)
+		for range notify {
+			lock.Lock()
+			items, finished := queue, done
+			queue = nil
+			lock.Unlock()
+			for _, k := range items {
+				handle(k)
+			}
+			if finished {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,134 @@
+package agg
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"testing"
+)
+
+const testStart = int64(1699920000000) // 00:00 UTC
+
+// fakeExg 返回每分钟一根K线，每页最多pageSize根
+type fakeExg struct {
+	*banexg.Exchange
+	pageSize int
+	calls    int
+	account  string
+	live     chan *banexg.PairTFKline
+}
+
+func (e *fakeExg) FetchOHLCV(symbol, timeframe string, since int64, limit int, params map[string]interface{}) ([]*banexg.Kline, *errs.Error) {
+	e.calls += 1
+	e.account, _ = params[banexg.ParamAccount].(string)
+	until, _ := params[banexg.ParamUntil].(int64)
+	var res []*banexg.Kline
+	for t := since; t <= until && len(res) < e.pageSize; t += 60000 {
+		res = append(res, &banexg.Kline{Time: t, Close: float64(t)})
+	}
+	return res, nil
+}
+
+func (e *fakeExg) GetMarket(symbol string) (*banexg.Market, *errs.Error) {
+	return &banexg.Market{Symbol: symbol}, nil
+}
+
+func (e *fakeExg) WatchOHLCVs(jobs [][2]string, params map[string]interface{}) (chan *banexg.PairTFKline, *errs.Error) {
+	return e.live, nil
+}
+
+func makeKlines(mins ...int64) []*banexg.Kline {
+	res := make([]*banexg.Kline, 0, len(mins))
+	for _, m := range mins {
+		res = append(res, &banexg.Kline{Time: testStart + m*60000})
+	}
+	return res
+}
+
+func TestFetchOHLCVRange(t *testing.T) {
+	exg := &fakeExg{Exchange: &banexg.Exchange{ExgInfo: &banexg.ExgInfo{Min1mHole: 1}}, pageSize: 7}
+	res, err := FetchOHLCVRange(exg, "BTC/USDT", "1m", testStart, testStart+20*60000, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 20 || exg.calls != 3 {
+		t.Errorf("bad page result: %v, calls: %v", len(res), exg.calls)
+	}
+	for i, k := range res {
+		if k.Time != testStart+int64(i)*60000 {
+			t.Errorf("bad kline time at %d", i)
+		}
+	}
+}
+
+func TestFindHoles(t *testing.T) {
+	klines := makeKlines(0, 1, 3, 4, 8, 9)
+	holes := FindHoles(klines, "1m", testStart, testStart+12*60000, nil, 1)
+	expect := []KlineHole{
+		{testStart + 2*60000, testStart + 3*60000},
+		{testStart + 5*60000, testStart + 8*60000},
+		{testStart + 10*60000, testStart + 12*60000},
+	}
+	if len(holes) != len(expect) {
+		t.Fatalf("bad holes: %v", holes)
+	}
+	for i, h := range holes {
+		if h != expect[i] {
+			t.Errorf("bad hole %d: %v, expect %v", i, h, expect[i])
+		}
+	}
+	// 少于2分钟的缺失视为无交易
+	holes = FindHoles(klines, "1m", testStart, testStart+12*60000, nil, 2)
+	if len(holes) != 2 {
+		t.Errorf("bad holes with min1mHole: %v", holes)
+	}
+	// 交易时段 00:00-00:06，之后的缺失不是空洞
+	mar := &banexg.Market{DayTimes: [][2]int64{{0, 6 * 60000}}}
+	holes = FindHoles(klines, "1m", testStart, testStart+12*60000, mar, 1)
+	if len(holes) != 2 || holes[1].End != testStart+6*60000 {
+		t.Errorf("bad holes with sessions: %v", holes)
+	}
+}
+
+func TestFillHoles(t *testing.T) {
+	exg := &fakeExg{Exchange: &banexg.Exchange{ExgInfo: &banexg.ExgInfo{Min1mHole: 1}}, pageSize: 100}
+	klines := makeKlines(0, 1, 3, 4, 8, 9)
+	res, holes, err := FillHoles(exg, "BTC/USDT", "1m", klines, testStart, testStart+12*60000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holes) != 0 || len(res) != 12 {
+		t.Errorf("fill holes fail: %v %v", len(res), holes)
+	}
+}
+
+func TestWatchOHLCVsFilled(t *testing.T) {
+	exg := &fakeExg{Exchange: &banexg.Exchange{ExgInfo: &banexg.ExgInfo{Min1mHole: 2}}, pageSize: 100,
+		live: make(chan *banexg.PairTFKline, 10)}
+	out, err := WatchOHLCVsFilled(exg, [][2]string{{"BTC/USDT", "1m"}}, map[string]interface{}{
+		banexg.ParamAccount: "acc1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 缺失1分钟小于Min1mHole，不请求；缺失3分钟需补全
+	for _, k := range makeKlines(0, 1, 3, 7, 8) {
+		exg.live <- &banexg.PairTFKline{Kline: *k, Symbol: "BTC/USDT", TimeFrame: "1m"}
+	}
+	close(exg.live)
+	var times []int64
+	for k := range out {
+		times = append(times, (k.Time-testStart)/60000)
+	}
+	expect := []int64{0, 1, 3, 4, 5, 6, 7}
+	if len(times) != len(expect) {
+		t.Fatalf("bad filled klines: %v", times)
+	}
+	for i, v := range expect {
+		if times[i] != v {
+			t.Fatalf("bad filled klines: %v", times)
+		}
+	}
+	if exg.calls != 1 || exg.account != "acc1" {
+		t.Errorf("bad fill request, calls: %v, account: %v", exg.calls, exg.account)
+	}
+}
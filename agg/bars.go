@@ -0,0 +1,219 @@
+package agg
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+)
+
+/*
+BarBuilder
+aggregate trades of one symbol into bars of the specified kind, not thread safe
+将单个品种的成交聚合为指定类型的K线，非线程安全
+*/
+type BarBuilder struct {
+	Symbol    string
+	Kind      string
+	TimeFrame string
+	Size      float64
+	tfMSecs   int64
+	cur       *Bar
+	cvd       float64
+	sumVol    float64
+	sumCost   float64
+}
+
+func NewBarBuilder(job *BarJob) (*BarBuilder, *errs.Error) {
+	if job == nil || job.Symbol == "" {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "symbol is required for BarBuilder")
+	}
+	res := &BarBuilder{
+		Symbol: job.Symbol,
+		Kind:   job.Kind,
+		Size:   job.Size,
+	}
+	switch job.Kind {
+	case BarTime:
+		if job.TimeFrame == "" {
+			return nil, errs.NewMsg(errs.CodeParamRequired, "timeFrame is required for time bars")
+		}
+		tfSecs, err := safeTFSecs(job.TimeFrame)
+		if err != nil {
+			return nil, err
+		}
+		res.TimeFrame = job.TimeFrame
+		res.tfMSecs = int64(tfSecs) * 1000
+	case BarTick, BarVolume, BarDollar:
+		if job.Size <= 0 {
+			return nil, errs.NewMsg(errs.CodeParamInvalid, "size must > 0 for %s bars", job.Kind)
+		}
+		res.TimeFrame = job.Kind + ":" + strconv.FormatFloat(job.Size, 'f', -1, 64)
+	default:
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "unsupported bar kind: %s", job.Kind)
+	}
+	return res, nil
+}
+
+// safeTFSecs 校验周期后再调用TFToSecs，避免其panic
+func safeTFSecs(timeFrame string) (int, *errs.Error) {
+	if _, err := utils.ParseTimeFrame(timeFrame); err != nil {
+		return 0, err
+	}
+	return utils.TFToSecs(timeFrame), nil
+}
+
+/*
+Add
+feed a trade, return bars finished by this trade. trades without a valid 13 digit timestamp are ignored by time bars
+输入一笔成交，返回因此完成的K线。时间K线忽略没有有效13位时间戳的成交
+*/
+func (b *BarBuilder) Add(t *banexg.Trade) []*Bar {
+	if t == nil || t.Amount <= 0 {
+		return nil
+	}
+	var res []*Bar
+	if b.Kind == BarTime {
+		if t.Timestamp < 100000000000 {
+			return nil
+		}
+		barTime := utils.AlignTfMSecs(t.Timestamp, b.tfMSecs)
+		if b.cur != nil && barTime > b.cur.Time {
+			res = append(res, b.finish())
+		}
+		if b.cur == nil {
+			b.cur = b.newBar(barTime, t.Price)
+		}
+		b.update(t, t.Amount, true)
+		return res
+	}
+	amount := t.Amount
+	// 拆分的成交只计入起始Bar的笔数
+	first := true
+	for amount > 0 {
+		if b.cur == nil {
+			b.cur = b.newBar(t.Timestamp, t.Price)
+		}
+		part := amount
+		// 单笔成交超出剩余阈值时，拆分到多个Bar
+		switch b.Kind {
+		case BarVolume:
+			part = min(amount, b.Size-b.cur.Volume)
+		case BarDollar:
+			part = min(amount, (b.Size-b.cur.Info)/t.Price)
+		}
+		if part <= 0 || amount-part < amount*1e-12 {
+			part = amount
+		}
+		b.update(t, part, first)
+		first = false
+		amount -= part
+		if b.isFull() {
+			res = append(res, b.finish())
+		}
+	}
+	return res
+}
+
+func (b *BarBuilder) newBar(stamp int64, price float64) *Bar {
+	return &Bar{
+		PairTFKline: banexg.PairTFKline{
+			Kline: banexg.Kline{
+				Time:  stamp,
+				Open:  price,
+				High:  price,
+				Low:   price,
+				Close: price,
+			},
+			Symbol:    b.Symbol,
+			TimeFrame: b.TimeFrame,
+		},
+		Kind: b.Kind,
+	}
+}
+
+func (b *BarBuilder) update(t *banexg.Trade, amount float64, first bool) {
+	bar := b.cur
+	price := t.Price
+	cost := price * amount
+	bar.High = max(bar.High, price)
+	bar.Low = min(bar.Low, price)
+	bar.Close = price
+	bar.Volume += amount
+	bar.Info += cost
+	if first {
+		bar.Count += 1
+	}
+	bar.EndTime = t.Timestamp
+	delta := -amount
+	if t.Side == banexg.OdSideBuy {
+		bar.BuyVolume += amount
+		delta = amount
+	}
+	bar.Delta += delta
+	b.cvd += delta
+	bar.CVD = b.cvd
+	if bar.Volume > 0 {
+		bar.VWAP = bar.Info / bar.Volume
+	}
+	b.sumVol += amount
+	b.sumCost += cost
+}
+
+func (b *BarBuilder) isFull() bool {
+	bar := b.cur
+	switch b.Kind {
+	case BarTick:
+		return float64(bar.Count) >= b.Size
+	case BarVolume:
+		return bar.Volume >= b.Size*(1-1e-12)
+	case BarDollar:
+		return bar.Info >= b.Size*(1-1e-12)
+	}
+	return false
+}
+
+func (b *BarBuilder) finish() *Bar {
+	res := b.cur
+	b.cur = nil
+	return res
+}
+
+/*
+Flush
+return and reset the unfinished bar, nil if none
+返回并清空未完成的K线，没有时返回nil
+*/
+func (b *BarBuilder) Flush() *Bar {
+	return b.finish()
+}
+
+/*
+Current
+the unfinished bar, do not modify it
+当前未完成的K线，请勿修改
+*/
+func (b *BarBuilder) Current() *Bar {
+	return b.cur
+}
+
+/*
+CVD
+cumulative volume delta since the builder was created
+从创建以来的累计成交量差
+*/
+func (b *BarBuilder) CVD() float64 {
+	return b.cvd
+}
+
+/*
+VWAP
+volume weighted average price since the builder was created
+从创建以来的成交量加权均价
+*/
+func (b *BarBuilder) VWAP() float64 {
+	if b.sumVol == 0 {
+		return 0
+	}
+	return b.sumCost / b.sumVol
+}
@@ -0,0 +1,131 @@
+package agg
+
+import (
+	"github.com/banbox/banexg"
+	"math"
+	"testing"
+)
+
+func makeTrades() []*banexg.Trade {
+	start := int64(1699999980000)
+	items := [][3]float64{
+		// offset secs, price, amount(>0 buy, <0 sell)
+		{1, 100, 1},
+		{20, 102, -2},
+		{59, 101, 3},
+		{61, 103, 1},
+		{130, 99, -4},
+	}
+	res := make([]*banexg.Trade, 0, len(items))
+	for _, it := range items {
+		side := banexg.OdSideBuy
+		amount := it[2]
+		if amount < 0 {
+			side = banexg.OdSideSell
+			amount = -amount
+		}
+		res = append(res, &banexg.Trade{
+			Symbol:    "BTC/USDT",
+			Side:      side,
+			Price:     it[1],
+			Amount:    amount,
+			Timestamp: start + int64(it[0])*1000,
+		})
+	}
+	return res
+}
+
+func runBuilder(t *testing.T, job *BarJob) ([]*Bar, *BarBuilder) {
+	b, err := NewBarBuilder(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []*Bar
+	for _, td := range makeTrades() {
+		res = append(res, b.Add(td)...)
+	}
+	return res, b
+}
+
+func TestTimeBars(t *testing.T) {
+	bars, b := runBuilder(t, &BarJob{Symbol: "BTC/USDT", Kind: BarTime, TimeFrame: "1m"})
+	if len(bars) != 2 {
+		t.Fatalf("expect 2 bars, got %d", len(bars))
+	}
+	first := bars[0]
+	if first.Open != 100 || first.High != 102 || first.Low != 100 || first.Close != 101 || first.Volume != 6 {
+		t.Errorf("bad first bar: %+v", first.Kline)
+	}
+	if first.Delta != 2 || first.CVD != 2 || first.Count != 3 {
+		t.Errorf("bad first delta: %v %v %v", first.Delta, first.CVD, first.Count)
+	}
+	if first.Time%60000 != 0 || first.TimeFrame != "1m" {
+		t.Errorf("bad first time: %v %v", first.Time, first.TimeFrame)
+	}
+	if b.CVD() != -1 {
+		t.Errorf("bad cvd: %v", b.CVD())
+	}
+	vwap := (100*1 + 102*2 + 101*3 + 103*1 + 99*4) / 11.
+	if math.Abs(b.VWAP()-vwap) > 1e-9 {
+		t.Errorf("bad vwap: %v, expect %v", b.VWAP(), vwap)
+	}
+	if cur := b.Flush(); cur == nil || cur.Volume != 4 {
+		t.Errorf("bad flush bar: %+v", cur)
+	}
+	if res := b.Add(&banexg.Trade{Symbol: "BTC/USDT", Price: 100, Amount: 1}); res != nil || b.Current() != nil {
+		t.Errorf("trade without timestamp should be ignored")
+	}
+}
+
+func TestTickBars(t *testing.T) {
+	bars, b := runBuilder(t, &BarJob{Symbol: "BTC/USDT", Kind: BarTick, Size: 2})
+	if len(bars) != 2 || bars[0].Volume != 3 || bars[1].Volume != 4 {
+		t.Errorf("bad tick bars: %v", len(bars))
+	}
+	if b.Current() == nil || b.Current().Count != 1 {
+		t.Errorf("bad tick current bar")
+	}
+}
+
+func TestVolumeBars(t *testing.T) {
+	bars, b := runBuilder(t, &BarJob{Symbol: "BTC/USDT", Kind: BarVolume, Size: 4})
+	if len(bars) != 2 {
+		t.Fatalf("expect 2 bars, got %d", len(bars))
+	}
+	for _, bar := range bars {
+		if math.Abs(bar.Volume-4) > 1e-9 {
+			t.Errorf("bad volume bar: %v", bar.Volume)
+		}
+	}
+	// 第二个Bar: 3@101(剩余2) + 1@103 + 1@99(拆分)
+	if bars[0].Count != 3 || bars[1].Count != 2 || b.Current().Count != 0 {
+		t.Errorf("split trades should be counted in the starting bar: %v %v", bars[0].Count, bars[1].Count)
+	}
+	if bars[1].Open != 101 || bars[1].Close != 99 || bars[1].Delta != 2 {
+		t.Errorf("bad second bar: %+v %v", bars[1].Kline, bars[1].Delta)
+	}
+	if cur := b.Current(); cur == nil || math.Abs(cur.Volume-3) > 1e-9 {
+		t.Errorf("bad current volume bar")
+	}
+}
+
+func TestDollarBars(t *testing.T) {
+	bars, _ := runBuilder(t, &BarJob{Symbol: "BTC/USDT", Kind: BarDollar, Size: 300})
+	if len(bars) != 3 {
+		t.Fatalf("expect 3 bars, got %d", len(bars))
+	}
+	for _, bar := range bars {
+		if math.Abs(bar.Info-300) > 1e-9 {
+			t.Errorf("bad dollar bar: %v", bar.Info)
+		}
+	}
+}
+
+func TestBadJob(t *testing.T) {
+	if _, err := NewBarBuilder(&BarJob{Symbol: "BTC/USDT", Kind: BarVolume}); err == nil {
+		t.Errorf("expect err for zero size")
+	}
+	if _, err := NewBarBuilder(&BarJob{Symbol: "BTC/USDT", Kind: BarTime, TimeFrame: "1x"}); err == nil {
+		t.Errorf("expect err for bad timeFrame")
+	}
+}
@@ -0,0 +1,165 @@
+package agg
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+)
+
+const dayMSecs = int64(utils.SecsDay * 1000)
+
+/*
+TFKline
+resampled kline; Closed is false for real-time updates of the unfinished bar
+重采样后的K线；未完成K线的实时更新Closed为false
+*/
+type TFKline struct {
+	banexg.PairTFKline
+	Closed bool
+}
+
+/*
+Resampler
+merge base timeframe klines of one symbol into a larger timeframe, not thread safe.
+For markets with trading sessions (intraday timeframe), bars are aligned to session start.
+将单个品种的基础周期K线合并为更大周期，非线程安全。
+对有交易时段的市场(日内周期)，K线按时段开始时间对齐。
+*/
+type Resampler struct {
+	Symbol    string
+	TimeFrame string
+	tfMSecs   int64
+	offset    int64
+	times     [][2]int64
+	barTime   int64
+	done      *banexg.Kline // 当前大周期内已完成的基础K线合并结果
+	base      *banexg.Kline // 最新的未完成基础K线
+}
+
+/*
+NewResampler
+timeFrame must be a multiple of baseTF; market is optional and used for trading sessions
+timeFrame必须是baseTF的整数倍；market可为nil，用于获取交易时段
+*/
+func NewResampler(symbol, baseTF, timeFrame string, market *banexg.Market) (*Resampler, *errs.Error) {
+	baseSecs, err := safeTFSecs(baseTF)
+	if err != nil {
+		return nil, err
+	}
+	tfSecs, err := safeTFSecs(timeFrame)
+	if err != nil {
+		return nil, err
+	}
+	if tfSecs%baseSecs != 0 {
+		return nil, errs.NewMsg(errs.CodeInvalidTimeFrame, "%s is not multiple of %s", timeFrame, baseTF)
+	}
+	_, offset := utils.GetTfAlignOrigin(tfSecs)
+	res := &Resampler{
+		Symbol:    symbol,
+		TimeFrame: timeFrame,
+		tfMSecs:   int64(tfSecs) * 1000,
+		offset:    int64(offset) * 1000,
+	}
+	if market != nil && res.tfMSecs < dayMSecs {
+		res.times = market.GetTradeTimes()
+	}
+	return res, nil
+}
+
+/*
+AlignTime
+return the start timestamp(13 digits) of the bar which contains stamp
+返回包含stamp的K线的开始时间戳(13位)
+*/
+func (r *Resampler) AlignTime(stamp int64) int64 {
+	if len(r.times) > 0 {
+		dayStart := stamp / dayMSecs * dayMSecs
+		tod := stamp - dayStart
+		for _, rg := range r.times {
+			// 跨越零点的时段，结束时间大于一天
+			for _, shift := range []int64{0, dayMSecs} {
+				t := tod + shift
+				if t >= rg[0] && t < rg[1] {
+					return dayStart - shift + rg[0] + (t-rg[0])/r.tfMSecs*r.tfMSecs
+				}
+			}
+		}
+	}
+	return utils.AlignTfMSecsOffset(stamp, r.tfMSecs, r.offset)
+}
+
+/*
+Update
+feed a base kline (repeated updates with same Time are allowed).
+Return the bar closed by this kline (nil if none), and the current unfinished bar.
+A base kline is treated as finished when a kline with a later Time arrives.
+输入一个基础K线(允许相同Time的重复更新)。
+返回因此完成的K线(没有则nil)，以及当前未完成的K线。
+收到更新Time的K线时，之前的基础K线视为已完成。
+*/
+func (r *Resampler) Update(k *banexg.Kline) (*banexg.Kline, *banexg.Kline) {
+	if k == nil {
+		return nil, nil
+	}
+	if r.base != nil && k.Time < r.base.Time {
+		// 忽略过期的K线
+		return nil, r.Current()
+	}
+	if r.base != nil && k.Time > r.base.Time {
+		r.done = mergeKline(r.done, r.base)
+		r.base = nil
+	}
+	var closed *banexg.Kline
+	barTime := r.AlignTime(k.Time)
+	if r.done != nil && barTime != r.barTime {
+		closed = r.done
+		closed.Time = r.barTime
+		r.done = nil
+	}
+	r.barTime = barTime
+	base := *k
+	r.base = &base
+	return closed, r.Current()
+}
+
+/*
+Current
+the unfinished bar including the latest base kline, nil if none
+包含最新基础K线的未完成K线，没有时返回nil
+*/
+func (r *Resampler) Current() *banexg.Kline {
+	if r.done == nil && r.base == nil {
+		return nil
+	}
+	res := mergeKline(nil, r.done)
+	res = mergeKline(res, r.base)
+	res.Time = r.barTime
+	return res
+}
+
+/*
+Flush
+return the unfinished bar as closed and reset
+将未完成K线作为已完成返回并重置
+*/
+func (r *Resampler) Flush() *banexg.Kline {
+	res := r.Current()
+	r.done = nil
+	r.base = nil
+	return res
+}
+
+func mergeKline(dst, k *banexg.Kline) *banexg.Kline {
+	if k == nil {
+		return dst
+	}
+	if dst == nil {
+		return k.Clone()
+	}
+	dst.High = max(dst.High, k.High)
+	dst.Low = min(dst.Low, k.Low)
+	dst.Close = k.Close
+	dst.Volume += k.Volume
+	dst.Info += k.Info
+	return dst
+}
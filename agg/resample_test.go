@@ -0,0 +1,69 @@
+package agg
+
+import (
+	"github.com/banbox/banexg"
+	"testing"
+)
+
+func TestResample(t *testing.T) {
+	r, err := NewResampler("BTC/USDT", "1m", "3m", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := int64(1699999920000) // 3m对齐
+	var closed []*banexg.Kline
+	for i := 0; i < 7; i++ {
+		stamp := start + int64(i)*60000
+		price := float64(100 + i)
+		// 同一根K线的两次更新，第二次为最终值
+		done, cur := r.Update(&banexg.Kline{Time: stamp, Open: price, High: price, Low: price, Close: price, Volume: 1})
+		if done != nil {
+			closed = append(closed, done)
+		}
+		done, cur = r.Update(&banexg.Kline{Time: stamp, Open: price, High: price + 1, Low: price - 1, Close: price, Volume: 2})
+		if done != nil {
+			closed = append(closed, done)
+		}
+		if cur == nil || cur.Time != r.AlignTime(stamp) {
+			t.Errorf("bad current bar at %d", i)
+		}
+	}
+	if len(closed) != 2 {
+		t.Fatalf("expect 2 closed, got %d", len(closed))
+	}
+	k := closed[0]
+	if k.Time != start || k.Open != 100 || k.High != 103 || k.Low != 99 || k.Close != 102 || k.Volume != 6 {
+		t.Errorf("bad first bar: %+v", k)
+	}
+	if closed[1].Time != start+180000 || closed[1].Volume != 6 {
+		t.Errorf("bad second bar: %+v", closed[1])
+	}
+	if cur := r.Flush(); cur == nil || cur.Time != start+360000 || cur.Volume != 2 {
+		t.Errorf("bad flush bar: %+v", cur)
+	}
+	if _, err = NewResampler("BTC/USDT", "2m", "3m", nil); err == nil {
+		t.Errorf("expect err for non multiple timeframe")
+	}
+}
+
+func TestResampleSession(t *testing.T) {
+	// 交易时段 01:30-03:30(UTC)，1h K线应从01:30对齐
+	mar := &banexg.Market{DayTimes: [][2]int64{{90 * 60000, 210 * 60000}}}
+	r, err := NewResampler("rb2410", "1m", "1h", mar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := int64(1699920000000) // 00:00 UTC
+	if got := r.AlignTime(day + 100*60000); got != day+90*60000 {
+		t.Errorf("bad session align: %v", got)
+	}
+	if got := r.AlignTime(day + 160*60000); got != day+150*60000 {
+		t.Errorf("bad session align: %v", got)
+	}
+	// 跨越零点的夜盘 21:00-01:00
+	mar = &banexg.Market{NightTimes: [][2]int64{{21 * 60 * 60000, 25 * 60 * 60000}}}
+	r, _ = NewResampler("rb2410", "1m", "2h", mar)
+	if got := r.AlignTime(day + 30*60000); got != day-3*60*60000+2*60*60000 {
+		t.Errorf("bad night align: %v", got)
+	}
+}
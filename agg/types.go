@@ -0,0 +1,38 @@
+package agg
+
+import "github.com/banbox/banexg"
+
+const (
+	BarTime   = "time"   // 按时间周期聚合
+	BarTick   = "tick"   // 按成交笔数聚合
+	BarVolume = "volume" // 按成交数量聚合
+	BarDollar = "dollar" // 按成交金额聚合
+)
+
+/*
+BarJob
+a bar subscription: for time bars Size is ignored and TimeFrame is used; for others Size is the threshold
+一个K线订阅任务：时间K线使用TimeFrame；其他类型使用Size作为阈值
+*/
+type BarJob struct {
+	Symbol    string
+	Kind      string
+	TimeFrame string
+	Size      float64
+}
+
+/*
+Bar
+aggregated bar built from trades. Kline.Info stores quote turnover
+由成交聚合得到的K线，Kline.Info存储成交额
+*/
+type Bar struct {
+	banexg.PairTFKline
+	Kind      string
+	EndTime   int64   // 最后一笔成交的时间戳
+	Count     int     // 成交笔数，跨多个Bar的成交只计入起始Bar
+	BuyVolume float64 // 主动买入数量
+	Delta     float64 // 主动买入-主动卖出数量
+	CVD       float64 // 累计成交量差，截止此Bar
+	VWAP      float64 // 此Bar的成交量加权均价
+}
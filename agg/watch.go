@@ -0,0 +1,121 @@
+package agg
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+)
+
+/*
+WatchBars
+subscribe trades of all job symbols via WatchTrades, and output aggregated bars.
+The returned channel is closed when the trade channel is closed (e.g. after UnWatchTrades).
+Time bars are emitted when the first trade of next bar arrives. Bars are never dropped, the output blocks when full.
+Order book input is not supported: exchanges only provide price-level books without per-order(L3) data.
+通过WatchTrades订阅所有任务的品种成交，输出聚合后的K线。
+成交通道关闭时(如调用UnWatchTrades)，返回的通道也会关闭。
+时间K线在下一周期首笔成交到达时才输出。K线不会丢弃，输出通道满时阻塞等待。
+不支持订单簿输入：交易所仅提供价格档位订单簿，没有逐笔订单(L3)数据。
+*/
+func WatchBars(exg banexg.BanExchange, jobs []*BarJob, params map[string]interface{}) (chan *Bar, *errs.Error) {
+	if len(jobs) == 0 {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "jobs is required for WatchBars")
+	}
+	var args = utils.SafeParams(params)
+	chanCap := utils.PopMapVal(args, banexg.ParamChanCap, 100)
+	builders := make(map[string][]*BarBuilder)
+	symbols := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		b, err := NewBarBuilder(job)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := builders[job.Symbol]; !ok {
+			symbols = append(symbols, job.Symbol)
+		}
+		builders[job.Symbol] = append(builders[job.Symbol], b)
+	}
+	trades, err := exg.WatchTrades(symbols, args)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *Bar, chanCap)
+	go func() {
+		defer close(out)
+		for t := range trades {
+			for _, b := range builders[t.Symbol] {
+				for _, bar := range b.Add(t) {
+					out <- bar
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+/*
+WatchKlines
+subscribe baseTF klines once for each symbol via WatchOHLCVs, and output klines of any multiple timeframe.
+jobs: [][symbol, timeFrame]. Both closed bars and updates of unfinished bars are output, check TFKline.Closed.
+通过WatchOHLCVs为每个品种订阅一次基础周期K线，输出任意整数倍周期的K线。
+jobs: [][品种, 周期]。已完成K线和未完成K线的更新都会输出，通过TFKline.Closed区分。
+*/
+func WatchKlines(exg banexg.BanExchange, baseTF string, jobs [][2]string, params map[string]interface{}) (chan *TFKline, *errs.Error) {
+	if len(jobs) == 0 {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "jobs is required for WatchKlines")
+	}
+	var args = utils.SafeParams(params)
+	chanCap := utils.PopMapVal(args, banexg.ParamChanCap, 100)
+	samplers := make(map[string][]*Resampler)
+	baseJobs := make([][2]string, 0, len(jobs))
+	for _, job := range jobs {
+		symbol := job[0]
+		market, err := exg.GetMarket(symbol)
+		if err != nil {
+			return nil, err
+		}
+		r, err := NewResampler(symbol, baseTF, job[1], market)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := samplers[symbol]; !ok {
+			baseJobs = append(baseJobs, [2]string{symbol, baseTF})
+		}
+		samplers[symbol] = append(samplers[symbol], r)
+	}
+	klines, err := exg.WatchOHLCVs(baseJobs, args)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *TFKline, chanCap)
+	go func() {
+		defer close(out)
+		for k := range klines {
+			if k.TimeFrame != baseTF {
+				continue
+			}
+			for _, r := range samplers[k.Symbol] {
+				closed, cur := r.Update(&k.Kline)
+				if closed != nil {
+					writeTFKline(out, r, closed, true)
+				}
+				if cur != nil {
+					writeTFKline(out, r, cur, false)
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func writeTFKline(out chan *TFKline, r *Resampler, k *banexg.Kline, closed bool) {
+	item := &TFKline{
+		PairTFKline: banexg.PairTFKline{
+			Kline:     *k,
+			Symbol:    r.Symbol,
+			TimeFrame: r.TimeFrame,
+		},
+		Closed: closed,
+	}
+	out <- item
+}
@@ -0,0 +1,299 @@
+package algo
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
+	"github.com/banbox/banexg/utils"
+	"go.uber.org/zap"
+	"maps"
+	"sync"
+	"time"
+)
+
+/*
+Runner
+a running algo job, progress is sent to Out, which is closed when the job finished or canceled
+运行中的算法任务，进度发送到Out，任务结束或取消后Out被关闭
+*/
+type Runner struct {
+	ID  string
+	Job *Job
+	Exg banexg.BanExchange
+	Out chan *Progress
+
+	market   *banexg.Market
+	params   map[string]interface{}
+	prog     Progress
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+/*
+Start
+validate job and run it in background; params are passed to child orders, job.Params override them
+校验任务并在后台运行；params传给子订单，job.Params优先
+*/
+func Start(exg banexg.BanExchange, job *Job, params map[string]interface{}) (*Runner, *errs.Error) {
+	if job == nil || job.Symbol == "" || job.Amount <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "symbol and amount are required for algo job")
+	}
+	if job.Side != banexg.OdSideBuy && job.Side != banexg.OdSideSell {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "invalid side for algo job: %s", job.Side)
+	}
+	market, err := exg.GetMarket(job.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	args := utils.SafeParams(params)
+	chanCap := utils.PopMapVal(args, banexg.ParamChanCap, 100)
+	maps.Copy(args, job.Params)
+	r := &Runner{
+		ID:     "algo" + utils.UUID(10),
+		Job:    job,
+		Exg:    exg,
+		Out:    make(chan *Progress, chanCap),
+		market: market,
+		params: args,
+		stop:   make(chan struct{}),
+	}
+	r.prog = Progress{ID: r.ID, Kind: job.Kind, Symbol: job.Symbol, Status: StatusRunning, Total: job.Amount}
+	var run func()
+	switch job.Kind {
+	case KindTWAP, KindVWAP:
+		if job.Slices <= 0 || job.Duration <= 0 {
+			return nil, errs.NewMsg(errs.CodeParamRequired, "slices and duration are required for %s", job.Kind)
+		}
+		weights := job.Weights
+		if job.Kind == KindTWAP {
+			weights = nil
+		} else if len(weights) == 0 {
+			weights, err = LoadVolumeWeights(exg, job.Symbol, job.Duration, job.Slices, time.Now().UnixMilli())
+			if err != nil {
+				return nil, err
+			}
+		}
+		weights, err = normWeights(weights, job.Slices)
+		if err != nil {
+			return nil, err
+		}
+		run = func() { r.runSlices(weights) }
+	case KindIceberg:
+		if job.Display <= 0 || job.Price <= 0 {
+			return nil, errs.NewMsg(errs.CodeParamRequired, "display and price are required for iceberg")
+		}
+		run = r.runIceberg
+	case KindTrailing:
+		if job.CallbackRate <= 0 || job.CallbackRate >= 1 {
+			return nil, errs.NewMsg(errs.CodeParamInvalid, "callbackRate should be in (0, 1) for trailing")
+		}
+		run, err = r.prepareTrailing()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "unsupported algo kind: %s", job.Kind)
+	}
+	go func() {
+		defer close(r.Out)
+		run()
+	}()
+	return r, nil
+}
+
+// Cancel stop the job, open child order is canceled 停止任务，撤销未完成的子订单
+func (r *Runner) Cancel() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// wait sleep for d, return false if canceled 等待d，已取消时返回false
+func (r *Runner) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-r.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (r *Runner) emit(status string, err *errs.Error) {
+	r.prog.Status = status
+	r.prog.Err = err
+	prog := r.prog
+	utils.WriteChan(r.Out, &prog, true)
+	if err != nil {
+		log.Warn("algo job fail", zap.String("id", r.ID), zap.String("symbol", r.Job.Symbol), zap.Error(err))
+	}
+}
+
+// minAmount min amount of child order 子订单最小数量
+func (r *Runner) minAmount() float64 {
+	if r.market.Limits != nil && r.market.Limits.Amount != nil {
+		return r.market.Limits.Amount.Min
+	}
+	return 0
+}
+
+/*
+childAmount
+truncate amount by precision, 0 if less than min amount, or its notional at price less than min cost.
+Price is used only for the min cost check, it's skipped when price is 0.
+按精度截断数量，小于最小数量或按price计算的价值小于最小金额时返回0。price仅用于最小金额检查，为0时跳过。
+*/
+func (r *Runner) childAmount(amount, price float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	if r.market.Precision != nil {
+		// avoid 0.39999999 being truncated to 0.39
+		res, err := r.Exg.PrecAmount(r.market, amount*(1+1e-9))
+		if err != nil {
+			return 0
+		}
+		amount = res
+	}
+	if amount <= 0 || amount < r.minAmount() {
+		return 0
+	}
+	if minCost := r.minCost(); minCost > 0 && price > 0 && r.market.Notional(amount, price) < minCost {
+		return 0
+	}
+	return amount
+}
+
+// minCost min notional of child order 子订单最小金额
+func (r *Runner) minCost() float64 {
+	if r.market.Limits != nil && r.market.Limits.Cost != nil {
+		return r.market.Limits.Cost.Min
+	}
+	return 0
+}
+
+/*
+refPrice
+price to check min cost of child: job price for limit children, last price from ticker for market children.
+Return 0 when min cost is not limited or ticker is unavailable.
+检查子订单最小金额的价格：限价子订单使用任务价格，市价子订单使用行情的最新价。未限制最小金额或无法获取行情时返回0。
+*/
+func (r *Runner) refPrice() float64 {
+	if r.Job.Price > 0 || r.minCost() <= 0 {
+		return r.Job.Price
+	}
+	ticker, err := r.Exg.FetchTicker(r.Job.Symbol, utils.SafeParams(r.params))
+	if err != nil {
+		log.Warn("fetch ticker for algo child fail", zap.String("symbol", r.Job.Symbol), zap.Error(err))
+		return 0
+	}
+	return ticker.Last
+}
+
+/*
+placeChild
+create child order, market order if price is 0. Market child is polled until finished and settled, since the
+create response may not contain fills (binance futures returns NEW with executedQty 0)
+创建子订单，价格为0时使用市价单。市价子订单的创建响应可能不含成交(币安合约返回NEW且executedQty为0)，
+故轮询直到完成并结算
+*/
+func (r *Runner) placeChild(amount, price float64) (*banexg.Order, *errs.Error) {
+	odType := banexg.OdTypeLimit
+	if price <= 0 {
+		odType = banexg.OdTypeMarket
+	}
+	od, err := r.Exg.CreateOrder(r.Job.Symbol, odType, r.Job.Side, amount, price, utils.SafeParams(r.params))
+	if err != nil {
+		return nil, err
+	}
+	if od.Amount <= 0 {
+		od.Amount = amount
+	}
+	r.prog.Sent += amount
+	r.prog.Child = od
+	if odType == banexg.OdTypeMarket {
+		od, _ = r.waitChild(od)
+		if err = r.settleChild(od); err != nil {
+			return od, err
+		}
+	}
+	r.emit(StatusRunning, nil)
+	return od, nil
+}
+
+func filledOf(od *banexg.Order, amount float64) float64 {
+	if od.Filled > 0 {
+		return od.Filled
+	}
+	if od.Status == banexg.OdStatusFilled {
+		return amount
+	}
+	return 0
+}
+
+func isOdOpen(od *banexg.Order) bool {
+	return od.Status == "" || od.Status == banexg.OdStatusOpen || od.Status == banexg.OdStatusPartFilled
+}
+
+func (r *Runner) pollInterval() time.Duration {
+	if r.Job.PollInterval > 0 {
+		return r.Job.PollInterval
+	}
+	return time.Second
+}
+
+/*
+waitChild
+poll child until finished, return false if canceled
+轮询子订单直到结束，取消时返回false
+*/
+func (r *Runner) waitChild(od *banexg.Order) (*banexg.Order, bool) {
+	for isOdOpen(od) {
+		if !r.wait(r.pollInterval()) {
+			return od, false
+		}
+		res, err := r.Exg.FetchOrder(r.Job.Symbol, od.ID, utils.SafeParams(r.params))
+		if err != nil {
+			log.Warn("fetch algo child fail", zap.String("id", od.ID), zap.Error(err))
+			continue
+		}
+		od = res
+	}
+	return od, true
+}
+
+/*
+settleChild
+cancel child if it's open, count filled amount and return unfilled amount to Sent.
+If the child is still open after cancel, it's kept in Child and Sent, and an error is returned.
+子订单未完成时撤销，统计成交数量，未成交部分从Sent中扣除。撤销后仍未完成时保留在Child和Sent中并返回错误。
+*/
+func (r *Runner) settleChild(od *banexg.Order) *errs.Error {
+	if isOdOpen(od) {
+		res, err := r.Exg.FetchOrder(r.Job.Symbol, od.ID, utils.SafeParams(r.params))
+		if err == nil {
+			od = res
+		}
+	}
+	if isOdOpen(od) {
+		res, err := r.Exg.CancelOrder(od.ID, r.Job.Symbol, utils.SafeParams(r.params))
+		if err != nil {
+			log.Warn("cancel algo child fail", zap.String("id", od.ID), zap.Error(err))
+			// the cancel may fail because the order is already done
+			res, err = r.Exg.FetchOrder(r.Job.Symbol, od.ID, utils.SafeParams(r.params))
+			if err != nil || isOdOpen(res) {
+				r.prog.Child = od
+				return errs.NewMsg(errs.CodeRunTime, "algo child %s is still open after cancel", od.ID)
+			}
+			od = res
+		} else if res != nil && res.Filled > od.Filled {
+			od.Filled = res.Filled
+		}
+	}
+	filled := filledOf(od, od.Amount)
+	r.prog.Filled += filled
+	r.prog.Sent -= od.Amount - filled
+	r.prog.Child = od
+	return nil
+}
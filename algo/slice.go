@@ -0,0 +1,202 @@
+package algo
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/agg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"time"
+)
+
+const dayMSecs = int64(24 * time.Hour / time.Millisecond)
+
+/*
+normWeights
+normalize weights to sum 1, uniform weights are used if empty
+归一化权重使总和为1，为空时使用均匀权重
+*/
+func normWeights(weights []float64, slices int) ([]float64, *errs.Error) {
+	if len(weights) == 0 {
+		weights = make([]float64, slices)
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	total := float64(0)
+	for _, w := range weights {
+		if w < 0 {
+			return nil, errs.NewMsg(errs.CodeParamInvalid, "weights should not be negative")
+		}
+		total += w
+	}
+	if total == 0 {
+		return normWeights(nil, len(weights))
+	}
+	res := make([]float64, len(weights))
+	for i, w := range weights {
+		res[i] = w / total
+	}
+	return res, nil
+}
+
+/*
+VolumeWeights
+sum volumes of klines in [start, end) into slices buckets, used as vwap weights
+将[start, end)内K线成交量按slices分桶求和，用作vwap权重
+*/
+func VolumeWeights(klines []*banexg.Kline, start, end int64, slices int) []float64 {
+	res := make([]float64, slices)
+	if end <= start {
+		return res
+	}
+	for _, k := range klines {
+		if k.Time < start || k.Time >= end {
+			continue
+		}
+		idx := int((k.Time - start) * int64(slices) / (end - start))
+		res[idx] += k.Volume
+	}
+	return res
+}
+
+/*
+LoadVolumeWeights
+load vwap weights from klines of the same time window in previous days
+从之前相同时间窗口的K线加载vwap权重
+*/
+func LoadVolumeWeights(exg banexg.BanExchange, symbol string, duration time.Duration, slices int, now int64) ([]float64, *errs.Error) {
+	durMS := duration.Milliseconds()
+	shift := (durMS + dayMSecs - 1) / dayMSecs * dayMSecs
+	start := now - shift
+	end := start + durMS
+	timeFrame := "1d"
+	for _, tf := range []string{"1m", "5m", "15m", "1h", "4h"} {
+		tfMSecs := int64(utils.TFToSecs(tf)) * 1000
+		if durMS/tfMSecs <= 1000 && tfMSecs*int64(slices) <= durMS {
+			timeFrame = tf
+			break
+		}
+	}
+	klines, err := agg.FetchOHLCVRange(exg, symbol, timeFrame, start, end, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return VolumeWeights(klines, start, end, slices), nil
+}
+
+/*
+runSlices
+place one child for each slice with equal interval, amount of child is decided by cumulative weights.
+Children below min amount or min cost are carried to next slice. Unfilled limit child is canceled before next slice.
+按相同间隔为每个分片下一个子订单，数量由累计权重决定。小于最小数量或最小金额的子订单累加到下一分片。下一分片前撤销未成交的限价子订单。
+*/
+func (r *Runner) runSlices(weights []float64) {
+	interval := r.Job.Duration / time.Duration(len(weights))
+	var child *banexg.Order
+	cum := float64(0)
+	for i, w := range weights {
+		if i > 0 && !r.wait(interval) {
+			if child != nil {
+				if err := r.settleChild(child); err != nil {
+					r.emit(StatusFailed, err)
+					return
+				}
+			}
+			r.emit(StatusCanceled, nil)
+			return
+		}
+		if child != nil {
+			if err := r.settleChild(child); err != nil {
+				r.emit(StatusFailed, err)
+				return
+			}
+			child = nil
+		}
+		cum += w
+		target := r.Job.Amount * cum
+		if i == len(weights)-1 {
+			target = r.Job.Amount
+		}
+		amount := r.childAmount(target-r.prog.Sent, r.refPrice())
+		if amount == 0 {
+			continue
+		}
+		od, err := r.placeChild(amount, r.Job.Price)
+		if err != nil {
+			r.emit(StatusFailed, err)
+			return
+		}
+		if r.Job.Price > 0 {
+			child = od
+		}
+	}
+	if child != nil {
+		// wait last limit child for one more interval
+		od, ok := r.waitChildUntil(child, interval)
+		if err := r.settleChild(od); err != nil {
+			r.emit(StatusFailed, err)
+			return
+		}
+		if !ok {
+			r.emit(StatusCanceled, nil)
+			return
+		}
+	}
+	r.emit(StatusDone, nil)
+}
+
+// waitChildUntil poll child until finished or timeout, return false if canceled 轮询子订单直到结束或超时
+func (r *Runner) waitChildUntil(od *banexg.Order, timeout time.Duration) (*banexg.Order, bool) {
+	stopAt := time.Now().Add(timeout)
+	for isOdOpen(od) && time.Now().Before(stopAt) {
+		if !r.wait(min(r.pollInterval(), time.Until(stopAt))) {
+			return od, false
+		}
+		res, err := r.Exg.FetchOrder(r.Job.Symbol, od.ID, utils.SafeParams(r.params))
+		if err == nil {
+			od = res
+		}
+	}
+	return od, true
+}
+
+/*
+runIceberg
+place limit child of Display amount at Price, next child is placed after previous one filled
+以Price挂出Display数量的限价子订单，上一个成交后再下一个
+*/
+func (r *Runner) runIceberg() {
+	for {
+		remain := r.Job.Amount - r.prog.Sent
+		display := max(r.Job.Display, r.minAmount())
+		amount := r.childAmount(min(display, remain), r.Job.Price)
+		if amount == 0 {
+			if remain > display {
+				r.emit(StatusFailed, errs.NewMsg(errs.CodeParamInvalid, "iceberg child %v is below min cost %v",
+					display, r.minCost()))
+				return
+			}
+			break
+		}
+		od, err := r.placeChild(amount, r.Job.Price)
+		if err != nil {
+			r.emit(StatusFailed, err)
+			return
+		}
+		od, ok := r.waitChild(od)
+		if err := r.settleChild(od); err != nil {
+			r.emit(StatusFailed, err)
+			return
+		}
+		if !ok {
+			r.emit(StatusCanceled, nil)
+			return
+		}
+		if od.Status != banexg.OdStatusFilled {
+			r.emit(StatusFailed, errs.NewMsg(errs.CodeRunTime, "iceberg child %s is %s", od.ID, od.Status))
+			return
+		}
+		r.emit(StatusRunning, nil)
+	}
+	r.emit(StatusDone, nil)
+}
@@ -0,0 +1,244 @@
+package algo
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeExg 市价单立即成交，限价单在FetchOrder时成交fillAfter次后成交
+type fakeExg struct {
+	*banexg.Exchange
+	lock      sync.Mutex
+	orders    map[string]*banexg.Order
+	amounts   []float64
+	fetches   map[string]int
+	fillAfter int
+	minCost   float64
+	noCancel  bool // CancelOrder always fails 撤单总是失败
+	marketNew bool // market orders are returned as NEW and filled by FetchOrder, like binance futures 市价单返回NEW，FetchOrder时成交
+	trades    chan *banexg.Trade
+	unwatched []string
+}
+
+func newFakeExg() *fakeExg {
+	return &fakeExg{
+		Exchange: &banexg.Exchange{},
+		orders:   map[string]*banexg.Order{},
+		fetches:  map[string]int{},
+		trades:   make(chan *banexg.Trade, 10),
+	}
+}
+
+func (e *fakeExg) GetMarket(symbol string) (*banexg.Market, *errs.Error) {
+	return &banexg.Market{
+		Symbol:    symbol,
+		Precision: &banexg.Precision{Amount: 0.01, ModeAmount: banexg.PrecModeTickSize},
+		Limits: &banexg.MarketLimits{Amount: &banexg.LimitRange{Min: 0.1},
+			Cost: &banexg.LimitRange{Min: e.minCost}},
+	}, nil
+}
+
+func (e *fakeExg) CreateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*banexg.Order, *errs.Error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	od := &banexg.Order{ID: strconv.Itoa(len(e.orders) + 1), Symbol: symbol, Type: odType, Side: side,
+		Amount: amount, Price: price, Status: banexg.OdStatusOpen}
+	if odType == banexg.OdTypeMarket && !e.marketNew {
+		od.Status = banexg.OdStatusFilled
+		od.Filled = amount
+	}
+	e.orders[od.ID] = od
+	e.amounts = append(e.amounts, amount)
+	res := *od
+	return &res, nil
+}
+
+func (e *fakeExg) FetchOrder(symbol, orderId string, params map[string]interface{}) (*banexg.Order, *errs.Error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	od := e.orders[orderId]
+	e.fetches[orderId] += 1
+	if od.Status == banexg.OdStatusOpen && e.fetches[orderId] >= e.fillAfter {
+		od.Status = banexg.OdStatusFilled
+		od.Filled = od.Amount
+	}
+	res := *od
+	return &res, nil
+}
+
+func (e *fakeExg) CancelOrder(id string, symbol string, params map[string]interface{}) (*banexg.Order, *errs.Error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.noCancel {
+		return nil, errs.NewMsg(errs.CodeNetFail, "cancel fail")
+	}
+	od := e.orders[id]
+	if od.Status == banexg.OdStatusOpen {
+		od.Status = banexg.OdStatusCanceled
+	}
+	res := *od
+	return &res, nil
+}
+
+func (e *fakeExg) WatchTrades(symbols []string, params map[string]interface{}) (chan *banexg.Trade, *errs.Error) {
+	return e.trades, nil
+}
+
+func (e *fakeExg) UnWatchTrades(symbols []string, params map[string]interface{}) *errs.Error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.unwatched = append(e.unwatched, symbols...)
+	return nil
+}
+
+func lastProgress(t *testing.T, out chan *Progress) *Progress {
+	var last *Progress
+	timeout := time.After(time.Second * 3)
+	for {
+		select {
+		case p, ok := <-out:
+			if !ok {
+				return last
+			}
+			last = p
+		case <-timeout:
+			t.Fatal("algo job timeout")
+		}
+	}
+}
+
+func TestNormWeights(t *testing.T) {
+	res, err := normWeights(nil, 4)
+	if err != nil || len(res) != 4 || res[0] != 0.25 {
+		t.Errorf("bad uniform weights: %v %v", res, err)
+	}
+	res, _ = normWeights(VolumeWeights([]*banexg.Kline{{Time: 0, Volume: 1}, {Time: 50, Volume: 3}}, 0, 100, 2), 2)
+	if res[0] != 0.25 || res[1] != 0.75 {
+		t.Errorf("bad volume weights: %v", res)
+	}
+}
+
+func TestTWAP(t *testing.T) {
+	exg := newFakeExg()
+	r, err := Start(exg, &Job{Kind: KindVWAP, Symbol: "BTC/USDT", Side: banexg.OdSideBuy, Amount: 1,
+		Duration: time.Millisecond * 40, Slices: 4, Weights: []float64{0.05, 0.35, 0.3, 0.3}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := lastProgress(t, r.Out)
+	if p.Status != StatusDone || math.Abs(p.Filled-1) > 1e-9 {
+		t.Fatalf("bad progress: %+v", p)
+	}
+	// first slice 0.05 is less than min amount 0.1, carried to the second
+	want := []float64{0.4, 0.3, 0.3}
+	if len(exg.amounts) != len(want) {
+		t.Fatalf("bad children: %v", exg.amounts)
+	}
+	for i, v := range want {
+		if exg.amounts[i] != v {
+			t.Errorf("child %d amount %v, expect %v", i, exg.amounts[i], v)
+		}
+	}
+}
+
+func TestTWAPMarketNew(t *testing.T) {
+	exg := newFakeExg()
+	exg.marketNew = true
+	r, err := Start(exg, &Job{Kind: KindTWAP, Symbol: "BTC/USDT", Side: banexg.OdSideBuy, Amount: 1,
+		Duration: time.Millisecond * 40, Slices: 2, PollInterval: time.Millisecond}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := lastProgress(t, r.Out)
+	if p.Status != StatusDone || math.Abs(p.Filled-1) > 1e-9 || math.Abs(p.Sent-1) > 1e-9 {
+		t.Fatalf("market children should be tracked by FetchOrder: %+v", p)
+	}
+	exg.lock.Lock()
+	defer exg.lock.Unlock()
+	if len(exg.fetches) != 2 {
+		t.Errorf("expect both children fetched, got %v", exg.fetches)
+	}
+}
+
+func TestIceberg(t *testing.T) {
+	exg := newFakeExg()
+	exg.fillAfter = 2
+	r, err := Start(exg, &Job{Kind: KindIceberg, Symbol: "BTC/USDT", Side: banexg.OdSideSell, Amount: 1,
+		Price: 100, Display: 0.3, PollInterval: time.Millisecond}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := lastProgress(t, r.Out)
+	if p.Status != StatusDone || math.Abs(p.Filled-1) > 1e-9 {
+		t.Fatalf("bad progress: %+v", p)
+	}
+	want := []float64{0.3, 0.3, 0.3, 0.1}
+	if len(exg.amounts) != len(want) {
+		t.Fatalf("bad children: %v", exg.amounts)
+	}
+	for i, v := range want {
+		if exg.amounts[i] != v {
+			t.Errorf("child %d amount %v, expect %v", i, exg.amounts[i], v)
+		}
+	}
+}
+
+func TestCancelIceberg(t *testing.T) {
+	exg := newFakeExg()
+	exg.fillAfter = 1 << 30
+	r, err := Start(exg, &Job{Kind: KindIceberg, Symbol: "BTC/USDT", Side: banexg.OdSideSell, Amount: 1,
+		Price: 100, Display: 0.3, PollInterval: time.Millisecond}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 10)
+	r.Cancel()
+	p := lastProgress(t, r.Out)
+	if p.Status != StatusCanceled || p.Filled != 0 || p.Sent != 0 {
+		t.Fatalf("bad progress: %+v", p)
+	}
+	if exg.orders["1"].Status != banexg.OdStatusCanceled {
+		t.Errorf("child should be canceled: %+v", exg.orders["1"])
+	}
+}
+
+func TestTWAPMinCost(t *testing.T) {
+	exg := newFakeExg()
+	exg.minCost = 40
+	r, err := Start(exg, &Job{Kind: KindTWAP, Symbol: "BTC/USDT", Side: banexg.OdSideBuy, Amount: 1, Price: 100,
+		Duration: time.Millisecond * 40, Slices: 4, PollInterval: time.Millisecond}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := lastProgress(t, r.Out)
+	if p.Status != StatusDone || math.Abs(p.Filled-1) > 1e-9 {
+		t.Fatalf("bad progress: %+v", p)
+	}
+	// 0.25*100 is less than min cost 40, carried to next slice
+	want := []float64{0.5, 0.5}
+	if len(exg.amounts) != len(want) || exg.amounts[0] != want[0] || exg.amounts[1] != want[1] {
+		t.Fatalf("bad children: %v", exg.amounts)
+	}
+}
+
+func TestCancelChildFail(t *testing.T) {
+	exg := newFakeExg()
+	exg.fillAfter = 1 << 30
+	exg.noCancel = true
+	r, err := Start(exg, &Job{Kind: KindIceberg, Symbol: "BTC/USDT", Side: banexg.OdSideSell, Amount: 1,
+		Price: 100, Display: 0.3, PollInterval: time.Millisecond}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 10)
+	r.Cancel()
+	p := lastProgress(t, r.Out)
+	if p.Status != StatusFailed || p.Sent != 0.3 || p.Child == nil || p.Child.Status != banexg.OdStatusOpen {
+		t.Fatalf("open child should be kept: %+v", p)
+	}
+}
@@ -0,0 +1,157 @@
+package algo
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+)
+
+/*
+Trailer
+trailing stop state. For sell side (protect long), track the highest price after activated, trigger when price
+retraces CallbackRate from it; buy side is the opposite.
+跟踪止损状态。卖出方向(保护多头)在激活后跟踪最高价，价格从最高价回撤CallbackRate时触发；买入方向相反。
+*/
+type Trailer struct {
+	Side          string
+	CallbackRate  float64
+	ActivatePrice float64
+	Active        bool
+	Extreme       float64 // highest(sell) or lowest(buy) price after activated 激活后的最高/最低价
+}
+
+func NewTrailer(side string, callbackRate, activatePrice float64) *Trailer {
+	return &Trailer{Side: side, CallbackRate: callbackRate, ActivatePrice: activatePrice}
+}
+
+/*
+Update
+update with latest price, return true when triggered
+使用最新价格更新，触发时返回true
+*/
+func (t *Trailer) Update(price float64) bool {
+	if price <= 0 {
+		return false
+	}
+	isSell := t.Side == banexg.OdSideSell
+	if !t.Active {
+		if t.ActivatePrice > 0 && (isSell && price < t.ActivatePrice || !isSell && price > t.ActivatePrice) {
+			return false
+		}
+		t.Active = true
+		t.Extreme = price
+	}
+	if isSell {
+		t.Extreme = max(t.Extreme, price)
+	} else {
+		t.Extreme = min(t.Extreme, price)
+	}
+	return isSell && price <= t.StopPrice() || !isSell && price >= t.StopPrice()
+}
+
+// StopPrice current trigger price, 0 if not active 当前触发价格，未激活时为0
+func (t *Trailer) StopPrice() float64 {
+	if !t.Active {
+		return 0
+	}
+	if t.Side == banexg.OdSideSell {
+		return t.Extreme * (1 - t.CallbackRate)
+	}
+	return t.Extreme * (1 + t.CallbackRate)
+}
+
+/*
+prepareTrailing
+subscribe prices of job symbol through WatchHub, return the function that places order of whole amount when triggered.
+The subscription is closed when the function returns. Prices of other symbols are ignored.
+通过WatchHub订阅任务品种的价格，返回触发时下全部数量订单的函数。函数返回时取消订阅。忽略其他品种价格。
+*/
+func (r *Runner) prepareTrailing() (func(), *errs.Error) {
+	symbol := r.Job.Symbol
+	symbols := []string{symbol}
+	prices := make(chan float64, 10)
+	var done <-chan struct{}
+	var unSub func()
+	if r.Job.PriceSrc == SrcMark {
+		out, err := banexg.SubMarkPrices(r.Exg, symbols, r.params)
+		if err != nil {
+			return nil, err
+		}
+		unSub = func() {
+			_ = banexg.UnSubMarkPrices(r.Exg, symbols, r.params, out)
+		}
+		done = pipePrices(out, prices, r.stop, func(msg map[string]float64) (float64, bool) {
+			price, ok := msg[symbol]
+			return price, ok
+		})
+	} else if r.Job.PriceSrc == "" || r.Job.PriceSrc == SrcTrade {
+		out, err := banexg.SubTrades(r.Exg, symbols, r.params)
+		if err != nil {
+			return nil, err
+		}
+		unSub = func() {
+			_ = banexg.UnSubTrades(r.Exg, symbols, r.params, out)
+		}
+		done = pipePrices(out, prices, r.stop, func(msg *banexg.Trade) (float64, bool) {
+			return msg.Price, msg.Symbol == symbol
+		})
+	} else {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "invalid price source for trailing: %s", r.Job.PriceSrc)
+	}
+	trailer := NewTrailer(r.Job.Side, r.Job.CallbackRate, r.Job.ActivatePrice)
+	return func() {
+		defer unSub()
+		for {
+			select {
+			case <-r.stop:
+				r.emit(StatusCanceled, nil)
+				return
+			case <-done:
+				r.emit(StatusFailed, errs.NewMsg(errs.CodeRunTime, "price channel closed"))
+				return
+			case price := <-prices:
+				active := trailer.Active
+				if !trailer.Update(price) {
+					if !active && trailer.Active {
+						r.emit(StatusRunning, nil)
+					}
+					continue
+				}
+				amount := r.childAmount(r.Job.Amount, price)
+				if amount == 0 {
+					r.emit(StatusFailed, errs.NewMsg(errs.CodeParamInvalid, "amount less than min amount or cost: %v", r.Job.Amount))
+					return
+				}
+				_, err := r.placeChild(amount, r.Job.Price)
+				if err != nil {
+					r.emit(StatusFailed, err)
+				} else {
+					r.emit(StatusDone, nil)
+				}
+				return
+			}
+		}
+	}, nil
+}
+
+// pipePrices read prices from subscribed channel until it's closed or stopped 从订阅通道读取价格直到关闭或停止
+func pipePrices[T any](in chan T, out chan float64, stop chan struct{}, get func(msg T) (float64, bool)) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			case msg, ok := <-in:
+				if !ok {
+					return
+				}
+				if price, ok := get(msg); ok {
+					utils.WriteChan(out, price, true)
+				}
+			}
+		}
+	}()
+	return done
+}
@@ -0,0 +1,57 @@
+package algo
+
+import (
+	"github.com/banbox/banexg"
+	"testing"
+)
+
+func TestTrailer(t *testing.T) {
+	tr := NewTrailer(banexg.OdSideSell, 0.1, 105)
+	for _, p := range []float64{100, 104} {
+		if tr.Update(p) || tr.Active {
+			t.Fatalf("should not active at %v", p)
+		}
+	}
+	for _, p := range []float64{106, 120, 110} {
+		if tr.Update(p) {
+			t.Fatalf("should not trigger at %v", p)
+		}
+	}
+	if tr.StopPrice() != 108 {
+		t.Errorf("bad stop price: %v", tr.StopPrice())
+	}
+	if !tr.Update(107) {
+		t.Errorf("should trigger at 107")
+	}
+	buy := NewTrailer(banexg.OdSideBuy, 0.1, 0)
+	if buy.Update(100) || buy.Update(80) || !buy.Update(88.5) {
+		t.Errorf("buy trailer should trigger after 10%% rebound")
+	}
+}
+
+func TestTrailingJob(t *testing.T) {
+	exg := newFakeExg()
+	r, err := Start(exg, &Job{Kind: KindTrailing, Symbol: "BTC/USDT", Side: banexg.OdSideSell, Amount: 0.5,
+		CallbackRate: 0.05}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []float64{100, 110, 200, 104} {
+		exg.trades <- &banexg.Trade{Symbol: "ETH/USDT", Price: p}
+	}
+	for _, p := range []float64{100, 110, 104} {
+		exg.trades <- &banexg.Trade{Symbol: "BTC/USDT", Price: p}
+	}
+	p := lastProgress(t, r.Out)
+	if p.Status != StatusDone || p.Filled != 0.5 || len(exg.amounts) != 1 {
+		t.Fatalf("bad progress: %+v, children: %v", p, exg.amounts)
+	}
+	if exg.orders["1"].Type != banexg.OdTypeMarket {
+		t.Errorf("trailing child should be market order")
+	}
+	exg.lock.Lock()
+	defer exg.lock.Unlock()
+	if len(exg.unwatched) != 1 || exg.unwatched[0] != "BTC/USDT" {
+		t.Errorf("trades should be unwatched after done: %v", exg.unwatched)
+	}
+}
@@ -0,0 +1,72 @@
+package algo
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"time"
+)
+
+const (
+	KindTWAP     = "twap"     // 按时间均匀拆单
+	KindVWAP     = "vwap"     // 按历史成交量分布拆单
+	KindIceberg  = "iceberg"  // 冰山单，每次只挂出Display数量
+	KindTrailing = "trailing" // 客户端跟踪止损
+)
+
+const (
+	StatusRunning  = "running"
+	StatusDone     = "done"
+	StatusCanceled = "canceled"
+	StatusFailed   = "failed"
+)
+
+const (
+	SrcTrade = "trade" // 跟踪止损使用WatchTrades的成交价
+	SrcMark  = "mark"  // 跟踪止损使用WatchMarkPrices的标记价格
+)
+
+/*
+Job
+an algorithmic execution task, fields used depend on Kind
+一个算法执行任务，使用的字段取决于Kind
+*/
+type Job struct {
+	Kind   string
+	Symbol string
+	Side   string
+	Amount float64
+	Price  float64 // limit price of children, market children if 0 (iceberg requires price) 子订单限价，0表示市价
+
+	// twap/vwap
+	Duration time.Duration // total execution time 总执行时长
+	Slices   int           // number of children 子订单数量
+	Weights  []float64     // vwap weights of slices, loaded from klines of previous day if empty 各子订单权重
+
+	// iceberg
+	Display float64 // visible amount of each child 每个子订单的显示数量
+
+	// trailing
+	CallbackRate  float64 // trigger when price retraces by this rate, 0.01 for 1% 回调比例
+	ActivatePrice float64 // start trailing after price reaches, 0 to start immediately 激活价格
+	PriceSrc      string  // SrcTrade(default) or SrcMark
+
+	PollInterval time.Duration          // interval to check status of limit children, default 1s 检查限价子订单状态的间隔
+	Params       map[string]interface{} // params for child orders 子订单参数
+}
+
+/*
+Progress
+execution progress, sent after each child order and when finished
+执行进度，每个子订单后及结束时发送
+*/
+type Progress struct {
+	ID     string
+	Kind   string
+	Symbol string
+	Status string
+	Total  float64       // target amount 目标数量
+	Sent   float64       // amount of submitted children 已提交子订单数量
+	Filled float64       // filled amount of children 子订单已成交数量
+	Child  *banexg.Order // latest child order 最新子订单
+	Err    *errs.Error
+}
@@ -0,0 +1,78 @@
+package banexg
+
+import (
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"maps"
+	"sync"
+)
+
+/*
+MergeOrderParams
+merge batch params and params of item, item params take precedence
+合并批量参数和单项参数，单项参数优先
+*/
+func MergeOrderParams(params map[string]interface{}, req *OrderReq) map[string]interface{} {
+	args := utils.SafeParams(params)
+	maps.Copy(args, req.Params)
+	return args
+}
+
+/*
+EmulateBatch
+call single order method for each request with at most BatchOdConcurr concurrent calls
+对每个请求调用单个订单方法，最多BatchOdConcurr个并发
+*/
+func EmulateBatch(reqs []*OrderReq, params map[string]interface{},
+	call func(req *OrderReq, args map[string]interface{}) (*Order, *errs.Error)) []*OrderRes {
+	res := make([]*OrderRes, len(reqs))
+	guard := make(chan struct{}, max(BatchOdConcurr, 1))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		guard <- struct{}{}
+		go func(i int, req *OrderReq) {
+			defer func() {
+				<-guard
+				wg.Done()
+			}()
+			od, err := call(req, MergeOrderParams(params, req))
+			res[i] = &OrderRes{Order: od, Err: err}
+		}(i, req)
+	}
+	wg.Wait()
+	return res
+}
+
+/*
+CreateOrdersEmulated
+emulate CreateOrders by calling CreateOrder concurrently
+通过并发调用CreateOrder模拟批量下单
+*/
+func CreateOrdersEmulated(exg BanExchange, reqs []*OrderReq, params map[string]interface{}) []*OrderRes {
+	return EmulateBatch(reqs, params, func(req *OrderReq, args map[string]interface{}) (*Order, *errs.Error) {
+		return exg.CreateOrder(req.Symbol, req.Type, req.Side, req.Amount, req.Price, args)
+	})
+}
+
+/*
+EditOrdersEmulated
+emulate EditOrders by calling EditOrder concurrently
+通过并发调用EditOrder模拟批量修改订单
+*/
+func EditOrdersEmulated(exg BanExchange, reqs []*OrderReq, params map[string]interface{}) []*OrderRes {
+	return EmulateBatch(reqs, params, func(req *OrderReq, args map[string]interface{}) (*Order, *errs.Error) {
+		return exg.EditOrder(req.Symbol, req.ID, req.Side, req.Amount, req.Price, args)
+	})
+}
+
+/*
+CancelOrdersEmulated
+emulate CancelOrders by calling CancelOrder concurrently
+通过并发调用CancelOrder模拟批量撤单
+*/
+func CancelOrdersEmulated(exg BanExchange, reqs []*OrderReq, params map[string]interface{}) []*OrderRes {
+	return EmulateBatch(reqs, params, func(req *OrderReq, args map[string]interface{}) (*Order, *errs.Error) {
+		return exg.CancelOrder(req.ID, req.Symbol, args)
+	})
+}
@@ -0,0 +1,49 @@
+package banexg
+
+import (
+	"github.com/banbox/banexg/errs"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEmulateBatch(t *testing.T) {
+	reqs := make([]*OrderReq, 12)
+	for i := range reqs {
+		reqs[i] = &OrderReq{Symbol: "BTC/USDT", ID: strconv.Itoa(i), Params: map[string]interface{}{"idx": i}}
+	}
+	var running, maxRun int32
+	res := EmulateBatch(reqs, map[string]interface{}{"idx": -1, "acc": "a"}, func(req *OrderReq, args map[string]interface{}) (*Order, *errs.Error) {
+		cur := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&maxRun)
+			if cur <= old || atomic.CompareAndSwapInt32(&maxRun, old, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 5)
+		atomic.AddInt32(&running, -1)
+		if args["idx"] != req.Params["idx"] || args["acc"] != "a" {
+			return nil, errs.NewMsg(errs.CodeParamInvalid, "bad params")
+		}
+		if req.ID == "3" {
+			return nil, errs.NewMsg(errs.CodeRunTime, "fail")
+		}
+		return &Order{ID: req.ID}, nil
+	})
+	if int(maxRun) > BatchOdConcurr {
+		t.Errorf("concurrency %v exceed %v", maxRun, BatchOdConcurr)
+	}
+	for i, r := range res {
+		if i == 3 {
+			if r.Err == nil {
+				t.Errorf("expect error for item 3")
+			}
+			continue
+		}
+		if r.Err != nil || r.Order.ID != strconv.Itoa(i) {
+			t.Errorf("bad result %d: %v %v", i, r.Order, r.Err)
+		}
+	}
+}
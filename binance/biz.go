@@ -101,6 +101,15 @@ func makeSign(e *Binance) banexg.FuncSign {
 			if e.RecvWindow > 0 {
 				extendParams["recvWindow"] = e.RecvWindow
 			}
+			if path == "asset/dust" {
+				// asset should be repeated for each currency: asset=A&asset=B
+				if codes, ok := extendParams["asset"].([]string); ok {
+					delete(extendParams, "asset")
+					for _, code := range codes {
+						query = append(query, "asset="+code)
+					}
+				}
+			}
 			if path == "batchOrders" || strings.Contains(path, "sub-account") || path == "capital/withdraw/apply" || strings.Contains(path, "staking") {
 				query = append(query, utils.UrlEncodeMap(extendParams, true))
 				if api.Method == "DELETE" && path == "batchOrders" {
@@ -120,20 +129,8 @@ func makeSign(e *Binance) banexg.FuncSign {
 			} else {
 				query = append(query, utils.UrlEncodeMap(extendParams, false))
 			}
-			var sign, method, hash string
-			var digest = "hex"
-			var secret = creds.Secret
-			if strings.Contains(secret, "PRIVATE KEY") {
-				if len(secret) > 120 {
-					method, hash = "rsa", "sha256"
-				} else {
-					method, hash = "eddsa", "ed25519"
-				}
-			} else {
-				method, hash = "hmac", "sha256"
-			}
 			queryText := strings.Join(query, "&")
-			sign, err = utils.Signature(queryText, secret, method, hash, digest)
+			sign, err := signText(queryText, creds.Secret)
 			if err != nil {
 				return &banexg.HttpReq{Error: err, Private: true}
 			}
@@ -153,6 +150,25 @@ func makeSign(e *Binance) banexg.FuncSign {
 	}
 }
 
+/*
+signText
+sign text with secret, rsa/ed25519 is used for PEM private key, otherwise hmac-sha256
+使用secret签名，PEM私钥使用rsa/ed25519，否则使用hmac-sha256
+*/
+func signText(text, secret string) (string, *errs.Error) {
+	var method, hash string
+	if strings.Contains(secret, "PRIVATE KEY") {
+		if len(secret) > 120 {
+			method, hash = "rsa", "sha256"
+		} else {
+			method, hash = "eddsa", "ed25519"
+		}
+	} else {
+		method, hash = "hmac", "sha256"
+	}
+	return utils.Signature(text, secret, method, hash, "hex")
+}
+
 /*
 fetches all available currencies on an exchange
 :see: https://binance-docs.github.io/apidocs/spot/en/#all-coins-39-information-user_data
@@ -214,6 +230,8 @@ func makeFetchCurr(e *Binance) banexg.FuncFetchCurr {
 					curr.Precision = precisionTick
 					curr.PrecMode = banexg.PrecModeTickSize
 				}
+				withdrawMin, _ := strconv.ParseFloat(net.WithdrawMin, 64)
+				withdrawMax, _ := strconv.ParseFloat(net.WithdrawMax, 64)
 				curr.Networks[i] = &banexg.ChainNetwork{
 					ID:        net.Network,
 					Network:   net.Network,
@@ -223,7 +241,10 @@ func makeFetchCurr(e *Binance) banexg.FuncFetchCurr {
 					Precision: precisionTick,
 					Deposit:   net.DepositEnable,
 					Withdraw:  net.WithdrawEnable,
-					Info:      net,
+					Limits: &banexg.CodeLimits{
+						Withdraw: &banexg.LimitRange{Min: withdrawMin, Max: withdrawMax},
+					},
+					Info: net,
 				}
 			}
 			curr.Active = isDeposit && isWithDraw && item.Trading
@@ -616,6 +637,7 @@ set the level of leverage for a market
 	:param float leverage: the rate of leverage
 	:param str symbol: unified market symbol
 	:param dict [params]: extra parameters specific to the exchange API endpoint
+	:param bool [params.portfolioMargin]: set leverage of portfolio margin account, default OptPortfolioMargin
 	:returns dict: response from the exchange
 */
 func (e *Binance) SetLeverage(leverage float64, symbol string, params map[string]interface{}) (map[string]interface{}, *errs.Error) {
@@ -630,7 +652,12 @@ func (e *Binance) SetLeverage(leverage float64, symbol string, params map[string
 		return nil, err
 	}
 	var method string
-	if market.Linear {
+	if e.isPortfolioMargin(args) {
+		method, err = pmLeverage.pick("SetLeverage", market.Type, "", args)
+		if err != nil {
+			return nil, err
+		}
+	} else if market.Linear {
 		method = MethodFapiPrivatePostLeverage
 	} else if market.Inverse {
 		method = MethodDapiPrivatePostLeverage
@@ -661,6 +688,8 @@ func (e *Binance) LoadLeverageBrackets(reload bool, params map[string]interface{
 	if err != nil {
 		return err
 	}
+	// brackets are same for portfolio margin account 统一账户的杠杆分层相同
+	delete(args, banexg.ParamPortfolioMargin)
 	var method string
 	if marketType == banexg.MarketLinear {
 		method = MethodFapiPrivateGetLeverageBracket
@@ -707,11 +736,8 @@ func (e *Binance) GetLeverage(symbol string, notional float64, account string) (
 			break
 		}
 	}
-	if account == "" {
-		account = e.DefAccName
-	}
 	var leverage int
-	if acc, ok := e.Accounts[account]; ok {
+	if acc, err := e.GetAccount(account); err == nil {
 		acc.LockLeverage.Lock()
 		leverage, _ = acc.Leverages[symbol]
 		acc.LockLeverage.Unlock()
@@ -903,6 +929,26 @@ func (e *Binance) regReplayHandles() {
 			_, err := e.WatchMarkPrices(symbols, nil)
 			return err
 		},
+		"WatchTickers": func(item *banexg.WsLog) *errs.Error {
+			var symbols = make([]string, 0)
+			err_ := utils.UnmarshalString(item.Content, &symbols, utils.JsonNumDefault)
+			if err_ != nil {
+				return errs.New(errs.CodeUnmarshalFail, err_)
+			}
+			log.Debug("replay WatchTickers", zap.Strings("codes", symbols))
+			_, err := e.WatchTickers(symbols, nil)
+			return err
+		},
+		"WatchBookTickers": func(item *banexg.WsLog) *errs.Error {
+			var symbols = make([]string, 0)
+			err_ := utils.UnmarshalString(item.Content, &symbols, utils.JsonNumDefault)
+			if err_ != nil {
+				return errs.New(errs.CodeUnmarshalFail, err_)
+			}
+			log.Debug("replay WatchBookTickers", zap.Strings("codes", symbols))
+			_, err := e.WatchBookTickers(symbols, nil)
+			return err
+		},
 		"OdBookShot": func(item *banexg.WsLog) *errs.Error {
 			var pak = &banexg.OdBookShotLog{}
 			err_ := utils.UnmarshalString(item.Content, pak, utils.JsonNumDefault)
@@ -1057,6 +1103,8 @@ func (e *Binance) FetchFundingRateHistory(symbol string, since int64, limit int,
 			return nil, err
 		}
 	}
+	// limit is the cap of total rows, pages are requested in [since, until] until limit rows
+	total := limit
 	if limit <= 0 {
 		limit = maxFundRateBatch
 	}
@@ -1077,46 +1125,40 @@ func (e *Binance) FetchFundingRateHistory(symbol string, since int64, limit int,
 			until = since + int64(limit)*interval
 		}
 	}
-	if until > 0 {
-		args["endTime"] = until
+	if until <= 0 {
+		return e.getFundRateHis(marketType, method, args)
 	}
-	items := make([]*banexg.FundingRate, 0)
-	for {
-		list, hasMore, err := e.getFundRateHis(marketType, method, until, args)
-		if err != nil {
-			return nil, err
+	args["endTime"] = until
+	fetch := func(req *banexg.PageReq) ([]*banexg.FundingRate, string, *errs.Error) {
+		if req.Since > 0 {
+			args["startTime"] = req.Since
 		}
-		items = append(items, list...)
-		if !hasMore {
-			break
-		}
-		// 有未完成的数据，需要继续请求
-		intv := list[1].Timestamp - list[0].Timestamp
-		since = list[len(list)-1].Timestamp + intv
-		args["startTime"] = since
-	}
-	return items, nil
+		list, err := e.getFundRateHis(marketType, method, args)
+		return list, "", err
+	}
+	it := banexg.NewPageIter(context.Background(), since, until, args["limit"].(int), fetch, func(f *banexg.FundingRate) int64 {
+		return f.Timestamp
+	}, func(f *banexg.FundingRate) string {
+		return f.Symbol
+	})
+	return it.Take(total)
 }
 
-func (e *Binance) getFundRateHis(marketType, method string, until int64, args map[string]interface{}) ([]*banexg.FundingRate, bool, *errs.Error) {
+func (e *Binance) getFundRateHis(marketType, method string, args map[string]interface{}) ([]*banexg.FundingRate, *errs.Error) {
 	tryNum := e.GetRetryNum("FetchFundingRateHistory", 1)
 	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
 	if rsp.Error != nil {
-		return nil, false, rsp.Error
+		return nil, rsp.Error
 	}
 	var items = make([]*FundingRate, 0)
 	err := utils.UnmarshalString(rsp.Content, &items, utils.JsonNumDefault)
 	if err != nil {
-		return nil, false, errs.NewFull(errs.CodeUnmarshalFail, err, "decode option kline fail")
+		return nil, errs.NewFull(errs.CodeUnmarshalFail, err, "decode option kline fail")
 	}
-	var lastMS int64
 	var list = make([]*banexg.FundingRate, 0, len(items))
 	for _, it := range items {
 		code := e.SafeSymbol(it.Symbol, "", marketType)
 		stamp := it.FundingTime
-		if stamp > lastMS {
-			lastMS = stamp
-		}
 		if code == "" {
 			continue
 		}
@@ -1128,9 +1170,7 @@ func (e *Binance) getFundRateHis(marketType, method string, until int64, args ma
 			Info:        it,
 		})
 	}
-	interval := int64(60 * 60 * 8 * 1000)
-	hasMore := until > 0 && len(items) == maxFundRateBatch && lastMS+interval < until
-	return list, hasMore, nil
+	return list, nil
 }
 
 func (f *FundingRateCur) ToStd(e *Binance, marketType string) *banexg.FundingRateCur {
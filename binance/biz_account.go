@@ -1,9 +1,11 @@
 package binance
 
 import (
+	"context"
 	"github.com/banbox/banexg"
 	"github.com/banbox/banexg/errs"
 	"github.com/banbox/banexg/utils"
+	"strings"
 )
 
 func (e *Binance) WatchAccountConfig(params map[string]interface{}) (chan *banexg.AccountConfig, *errs.Error) {
@@ -18,3 +20,287 @@ func (e *Binance) WatchAccountConfig(params map[string]interface{}) (chan *banex
 	e.AddWsChanRefs(chanKey, "account")
 	return out, nil
 }
+
+const (
+	bizNoNeedChangePosSide    = -4059 // No need to change position side.
+	bizNoNeedChangeMarginType = -4046 // No need to change margin type.
+	bizNoNeedChangeMultiAsset = -4171 // Multi-Assets mode is already the same.
+)
+
+type PositionSideDual struct {
+	DualSidePosition bool `json:"dualSidePosition"`
+}
+
+type MultiAssetsMargin struct {
+	MultiAssetsMargin bool `json:"multiAssetsMargin"`
+}
+
+/*
+loadAccConfigArgs
+prepare args for account config apis, only linear/inverse are supported
+准备账户配置接口的参数，仅支持U本位和币本位合约
+*/
+func (e *Binance) loadAccConfigArgs(params map[string]interface{}, symbols ...string) (map[string]interface{}, string, *banexg.Account, *errs.Error) {
+	args := utils.SafeParams(params)
+	marketType, _, err := e.LoadArgsMarketType(args, symbols...)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if marketType != banexg.MarketLinear && marketType != banexg.MarketInverse {
+		return nil, "", nil, errs.NewMsg(errs.CodeUnsupportMarket, "account config support linear/inverse contracts only")
+	}
+	acc, err := e.GetAccount(e.GetAccName(args))
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return args, marketType, acc, nil
+}
+
+/*
+requestAccConfig
+request account config api, the error of bizNoChange is ignored since the account is already in target mode.
+result is decoded into out if it's not nil
+请求账户配置接口，bizNoChange错误表示已是目标模式，忽略。out不为nil时解析结果
+*/
+func (e *Binance) requestAccConfig(apiName, method string, args map[string]interface{}, bizNoChange int, out interface{}) *errs.Error {
+	tryNum := e.GetRetryNum(apiName, 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		if bizNoChange != 0 && rsp.Error.BizCode == bizNoChange {
+			return nil
+		}
+		return rsp.Error
+	}
+	if out == nil {
+		return nil
+	}
+	err := utils.UnmarshalString(rsp.Content, out, utils.JsonNumDefault)
+	if err != nil {
+		return errs.New(errs.CodeUnmarshalFail, err)
+	}
+	return nil
+}
+
+/*
+GetPositionMode
+return true if hedge mode (dual position side) is enabled
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#get-current-position-mode-user_data
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#get-current-position-mode-user_data
+	:param bool [params.refresh]: fetch from exchange instead of cache
+*/
+func (e *Binance) GetPositionMode(params map[string]interface{}) (bool, *errs.Error) {
+	args, marketType, acc, err := e.loadAccConfigArgs(params)
+	if err != nil {
+		return false, err
+	}
+	if !utils.PopMapVal(args, banexg.ParamRefresh, false) {
+		acc.LockLeverage.Lock()
+		hedged, ok := acc.PosModes[marketType]
+		acc.LockLeverage.Unlock()
+		if ok {
+			return hedged, nil
+		}
+	}
+	method := MethodFapiPrivateGetPositionSideDual
+	if marketType == banexg.MarketInverse {
+		method = MethodDapiPrivateGetPositionSideDual
+	}
+	var res = PositionSideDual{}
+	err = e.requestAccConfig("GetPositionMode", method, args, 0, &res)
+	if err != nil {
+		return false, err
+	}
+	acc.LockLeverage.Lock()
+	acc.PosModes[marketType] = res.DualSidePosition
+	acc.LockLeverage.Unlock()
+	return res.DualSidePosition, nil
+}
+
+/*
+SetPositionMode
+switch between one-way mode and hedge mode, this affects all symbols of the market type
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#change-position-mode-trade
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#change-position-mode-trade
+*/
+func (e *Binance) SetPositionMode(hedged bool, params map[string]interface{}) *errs.Error {
+	args, marketType, acc, err := e.loadAccConfigArgs(params)
+	if err != nil {
+		return err
+	}
+	method := MethodFapiPrivatePostPositionSideDual
+	if marketType == banexg.MarketInverse {
+		method = MethodDapiPrivatePostPositionSideDual
+	}
+	args["dualSidePosition"] = hedged
+	err = e.requestAccConfig("SetPositionMode", method, args, bizNoNeedChangePosSide, nil)
+	if err != nil {
+		return err
+	}
+	acc.LockLeverage.Lock()
+	acc.PosModes[marketType] = hedged
+	acc.LockLeverage.Unlock()
+	return nil
+}
+
+func parseMarginType(text string) string {
+	if strings.HasPrefix(strings.ToLower(text), banexg.MarginCross) {
+		return banexg.MarginCross
+	}
+	return banexg.MarginIsolated
+}
+
+/*
+GetMarginMode
+return MarginCross or MarginIsolated of symbol, read from position risk when not cached
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#position-information-v2-user_data
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#position-information-user_data
+	:param bool [params.refresh]: fetch from exchange instead of cache
+*/
+func (e *Binance) GetMarginMode(symbol string, params map[string]interface{}) (string, *errs.Error) {
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return "", err
+	}
+	acc, err := e.GetAccount(e.GetAccName(args))
+	if err != nil {
+		return "", err
+	}
+	if !utils.PopMapVal(args, banexg.ParamRefresh, false) {
+		acc.LockLeverage.Lock()
+		mode, ok := acc.MarginModes[market.Symbol]
+		acc.LockLeverage.Unlock()
+		if ok {
+			return mode, nil
+		}
+	}
+	var method string
+	if market.Linear {
+		method = MethodFapiPrivateV2GetPositionRisk
+		args["symbol"] = market.ID
+	} else if market.Inverse {
+		method = MethodDapiPrivateGetPositionRisk
+	} else {
+		return "", errs.NewMsg(errs.CodeUnsupportMarket, "GetMarginMode support linear/inverse contracts only")
+	}
+	var items = make([]*ContPositionRisk, 0)
+	err = e.requestAccConfig("GetMarginMode", method, args, 0, &items)
+	if err != nil {
+		return "", err
+	}
+	for _, it := range items {
+		if it.Symbol == market.ID && it.MarginType != "" {
+			mode := parseMarginType(it.MarginType)
+			acc.LockLeverage.Lock()
+			acc.MarginModes[market.Symbol] = mode
+			acc.LockLeverage.Unlock()
+			return mode, nil
+		}
+	}
+	return "", errs.NewMsg(errs.CodeInvalidResponse, "no position risk found for %s", symbol)
+}
+
+/*
+SetMarginMode
+set MarginCross or MarginIsolated for symbol
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#change-margin-type-trade
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#change-margin-type-trade
+*/
+func (e *Binance) SetMarginMode(symbol, mode string, params map[string]interface{}) *errs.Error {
+	var marginType string
+	if mode == banexg.MarginCross {
+		marginType = "CROSSED"
+	} else if mode == banexg.MarginIsolated {
+		marginType = "ISOLATED"
+	} else {
+		return errs.NewMsg(errs.CodeParamInvalid, "invalid margin mode: %s", mode)
+	}
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return err
+	}
+	acc, err := e.GetAccount(e.GetAccName(args))
+	if err != nil {
+		return err
+	}
+	var method string
+	if market.Linear {
+		method = MethodFapiPrivatePostMarginType
+	} else if market.Inverse {
+		method = MethodDapiPrivatePostMarginType
+	} else {
+		return errs.NewMsg(errs.CodeUnsupportMarket, "SetMarginMode support linear/inverse contracts only")
+	}
+	args["symbol"] = market.ID
+	args["marginType"] = marginType
+	err = e.requestAccConfig("SetMarginMode", method, args, bizNoNeedChangeMarginType, nil)
+	if err != nil {
+		return err
+	}
+	acc.LockLeverage.Lock()
+	acc.MarginModes[market.Symbol] = mode
+	acc.LockLeverage.Unlock()
+	return nil
+}
+
+/*
+GetMultiAssetsMode
+return true if multi-assets margin mode is enabled, linear only
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#get-current-multi-assets-mode-user_data
+	:param bool [params.refresh]: fetch from exchange instead of cache
+*/
+func (e *Binance) GetMultiAssetsMode(params map[string]interface{}) (bool, *errs.Error) {
+	args, marketType, acc, err := e.loadAccConfigArgs(params)
+	if err != nil {
+		return false, err
+	}
+	if marketType != banexg.MarketLinear {
+		return false, errs.NewMsg(errs.CodeUnsupportMarket, "multi-assets mode is for linear only")
+	}
+	if !utils.PopMapVal(args, banexg.ParamRefresh, false) {
+		acc.LockLeverage.Lock()
+		enabled, ok := acc.MultiAssets[marketType]
+		acc.LockLeverage.Unlock()
+		if ok {
+			return enabled, nil
+		}
+	}
+	var res = MultiAssetsMargin{}
+	err = e.requestAccConfig("GetMultiAssetsMode", MethodFapiPrivateGetMultiAssetsMargin, args, 0, &res)
+	if err != nil {
+		return false, err
+	}
+	acc.LockLeverage.Lock()
+	acc.MultiAssets[marketType] = res.MultiAssetsMargin
+	acc.LockLeverage.Unlock()
+	return res.MultiAssetsMargin, nil
+}
+
+/*
+SetMultiAssetsMode
+enable or disable multi-assets margin mode, linear only
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#change-multi-assets-mode-trade
+*/
+func (e *Binance) SetMultiAssetsMode(enable bool, params map[string]interface{}) *errs.Error {
+	args, marketType, acc, err := e.loadAccConfigArgs(params)
+	if err != nil {
+		return err
+	}
+	if marketType != banexg.MarketLinear {
+		return errs.NewMsg(errs.CodeUnsupportMarket, "multi-assets mode is for linear only")
+	}
+	args["multiAssetsMargin"] = enable
+	err = e.requestAccConfig("SetMultiAssetsMode", MethodFapiPrivatePostMultiAssetsMargin, args, bizNoNeedChangeMultiAsset, nil)
+	if err != nil {
+		return err
+	}
+	acc.LockLeverage.Lock()
+	acc.MultiAssets[marketType] = enable
+	acc.LockLeverage.Unlock()
+	return nil
+}
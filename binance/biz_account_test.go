@@ -0,0 +1,123 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func getOfflineLinear(t *testing.T) *Binance {
+	exg, err := New(map[string]interface{}{
+		banexg.OptApiKey:     "key",
+		banexg.OptApiSecret:  "secret",
+		banexg.OptMarketType: banexg.MarketLinear,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	btcLinear := &banexg.Market{ID: "BTCUSDT", Symbol: "BTC/USDT:USDT", Type: banexg.MarketLinear,
+		Contract: true, Linear: true, Swap: true, Base: "BTC", Quote: "USDT", Settle: "USDT"}
+	exg.Markets = banexg.MarketMap{"BTC/USDT:USDT": btcLinear}
+	exg.MarketsById = banexg.MarketArrMap{"BTCUSDT": {btcLinear}}
+	return exg
+}
+
+func TestAccountConfig(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://fapi.binance.com").Get("/fapi/v1/positionSide/dual").
+		Reply(200).JSON(map[string]interface{}{"dualSidePosition": true})
+	gock.New("https://fapi.binance.com").Post("/fapi/v1/marginType").
+		Reply(400).JSON(map[string]interface{}{"code": -4046, "msg": "No need to change margin type."})
+
+	hedged, err := exg.GetPositionMode(nil)
+	if err != nil || !hedged {
+		t.Fatalf("GetPositionMode: %v %v", hedged, err)
+	}
+	// cached, no request
+	hedged, err = exg.GetPositionMode(nil)
+	if err != nil || !hedged {
+		t.Fatalf("cached GetPositionMode: %v %v", hedged, err)
+	}
+	symbol := "BTC/USDT:USDT"
+	if err = exg.SetMarginMode(symbol, banexg.MarginIsolated, nil); err != nil {
+		t.Fatalf("no need to change should be ignored: %v", err)
+	}
+	mode, err := exg.GetMarginMode(symbol, nil)
+	if err != nil || mode != banexg.MarginIsolated {
+		t.Fatalf("GetMarginMode: %v %v", mode, err)
+	}
+	if !gock.IsDone() {
+		t.Error("pending mocks")
+	}
+
+	client := &banexg.WsClient{Exg: exg.Exchange, AccName: exg.DefAccName, MarketType: banexg.MarketLinear}
+	exg.handleAccountConfigUpdate(client, map[string]string{"e": "ACCOUNT_CONFIG_UPDATE", "ai": `{"j":true}`})
+	enabled, err := exg.GetMultiAssetsMode(nil)
+	if err != nil || !enabled {
+		t.Fatalf("GetMultiAssetsMode: %v %v", enabled, err)
+	}
+	if err = exg.SetMarginMode(symbol, "bad", nil); err == nil {
+		t.Error("invalid margin mode should fail")
+	}
+}
+
+func TestAccountOverrides(t *testing.T) {
+	exg, err := New(map[string]interface{}{
+		banexg.OptMarketType: banexg.MarketLinear,
+		banexg.OptAccCreds: map[string]map[string]interface{}{
+			"vip0": {banexg.OptApiKey: "k0", banexg.OptApiSecret: "s0"},
+			"vip3": {banexg.OptApiKey: "k3", banexg.OptApiSecret: "s3", banexg.OptMarginMode: banexg.MarginIsolated,
+				banexg.OptFees: map[string]map[string]float64{banexg.MarketLinear: {"maker": 0.00012, "taker": 0.0003}}},
+		},
+		banexg.OptAccName: "vip0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	btc := &banexg.Market{ID: "BTCUSDT", Symbol: "BTC/USDT:USDT", Type: banexg.MarketLinear, Contract: true,
+		Linear: true, Swap: true, Base: "BTC", Quote: "USDT", Settle: "USDT", Maker: 0.0002, Taker: 0.0005,
+		Precision: &banexg.Precision{Amount: 0.001, ModeAmount: banexg.PrecModeTickSize, Price: 0.1, ModePrice: banexg.PrecModeTickSize},
+		Info:      &BnbMarket{OrderTypes: []string{"LIMIT", "MARKET"}}}
+	exg.Markets = banexg.MarketMap{btc.Symbol: btc}
+	exg.MarketsById = banexg.MarketArrMap{btc.ID: {btc}}
+	fee0, err := exg.CalculateFee(btc.Symbol, banexg.OdTypeLimit, banexg.OdSideBuy, 1, 10000, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fee3, err := exg.CalculateFee(btc.Symbol, banexg.OdTypeLimit, banexg.OdSideBuy, 1, 10000, false,
+		map[string]interface{}{banexg.ParamAccount: "vip3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee0.Rate != 0.0005 || fee3.Rate != 0.0003 {
+		t.Errorf("account fee not resolved, vip0: %v, vip3: %v", fee0.Rate, fee3.Rate)
+	}
+	if mode := exg.GetAccMarginMode(btc.Symbol, "vip3"); mode != banexg.MarginIsolated {
+		t.Errorf("bad margin mode of vip3: %s", mode)
+	}
+	if mode := exg.GetAccMarginMode(btc.Symbol, "vip0"); mode != "" {
+		t.Errorf("bad margin mode of vip0: %s", mode)
+	}
+
+	acc, _ := exg.GetAccount("vip3")
+	acc.PosModes[banexg.MarketLinear] = true
+	args, _, _, err := exg.makeCreateOrderArgs(btc.Symbol, banexg.OdTypeMarket, banexg.OdSideSell, 0.01, 0,
+		map[string]interface{}{banexg.ParamAccount: "vip3", banexg.ParamReduceOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[banexg.ParamPositionSide] != "LONG" || args[banexg.ParamReduceOnly] != nil {
+		t.Errorf("hedge mode order should close long: %v", args)
+	}
+	args, _, _, err = exg.makeCreateOrderArgs(btc.Symbol, banexg.OdTypeMarket, banexg.OdSideSell, 0.01, 0,
+		map[string]interface{}{banexg.ParamAccount: "vip0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := args[banexg.ParamPositionSide]; ok {
+		t.Errorf("one-way mode should not set positionSide: %v", args)
+	}
+}
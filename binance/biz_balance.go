@@ -26,6 +26,7 @@ query for balance and get the amount of funds available for trading or funds loc
 :param str [params.market]: 'spot', 'future', 'swap', 'funding', or 'spot'
 :param str [params.marginMode]: 'cross' or 'isolated', for margin trading, uses self.options.defaultMarginMode if not passed, defaults to None/None/None
 :param str[]|None [params.symbols]: unified market symbols, only used in isolated margin mode
+:param bool [params.portfolioMargin]: fetch balances of portfolio margin account, default OptPortfolioMargin
 :returns dict: a `balance structure <https://docs.ccxt.com/#/?id=balance-structure>`
 */
 func (e *Binance) FetchBalance(params map[string]interface{}) (*banexg.Balances, *errs.Error) {
@@ -35,8 +36,11 @@ func (e *Binance) FetchBalance(params map[string]interface{}) (*banexg.Balances,
 		return nil, err
 	}
 	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
+	portfolio := e.isPortfolioMargin(args)
 	method := MethodPrivateGetAccount
-	if marketType == banexg.MarketLinear {
+	if portfolio && marketType != banexg.MarketFunding && marketType != banexg.MarketOption {
+		method = MethodPapiGetBalance
+	} else if marketType == banexg.MarketLinear {
 		method = MethodFapiPrivateV2GetAccount
 	} else if marketType == banexg.MarketInverse {
 		method = MethodDapiPrivateGetAccount
@@ -61,7 +65,7 @@ func (e *Binance) FetchBalance(params map[string]interface{}) (*banexg.Balances,
 		}
 	} else if marketType == banexg.MarketMargin || marginMode == banexg.MarginCross {
 		method = MethodSapiGetMarginAccount
-	} else if marketType == "funding" {
+	} else if marketType == banexg.MarketFunding {
 		method = MethodSapiPostAssetGetFundingAsset
 	}
 	tryNum := e.GetRetryNum("FetchBalance", 1)
@@ -85,6 +89,8 @@ func (e *Binance) FetchBalance(params map[string]interface{}) (*banexg.Balances,
 		return parseInverseBalances(getCurrCode, rsp)
 	case MethodSapiPostAssetGetFundingAsset:
 		return parseFundingBalances(e, rsp)
+	case MethodPapiGetBalance:
+		return parsePmBalances(getCurrCode, rsp)
 	default:
 		return nil, errs.NewMsg(errs.CodeNotSupport, "unsupport parse balance method: %s", method)
 	}
@@ -92,6 +98,7 @@ func (e *Binance) FetchBalance(params map[string]interface{}) (*banexg.Balances,
 
 /*
 FetchPositions get 'positionRisk' or 'account' positions (by banexg.OptPositionMethod)
+papi is used for portfolio margin account (by banexg.OptPortfolioMargin)
 */
 func (e *Binance) FetchPositions(symbols []string, params map[string]interface{}) ([]*banexg.Position, *errs.Error) {
 	args := utils.SafeParams(params)
@@ -112,11 +119,18 @@ func (e *Binance) FetchPositionsRisk(symbols []string, params map[string]interfa
 	if err != nil {
 		return nil, err
 	}
+	portfolio := e.isPortfolioMargin(args)
 	var method string
 	if marketType == banexg.MarketLinear {
 		method = MethodFapiPrivateV2GetPositionRisk
+		if portfolio {
+			method = MethodPapiGetUmPositionRisk
+		}
 	} else if marketType == banexg.MarketInverse {
 		method = MethodDapiPrivateGetPositionRisk
+		if portfolio {
+			method = MethodPapiGetCmPositionRisk
+		}
 	} else {
 		return nil, errs.NewMsg(errs.CodeInvalidRequest, "FetchPositionsRisk support linear/inverse contracts only")
 	}
@@ -151,11 +165,18 @@ func (e *Binance) FetchAccountPositions(symbols []string, params map[string]inte
 	if err != nil {
 		return nil, err
 	}
+	portfolio := e.isPortfolioMargin(args)
 	var method string
 	if marketType == banexg.MarketLinear {
 		method = MethodFapiPrivateV2GetAccount
+		if portfolio {
+			method = MethodPapiGetUmAccount
+		}
 	} else if marketType == banexg.MarketInverse {
 		method = MethodDapiPrivateGetAccount
+		if portfolio {
+			method = MethodPapiGetCmAccount
+		}
 	} else {
 		return nil, errs.NewMsg(errs.CodeInvalidRequest, "FetchAccountPositions support linear/inverse contracts only")
 	}
@@ -172,69 +193,92 @@ func (e *Binance) FetchAccountPositions(symbols []string, params map[string]inte
 }
 
 func (e *Binance) FetchIncomeHistory(inType string, symbol string, since int64, limit int, params map[string]interface{}) ([]*banexg.Income, *errs.Error) {
+	data, marketType, err := e.fetchIncomes(inType, symbol, since, limit, params)
+	if err != nil {
+		return nil, err
+	}
+	var res = make([]*banexg.Income, 0, len(data))
+	for _, it := range data {
+		market := e.GetMarketById(it.Symbol, marketType)
+		if market == nil {
+			log.Warn("no symbol for", zap.String("code", it.Symbol))
+			continue
+		}
+		income, _ := strconv.ParseFloat(it.Income, 64)
+		res = append(res, &banexg.Income{
+			Symbol:     market.Symbol,
+			IncomeType: it.IncomeType,
+			Income:     income,
+			Asset:      e.SafeCurrencyCode(it.Asset),
+			Info:       it.Info,
+			Time:       it.Time,
+			TranID:     strconv.FormatInt(it.TranID, 10),
+			TradeID:    it.TradeID,
+		})
+	}
+	return res, nil
+}
+
+/*
+fetchIncomes
+request income history of linear/inverse account, all types if inType is empty. return incomes and market type
+请求U本位/币本位合约账户的收益历史，inType为空时返回所有类型
+*/
+func (e *Binance) fetchIncomes(inType string, symbol string, since int64, limit int, params map[string]interface{}) ([]*Income, string, *errs.Error) {
 	args := utils.SafeParams(params)
 	var marketType string
 	var err *errs.Error
 	if symbol != "" {
 		market, err := e.GetMarket(symbol)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		if !market.Swap {
-			return nil, errs.NewMsg(errs.CodeUnsupportMarket, "FetchIncomeHistory support swap market only")
+			return nil, "", errs.NewMsg(errs.CodeUnsupportMarket, "FetchIncomeHistory support swap market only")
 		}
 		args["symbol"] = market.ID
 		marketType = market.Type
 	} else {
 		marketType, _, err = e.LoadArgsMarketType(args)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 	}
 	if !banexg.IsContract(marketType) {
-		return nil, errs.NewMsg(errs.CodeUnsupportMarket, "FetchIncomeHistory support future market only")
+		return nil, "", errs.NewMsg(errs.CodeUnsupportMarket, "FetchIncomeHistory support future market only")
+	}
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until > 0 {
+		args["endTime"] = until
+	}
+	if since > 0 {
+		args["startTime"] = since
+	}
+	if limit > 0 {
+		args["limit"] = limit
+	}
+	if inType != "" {
+		args["incomeType"] = inType
 	}
-	args["startTime"] = since
-	args["limit"] = limit
-	args["incomeType"] = inType
 	var method string
 	if marketType == banexg.MarketLinear {
 		method = MethodFapiPrivateGetIncome
 	} else if marketType == banexg.MarketInverse {
 		method = MethodDapiPrivateGetIncome
 	} else {
-		return nil, errs.NewMsg(errs.CodeUnsupportMarket, "FetchIncomeHistory not support: "+marketType)
+		return nil, "", errs.NewMsg(errs.CodeUnsupportMarket, "FetchIncomeHistory not support: "+marketType)
 	}
 	tryNum := e.GetRetryNum("FetchIncomeHistory", 1)
 	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
 	if rsp.Error != nil {
-		return nil, rsp.Error
+		return nil, "", rsp.Error
 	}
 	var data = make([]*Income, 0)
 	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
 	if err_ != nil {
-		return nil, errs.New(errs.CodeUnmarshalFail, err_)
-	}
-	var res = make([]*banexg.Income, 0, len(data))
-	for _, it := range data {
-		market := e.GetMarketById(it.Symbol, marketType)
-		if market == nil {
-			log.Warn("no symbol for", zap.String("code", it.Symbol))
-			continue
-		}
-		income, _ := strconv.ParseFloat(it.Income, 64)
-		res = append(res, &banexg.Income{
-			Symbol:     market.Symbol,
-			IncomeType: it.IncomeType,
-			Income:     income,
-			Asset:      e.SafeCurrencyCode(it.Asset),
-			Info:       it.Info,
-			Time:       it.Time,
-			TranID:     strconv.FormatInt(it.TranID, 10),
-			TradeID:    it.TradeID,
-		})
+		return nil, "", errs.New(errs.CodeUnmarshalFail, err_)
 	}
-	return res, nil
+	return data, marketType, nil
 }
 
 func parseAccPosition(e *Binance, rsp *banexg.HttpRes, marketType string) ([]*banexg.Position, *errs.Error) {
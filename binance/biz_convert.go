@@ -0,0 +1,214 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+)
+
+const convertMaxRange = int64(30 * 24 * 3600 * 1000) // max 30 days for convert trade flow
+
+/*
+FetchConvertQuote
+request quote to convert amount of fromCode to toCode
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#send-quote-request-user_data
+	:param str fromCode: unified currency code to sell
+	:param str toCode: unified currency code to buy
+	:param float amount: amount of fromCode
+	:param str [params.validTime]: 10s/30s/1m/2m, default 10s
+*/
+func (e *Binance) FetchConvertQuote(fromCode, toCode string, amount float64, params map[string]interface{}) (*banexg.ConvertQuote, *errs.Error) {
+	if fromCode == "" || toCode == "" || amount <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "fromCode, toCode and positive amount are required for FetchConvertQuote")
+	}
+	args := utils.SafeParams(params)
+	args["fromAsset"] = fromCode
+	args["toAsset"] = toCode
+	args["fromAmount"] = strconv.FormatFloat(amount, 'f', -1, 64)
+	tryNum := e.GetRetryNum("FetchConvertQuote", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiPostConvertGetQuote, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = ConvertQuoteRsp{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	fromAmount, _ := strconv.ParseFloat(data.FromAmount, 64)
+	toAmount, _ := strconv.ParseFloat(data.ToAmount, 64)
+	ratio, _ := strconv.ParseFloat(data.Ratio, 64)
+	return &banexg.ConvertQuote{
+		ID:         data.QuoteId,
+		From:       fromCode,
+		To:         toCode,
+		FromAmount: fromAmount,
+		ToAmount:   toAmount,
+		Price:      ratio,
+		Expire:     data.ValidTimestamp,
+		Info:       data,
+	}, nil
+}
+
+/*
+AcceptConvertQuote
+accept quote from FetchConvertQuote before it expires
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#accept-quote-trade
+*/
+func (e *Binance) AcceptConvertQuote(quoteId string, params map[string]interface{}) (*banexg.ConvertTrade, *errs.Error) {
+	if quoteId == "" {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "quoteId is required for AcceptConvertQuote")
+	}
+	args := utils.SafeParams(params)
+	args["quoteId"] = quoteId
+	tryNum := e.GetRetryNum("AcceptConvertQuote", 0)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiPostConvertAcceptQuote, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = ConvertAcceptRsp{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	return &banexg.ConvertTrade{
+		ID:        data.OrderId,
+		QuoteID:   quoteId,
+		Status:    parseConvertStatus(data.OrderStatus),
+		Timestamp: data.CreateTime,
+		Info:      data,
+	}, nil
+}
+
+/*
+FetchConvertHistory
+fetch convert trades, time range is limited to 30 days
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#get-convert-trade-history-user_data
+	:param str code: unified currency code of From or To, all if empty
+	:param int since: start time in ms, default 30 days ago
+	:param int limit: max 1000
+	:param int [params.until]: end time in ms
+*/
+func (e *Binance) FetchConvertHistory(code string, since int64, limit int, params map[string]interface{}) ([]*banexg.ConvertTrade, *errs.Error) {
+	args := utils.SafeParams(params)
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until == 0 {
+		until = e.MilliSeconds()
+		if since > 0 {
+			until = min(until, since+convertMaxRange)
+		}
+	}
+	if since == 0 {
+		since = until - convertMaxRange
+	}
+	args["startTime"] = since
+	args["endTime"] = until
+	if limit > 0 {
+		args["limit"] = min(limit, 1000)
+	}
+	tryNum := e.GetRetryNum("FetchConvertHistory", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetConvertTradeFlow, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parseConvertFlow(e, code, rsp.Content)
+}
+
+func parseConvertFlow(e *Binance, code, content string) ([]*banexg.ConvertTrade, *errs.Error) {
+	var data = ConvertFlowRsp{}
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.ConvertTrade, 0, len(data.List))
+	for _, it := range data.List {
+		from, to := e.SafeCurrencyCode(it.FromAsset), e.SafeCurrencyCode(it.ToAsset)
+		if code != "" && from != code && to != code {
+			continue
+		}
+		fromAmount, _ := strconv.ParseFloat(it.FromAmount, 64)
+		toAmount, _ := strconv.ParseFloat(it.ToAmount, 64)
+		ratio, _ := strconv.ParseFloat(it.Ratio, 64)
+		res = append(res, &banexg.ConvertTrade{
+			ID:         strconv.FormatInt(it.OrderId, 10),
+			QuoteID:    it.QuoteId,
+			From:       from,
+			To:         to,
+			FromAmount: fromAmount,
+			ToAmount:   toAmount,
+			Price:      ratio,
+			Status:     parseConvertStatus(it.OrderStatus),
+			Timestamp:  it.CreateTime,
+			Info:       it,
+		})
+	}
+	return res, nil
+}
+
+func parseConvertStatus(status string) string {
+	switch status {
+	case "SUCCESS", "ACCEPT_SUCCESS":
+		return banexg.TxStatusOk
+	case "FAIL", "EXPIRED":
+		return banexg.TxStatusFailed
+	default:
+		return banexg.TxStatusPending
+	}
+}
+
+/*
+ConvertDust
+convert small balances in spot wallet to BNB
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#dust-transfer-user_data
+	:param []str codes: unified currency codes to convert
+*/
+func (e *Binance) ConvertDust(codes []string, params map[string]interface{}) ([]*banexg.ConvertTrade, *errs.Error) {
+	if len(codes) == 0 {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "codes are required for ConvertDust")
+	}
+	args := utils.SafeParams(params)
+	args["asset"] = codes
+	tryNum := e.GetRetryNum("ConvertDust", 0)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiPostAssetDust, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parseDustResult(e, rsp.Content)
+}
+
+func parseDustResult(e *Binance, content string) ([]*banexg.ConvertTrade, *errs.Error) {
+	var data = DustRsp{}
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.ConvertTrade, 0, len(data.TransferResult))
+	for _, it := range data.TransferResult {
+		fromAmount, _ := strconv.ParseFloat(it.Amount, 64)
+		toAmount, _ := strconv.ParseFloat(it.TransferedAmount, 64)
+		fee, _ := strconv.ParseFloat(it.ServiceChargeAmount, 64)
+		var price float64
+		if fromAmount > 0 {
+			price = toAmount / fromAmount
+		}
+		res = append(res, &banexg.ConvertTrade{
+			ID:         strconv.FormatInt(it.TranId, 10),
+			From:       e.SafeCurrencyCode(it.FromAsset),
+			To:         "BNB",
+			FromAmount: fromAmount,
+			ToAmount:   toAmount,
+			Price:      price,
+			Fee:        fee,
+			Status:     banexg.TxStatusOk,
+			Timestamp:  it.OperateTime,
+			Info:       it,
+		})
+	}
+	return res, nil
+}
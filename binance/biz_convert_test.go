@@ -0,0 +1,65 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func TestConvertQuote(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://api.binance.com").Post("/sapi/v1/convert/getQuote").
+		Reply(200).BodyString(`{"quoteId":"12415572564","ratio":"38163.7","inverseRatio":"0.0000262","validTimestamp":1623319461670,"toAmount":"3816.37","fromAmount":"0.1"}`)
+	gock.New("https://api.binance.com").Post("/sapi/v1/convert/acceptQuote").
+		Reply(200).BodyString(`{"orderId":"933256278426274426","createTime":1623381330472,"orderStatus":"PROCESS"}`)
+	quote, err := exg.FetchConvertQuote("BTC", "USDT", 0.1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.ID != "12415572564" || quote.Price != 38163.7 || quote.ToAmount != 3816.37 || quote.Expire != 1623319461670 {
+		t.Errorf("bad quote: %+v", quote)
+	}
+	trade, err := exg.AcceptConvertQuote(quote.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trade.ID != "933256278426274426" || trade.Status != banexg.TxStatusPending || trade.QuoteID != quote.ID {
+		t.Errorf("bad accept: %+v", trade)
+	}
+}
+
+func TestParseConvertFlow(t *testing.T) {
+	exg := getOfflineLinear(t)
+	content := `{"list":[{"quoteId":"f3b91c525b2644c7bc1e1cd31b6e1aa6","orderId":940708407462087195,"orderStatus":"SUCCESS",
+"fromAsset":"USDT","fromAmount":"20","toAsset":"BNB","toAmount":"0.06154036","ratio":"0.00307702","inverseRatio":"324.99","createTime":1624248872184},
+{"quoteId":"x","orderId":1,"orderStatus":"FAIL","fromAsset":"ETH","fromAmount":"1","toAsset":"BTC","toAmount":"0.05","ratio":"0.05","createTime":1624248872185}],
+"startTime":1623824139000,"endTime":1626416139000,"limit":100,"moreData":false}`
+	res, err := parseConvertFlow(exg, "BNB", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].ID != "940708407462087195" || res[0].Status != banexg.TxStatusOk || res[0].FromAmount != 20 {
+		t.Errorf("bad convert flow: %+v", res)
+	}
+}
+
+func TestConvertDust(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://api.binance.com").Post("/sapi/v1/asset/dust").BodyString(`asset=ETH&asset=LTC&`).
+		Reply(200).BodyString(`{"totalServiceCharge":"0.02102542","totalTransfered":"1.05127099","transferResult":[
+{"amount":"0.03000000","fromAsset":"ETH","operateTime":1563368549307,"serviceChargeAmount":"0.00500000","tranId":2970932918,"transferedAmount":"0.25000000"},
+{"amount":"0.09000000","fromAsset":"LTC","operateTime":1563368549404,"serviceChargeAmount":"0.01548000","tranId":2970932918,"transferedAmount":"0.77400000"}]}`)
+	res, err := exg.ConvertDust([]string{"ETH", "LTC"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].To != "BNB" || res[0].ToAmount != 0.25 || res[1].Fee != 0.01548 {
+		t.Errorf("bad dust result: %+v", res)
+	}
+}
@@ -0,0 +1,117 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+)
+
+/*
+FetchTradingFees
+fetch maker/taker fee rates of account, VIP level and BNB discount are included.
+contract markets require symbols, one request for each symbol.
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#trade-fee-user_data
+	:see: https://binance-docs.github.io/apidocs/futures/en/#user-commission-rate-user_data
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#user-commission-rate-user_data
+	:param []str symbols: unified symbols, all spot symbols if empty
+	:param bool [params.applyFees]: save fees to account, then used by CalculateFee
+*/
+func (e *Binance) FetchTradingFees(symbols []string, params map[string]interface{}) ([]*banexg.TradingFee, *errs.Error) {
+	args := utils.SafeParams(params)
+	apply := utils.PopMapVal(args, banexg.ParamApplyFees, false)
+	accName := e.GetAccName(args)
+	marketType, _, err := e.LoadArgsMarketType(args, symbols...)
+	if err != nil {
+		return nil, err
+	}
+	var res []*banexg.TradingFee
+	if marketType == banexg.MarketSpot || marketType == banexg.MarketMargin {
+		res, err = e.fetchSpotTradeFees(symbols, args)
+	} else if marketType == banexg.MarketLinear || marketType == banexg.MarketInverse {
+		res, err = e.fetchCommissionRates(symbols, args)
+	} else {
+		return nil, errs.NewMsg(errs.CodeUnsupportMarket, "FetchTradingFees not support %s", marketType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if apply {
+		err = e.SetTradingFees(accName, res)
+	}
+	return res, err
+}
+
+func (e *Binance) fetchSpotTradeFees(symbols []string, args map[string]interface{}) ([]*banexg.TradingFee, *errs.Error) {
+	var wants = make(map[string]bool)
+	for _, symbol := range symbols {
+		market, ok := e.Markets[symbol]
+		if !ok {
+			return nil, errs.NewMsg(errs.CodeNoMarketForPair, "no market for %s", symbol)
+		}
+		wants[market.Symbol] = true
+		if len(symbols) == 1 {
+			args["symbol"] = market.ID
+		}
+	}
+	tryNum := e.GetRetryNum("FetchTradingFees", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetAssetTradeFee, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = make([]*SpotTradeFee, 0)
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.TradingFee, 0, len(data))
+	for _, it := range data {
+		symbol := e.SafeSymbol(it.Symbol, "", banexg.MarketSpot)
+		if symbol == "" || len(wants) > 0 && !wants[symbol] {
+			continue
+		}
+		maker, _ := strconv.ParseFloat(it.MakerCommission, 64)
+		taker, _ := strconv.ParseFloat(it.TakerCommission, 64)
+		res = append(res, &banexg.TradingFee{Symbol: symbol, Maker: maker, Taker: taker, Info: it})
+	}
+	return res, nil
+}
+
+func (e *Binance) fetchCommissionRates(symbols []string, args map[string]interface{}) ([]*banexg.TradingFee, *errs.Error) {
+	if len(symbols) == 0 {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "FetchTradingFees requires symbols for contracts")
+	}
+	tryNum := e.GetRetryNum("FetchTradingFees", 1)
+	var res = make([]*banexg.TradingFee, 0, len(symbols))
+	for _, symbol := range symbols {
+		market, err := e.GetMarket(symbol)
+		if err != nil {
+			return nil, err
+		}
+		var method string
+		if market.Linear {
+			method = MethodFapiPrivateGetCommissionRate
+		} else if market.Inverse {
+			method = MethodDapiPrivateGetCommissionRate
+		} else {
+			return nil, errs.NewMsg(errs.CodeUnsupportMarket, "%s is not a contract", symbol)
+		}
+		symArgs := utils.SafeParams(args)
+		symArgs["symbol"] = market.ID
+		rsp := e.RequestApiRetry(context.Background(), method, symArgs, tryNum)
+		if rsp.Error != nil {
+			return nil, rsp.Error
+		}
+		var data = CommissionRate{}
+		err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+		if err_ != nil {
+			return nil, errs.New(errs.CodeUnmarshalFail, err_)
+		}
+		maker, _ := strconv.ParseFloat(data.MakerCommissionRate, 64)
+		taker, _ := strconv.ParseFloat(data.TakerCommissionRate, 64)
+		res = append(res, &banexg.TradingFee{Symbol: market.Symbol, Maker: maker, Taker: taker, Info: data})
+	}
+	return res, nil
+}
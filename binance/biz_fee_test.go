@@ -0,0 +1,41 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func TestFetchTradingFees(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://fapi.binance.com").Get("/fapi/v1/commissionRate").MatchParam("symbol", "BTCUSDT").
+		Reply(200).JSON(map[string]interface{}{"symbol": "BTCUSDT", "makerCommissionRate": "0.00018", "takerCommissionRate": "0.00045"})
+	symbol := "BTC/USDT:USDT"
+	fee, err := exg.CalculateFee(symbol, banexg.OdTypeLimit, banexg.OdSideBuy, 1, 10000, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.Cost != 0 {
+		t.Fatalf("market fee should be zero, got %v", fee.Cost)
+	}
+	res, err := exg.FetchTradingFees([]string{symbol}, map[string]interface{}{banexg.ParamApplyFees: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Symbol != symbol || res[0].Maker != 0.00018 || res[0].Taker != 0.00045 {
+		t.Fatalf("bad fees: %+v", res)
+	}
+	fee, err = exg.CalculateFee(symbol, banexg.OdTypeLimit, banexg.OdSideBuy, 1, 10000, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.Rate != 0.00045 || fee.Cost != 4.5 || fee.Currency != "USDT" {
+		t.Errorf("account fee not applied: %+v", fee)
+	}
+	if _, err = exg.FetchTradingFees(nil, nil); err == nil {
+		t.Error("contract fees without symbols should fail")
+	}
+}
@@ -0,0 +1,346 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"sort"
+	"strconv"
+)
+
+// incomeLedgerTypes income type of futures to ledger type 合约收益类型对应的账本类型
+var incomeLedgerTypes = map[string]string{
+	"REALIZED_PNL":              banexg.LedgerPnl,
+	"COMMISSION":                banexg.LedgerFee,
+	"FUNDING_FEE":               banexg.LedgerFunding,
+	"TRANSFER":                  banexg.LedgerTransfer,
+	"INTERNAL_TRANSFER":         banexg.LedgerTransfer,
+	"CROSS_COLLATERAL_TRANSFER": banexg.LedgerTransfer,
+	"COMMISSION_REBATE":         banexg.LedgerRebate,
+	"API_REBATE":                banexg.LedgerRebate,
+	"REFERRAL_KICKBACK":         banexg.LedgerRebate,
+}
+
+// ledgerTransferPeers wallets whose transfers with spot are recorded in spot ledger 现货账本记录与这些钱包的划转
+var ledgerTransferPeers = []string{banexg.MarketLinear, banexg.MarketInverse, banexg.MarketFunding}
+
+/*
+FetchLedger
+fetch cash flows of account, sorted by time ascending.
+linear/inverse: income history(pnl, fee, funding, transfer...)
+spot: deposits, withdrawals, transfers with linear/inverse/funding wallets, and commissions of params.symbol(s)
+margin: borrow interest and transfers with spot wallet
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#get-income-history-user_data
+	:see: https://binance-docs.github.io/apidocs/spot/en/#deposit-history-supporting-network-user_data
+	:see: https://binance-docs.github.io/apidocs/spot/en/#query-user-universal-transfer-history-user_data
+	:param str code: unified currency code, all if empty
+	:param int since: start time in ms
+	:param int limit: max number of entries
+	:param str [params.market]: market type of account
+	:param int [params.until]: end time in ms
+	:param str [params.symbol]: spot symbol whose trade commissions are included
+	:param []str [params.symbols]: spot symbols whose trade commissions are included
+*/
+func (e *Binance) FetchLedger(code string, since int64, limit int, params map[string]interface{}) ([]*banexg.LedgerEntry, *errs.Error) {
+	args := utils.SafeParams(params)
+	marketType, _, err := e.LoadArgsMarketType(args)
+	if err != nil {
+		return nil, err
+	}
+	var res []*banexg.LedgerEntry
+	switch marketType {
+	case banexg.MarketLinear, banexg.MarketInverse:
+		args[banexg.ParamMarket] = marketType
+		res, err = e.fetchIncomeLedger(since, limit, args)
+	case banexg.MarketSpot:
+		res, err = e.fetchSpotLedger(code, since, args)
+	case banexg.MarketMargin:
+		res, err = e.fetchMarginLedger(code, since, limit, args)
+	default:
+		return nil, errs.NewMsg(errs.CodeUnsupportMarket, "FetchLedger not support: "+marketType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sortLedger(res, code, limit), nil
+}
+
+func (e *Binance) fetchIncomeLedger(since int64, limit int, args map[string]interface{}) ([]*banexg.LedgerEntry, *errs.Error) {
+	data, marketType, err := e.fetchIncomes("", "", since, limit, args)
+	if err != nil {
+		return nil, err
+	}
+	return parseIncomeLedger(e, marketType, data), nil
+}
+
+func parseIncomeLedger(e *Binance, marketType string, data []*Income) []*banexg.LedgerEntry {
+	var res = make([]*banexg.LedgerEntry, 0, len(data))
+	for _, it := range data {
+		symbol := ""
+		if it.Symbol != "" {
+			symbol = e.SafeSymbol(it.Symbol, "", marketType)
+		}
+		ledgerType, ok := incomeLedgerTypes[it.IncomeType]
+		if !ok {
+			ledgerType = banexg.LedgerOther
+		}
+		tranId := strconv.FormatInt(it.TranID, 10)
+		refId := it.TradeID
+		if refId == "" {
+			refId = tranId
+		}
+		amount, _ := strconv.ParseFloat(it.Income, 64)
+		res = append(res, &banexg.LedgerEntry{
+			ID:        tranId + "_" + it.IncomeType,
+			RefID:     refId,
+			Type:      ledgerType,
+			Code:      e.SafeCurrencyCode(it.Asset),
+			Symbol:    symbol,
+			Amount:    amount,
+			Timestamp: it.Time,
+			Info:      it,
+		})
+	}
+	return res
+}
+
+const (
+	txWindowMS = int64(90 * 24 * 3600 * 1000) // max time range of deposit/withdraw history 充值提现历史的最大时间范围
+	txPageSize = 1000
+)
+
+/*
+fetchSpotLedger
+every source is paged until exhausted in [since, until] before merged, so that limit is applied to the merged result.
+The last 90 days are fetched when since is 0.
+Commissions of spot trades are included for symbols in params.symbol/params.symbols, since the api requires symbol.
+每个来源在[since, until]内分页请求完后再合并，使limit作用于合并结果。since为0时获取最近90天。
+现货成交手续费仅包含params.symbol/params.symbols中的品种，因接口要求指定品种。
+*/
+func (e *Binance) fetchSpotLedger(code string, since int64, args map[string]interface{}) ([]*banexg.LedgerEntry, *errs.Error) {
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until <= 0 {
+		until = e.MilliSeconds()
+	}
+	if since <= 0 {
+		since = until - txWindowMS
+	}
+	symbols := utils.PopMapVal(args, banexg.ParamSymbols, []string(nil))
+	if symbol := utils.PopMapVal(args, banexg.ParamSymbol, ""); symbol != "" {
+		symbols = append(symbols, symbol)
+	}
+	deposits, err := e.fetchTxPages(e.FetchDeposits, code, since, until, args)
+	if err != nil {
+		return nil, err
+	}
+	withdraws, err := e.fetchTxPages(e.FetchWithdrawals, code, since, until, args)
+	if err != nil {
+		return nil, err
+	}
+	res := txsToLedger(append(deposits, withdraws...))
+	for _, peer := range ledgerTransferPeers {
+		items, err := e.fetchTransferLedger(code, since, until, banexg.MarketSpot, peer, args)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, items...)
+	}
+	for _, symbol := range symbols {
+		items, err := e.fetchCommissionLedger(symbol, since, until, args)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, items...)
+	}
+	return res, nil
+}
+
+/*
+fetchTxPages
+fetch all deposits or withdrawals in [since, until], by windows of 90 days and offset in each window
+按90天窗口和窗口内偏移量获取[since, until]内的全部充值或提现
+*/
+func (e *Binance) fetchTxPages(fetch func(code string, since int64, limit int, params map[string]interface{}) ([]*banexg.Transaction, *errs.Error),
+	code string, since, until int64, args map[string]interface{}) ([]*banexg.Transaction, *errs.Error) {
+	var res []*banexg.Transaction
+	for start := since; start <= until; start += txWindowMS {
+		end := min(start+txWindowMS-1, until)
+		for offset := 0; ; offset += txPageSize {
+			reqArgs := utils.SafeParams(args)
+			reqArgs[banexg.ParamUntil] = end
+			reqArgs["offset"] = offset
+			items, err := fetch(code, start, txPageSize, reqArgs)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, items...)
+			if len(items) < txPageSize {
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+/*
+fetchCommissionLedger
+commissions of spot trades of symbol in [since, until]
+品种在[since, until]内现货成交的手续费
+*/
+func (e *Binance) fetchCommissionLedger(symbol string, since, until int64, args map[string]interface{}) ([]*banexg.LedgerEntry, *errs.Error) {
+	reqArgs := utils.SafeParams(args)
+	reqArgs[banexg.ParamMarket] = banexg.MarketSpot
+	trades, err := banexg.IterMyTrades(context.Background(), e, symbol, since, until, 0, reqArgs).All()
+	if err != nil {
+		return nil, err
+	}
+	var res = make([]*banexg.LedgerEntry, 0, len(trades))
+	for _, it := range trades {
+		if it.Fee == nil || it.Fee.Cost == 0 {
+			continue
+		}
+		res = append(res, &banexg.LedgerEntry{
+			ID:        "commission_" + it.Symbol + "_" + it.ID,
+			RefID:     it.ID,
+			Type:      banexg.LedgerFee,
+			Code:      it.Fee.Currency,
+			Symbol:    it.Symbol,
+			Amount:    -it.Fee.Cost,
+			Timestamp: it.Timestamp,
+			Info:      it.Info,
+		})
+	}
+	return res, nil
+}
+
+func (e *Binance) fetchMarginLedger(code string, since int64, limit int, args map[string]interface{}) ([]*banexg.LedgerEntry, *errs.Error) {
+	interests, err := e.FetchBorrowInterest(code, since, limit, args)
+	if err != nil {
+		return nil, err
+	}
+	var res = make([]*banexg.LedgerEntry, 0, len(interests))
+	for _, it := range interests {
+		res = append(res, &banexg.LedgerEntry{
+			ID:        "interest_" + strconv.FormatInt(it.Timestamp, 10) + "_" + it.Code,
+			Type:      banexg.LedgerInterest,
+			Code:      it.Code,
+			Symbol:    it.Symbol,
+			Amount:    -it.Interest,
+			Timestamp: it.Timestamp,
+			Info:      it.Info,
+		})
+	}
+	until := utils.GetMapVal(args, banexg.ParamUntil, int64(0))
+	items, err := e.fetchTransferLedger(code, since, until, banexg.MarketMargin, banexg.MarketSpot, args)
+	if err != nil {
+		return nil, err
+	}
+	return append(res, items...), nil
+}
+
+/*
+fetchTransferLedger
+fetch all transfers in both directions between wallet and peer, amount is positive when transferred into wallet
+查询钱包与peer之间双向的全部划转，转入钱包时金额为正
+*/
+func (e *Binance) fetchTransferLedger(code string, since, until int64, wallet, peer string, args map[string]interface{}) ([]*banexg.LedgerEntry, *errs.Error) {
+	var res []*banexg.LedgerEntry
+	for _, pair := range [][2]string{{wallet, peer}, {peer, wallet}} {
+		reqArgs := utils.SafeParams(args)
+		reqArgs[banexg.ParamFromMarket] = pair[0]
+		reqArgs[banexg.ParamToMarket] = pair[1]
+		if until > 0 {
+			reqArgs[banexg.ParamUntil] = until
+		}
+		query, err := e.loadTransferQuery(since, reqArgs)
+		if err != nil {
+			return nil, err
+		}
+		items, err := e.fetchTransferPages(query, code, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			if it.Status != banexg.TxStatusOk {
+				continue
+			}
+			amount := it.Amount
+			if it.From == wallet {
+				amount = -amount
+			}
+			res = append(res, &banexg.LedgerEntry{
+				ID:        "transfer_" + it.ID,
+				RefID:     it.ID,
+				Type:      banexg.LedgerTransfer,
+				Code:      it.Code,
+				Amount:    amount,
+				Timestamp: it.Timestamp,
+				Info:      it.Info,
+			})
+		}
+	}
+	return res, nil
+}
+
+/*
+txsToLedger
+convert deposits and withdrawals to ledger entries, failed or canceled ones are skipped. withdrawal fee is a separate entry
+将充值提现转为账本记录，跳过失败或取消的。提现手续费单独记录
+*/
+func txsToLedger(txs []*banexg.Transaction) []*banexg.LedgerEntry {
+	var res = make([]*banexg.LedgerEntry, 0, len(txs))
+	for _, tx := range txs {
+		if tx.Status == banexg.TxStatusFailed || tx.Status == banexg.TxStatusCanceled {
+			continue
+		}
+		entry := &banexg.LedgerEntry{
+			ID:        tx.Type + "_" + tx.ID,
+			RefID:     tx.ID,
+			Type:      banexg.LedgerDeposit,
+			Code:      tx.Code,
+			Amount:    tx.Amount,
+			Timestamp: tx.Timestamp,
+			Info:      tx.Info,
+		}
+		if tx.Type == banexg.TxTypeWithdrawal {
+			entry.Type = banexg.LedgerWithdraw
+			entry.Amount = -tx.Amount
+			if tx.Fee > 0 {
+				res = append(res, &banexg.LedgerEntry{
+					ID:        "fee_" + tx.ID,
+					RefID:     tx.ID,
+					Type:      banexg.LedgerFee,
+					Code:      tx.Code,
+					Amount:    -tx.Fee,
+					Timestamp: tx.Timestamp,
+					Info:      tx.Info,
+				})
+			}
+		}
+		res = append(res, entry)
+	}
+	return res
+}
+
+/*
+sortLedger
+sort entries by time ascending, filter by code and keep the earliest limit entries
+按时间升序排列，按币种过滤，保留最早的limit条
+*/
+func sortLedger(items []*banexg.LedgerEntry, code string, limit int) []*banexg.LedgerEntry {
+	var res = make([]*banexg.LedgerEntry, 0, len(items))
+	for _, it := range items {
+		if code == "" || it.Code == code {
+			res = append(res, it)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Timestamp < res[j].Timestamp
+	})
+	if limit > 0 && len(res) > limit {
+		res = res[:limit]
+	}
+	return res
+}
@@ -0,0 +1,111 @@
+package binance
+
+import (
+	"fmt"
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"strings"
+	"testing"
+)
+
+func TestFetchLedgerLinear(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://fapi.binance.com").Get("/fapi/v1/income").
+		MatchParam("startTime", "1700000000000").
+		Reply(200).BodyString(`[
+{"symbol":"BTCUSDT","incomeType":"FUNDING_FEE","income":"-0.5","asset":"USDT","info":"","time":1700000300000,"tranId":3,"tradeId":""},
+{"symbol":"BTCUSDT","incomeType":"COMMISSION","income":"-0.12","asset":"USDT","info":"","time":1700000100000,"tranId":2,"tradeId":"888"},
+{"symbol":"","incomeType":"TRANSFER","income":"100","asset":"USDT","info":"","time":1700000000000,"tranId":1,"tradeId":""},
+{"symbol":"BTCUSDT","incomeType":"STRATEGY_UMFUTURES_TRANSFER","income":"1","asset":"USDT","info":"","time":1700000400000,"tranId":4,"tradeId":""}]`)
+	res, err := exg.FetchLedger("", 1700000000000, 3, map[string]interface{}{banexg.ParamMarket: banexg.MarketLinear})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("expect 3 entries, got %d", len(res))
+	}
+	if res[0].Type != banexg.LedgerTransfer || res[0].Amount != 100 || res[0].Symbol != "" || res[0].RefID != "1" {
+		t.Errorf("bad transfer entry: %+v", res[0])
+	}
+	if res[1].Type != banexg.LedgerFee || res[1].RefID != "888" || res[1].Symbol != "BTC/USDT:USDT" || res[1].Code != "USDT" {
+		t.Errorf("bad fee entry: %+v", res[1])
+	}
+	if res[2].Type != banexg.LedgerFunding || res[2].Amount != -0.5 {
+		t.Errorf("bad funding entry: %+v", res[2])
+	}
+}
+
+func TestTxsToLedger(t *testing.T) {
+	txs := []*banexg.Transaction{
+		{ID: "d1", Type: banexg.TxTypeDeposit, Code: "USDT", Amount: 50, Status: banexg.TxStatusOk, Timestamp: 20},
+		{ID: "w1", Type: banexg.TxTypeWithdrawal, Code: "USDT", Amount: 10, Fee: 1, Status: banexg.TxStatusOk, Timestamp: 10},
+		{ID: "w2", Type: banexg.TxTypeWithdrawal, Code: "USDT", Amount: 5, Status: banexg.TxStatusCanceled, Timestamp: 5},
+		{ID: "d2", Type: banexg.TxTypeDeposit, Code: "BTC", Amount: 1, Status: banexg.TxStatusOk, Timestamp: 1},
+	}
+	res := sortLedger(txsToLedger(txs), "USDT", 0)
+	if len(res) != 3 {
+		t.Fatalf("expect 3 entries, got %d", len(res))
+	}
+	if res[0].Type != banexg.LedgerFee || res[0].Amount != -1 || res[1].Type != banexg.LedgerWithdraw || res[1].Amount != -10 {
+		t.Errorf("bad withdrawal entries: %+v %+v", res[0], res[1])
+	}
+	if res[2].Type != banexg.LedgerDeposit || res[2].Amount != 50 || res[2].RefID != "d1" {
+		t.Errorf("bad deposit entry: %+v", res[2])
+	}
+}
+
+func TestFetchLedgerSpot(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	btcSpot := &banexg.Market{ID: "BTCUSDT", Symbol: "BTC/USDT", Type: banexg.MarketSpot, Spot: true,
+		Base: "BTC", Quote: "USDT"}
+	// withdrawal history costs 1800, skip the rate limit wait
+	exg.EnableRateLimit = banexg.BoolFalse
+	exg.Markets["BTC/USDT"] = btcSpot
+	exg.MarketsById["BTCUSDT"] = append([]*banexg.Market{btcSpot}, exg.MarketsById["BTCUSDT"]...)
+	since := int64(1700000000000)
+	until := since + 3600000
+	// deposits are paged by offset until a short page
+	var rows []string
+	for i := 0; i < txPageSize; i++ {
+		rows = append(rows, fmt.Sprintf(`{"id":"d%d","amount":"1","coin":"USDT","status":1,"insertTime":%d}`, i, since+int64(i)))
+	}
+	gock.New("https://api.binance.com").Get("/sapi/v1/capital/deposit/hisrec").MatchParam("offset", "^0$").
+		Reply(200).BodyString("[" + strings.Join(rows, ",") + "]")
+	gock.New("https://api.binance.com").Get("/sapi/v1/capital/deposit/hisrec").MatchParam("offset", "^1000$").
+		Reply(200).BodyString(`[{"id":"last","amount":"2","coin":"USDT","status":1,"insertTime":1700000002000}]`)
+	gock.New("https://api.binance.com").Get("/sapi/v1/capital/withdraw/history").Reply(200).BodyString("[]")
+	gock.New("https://api.binance.com").Get("/sapi/v1/asset/transfer").Persist().
+		Reply(200).BodyString(`{"total":0,"rows":[]}`)
+	gock.New("https://api.binance.com").Get("/api/v3/myTrades").MatchParam("symbol", "BTCUSDT").Persist().
+		Reply(200).BodyString(`[{"symbol":"BTCUSDT","id":28457,"orderId":100234,"price":"40000","qty":"0.01",
+"quoteQty":"400","commission":"0.4","commissionAsset":"USDT","time":1700000001000,"isBuyer":true,"isMaker":false}]`)
+	res, err := exg.FetchLedger("", since, 0, map[string]interface{}{
+		banexg.ParamMarket: banexg.MarketSpot,
+		banexg.ParamUntil:  until,
+		banexg.ParamSymbol: "BTC/USDT",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != txPageSize+2 {
+		t.Fatalf("expect %d entries, got %d", txPageSize+2, len(res))
+	}
+	var fee *banexg.LedgerEntry
+	for _, it := range res {
+		if it.Type == banexg.LedgerFee {
+			fee = it
+		}
+	}
+	if fee == nil || fee.Amount != -0.4 || fee.Code != "USDT" || fee.Symbol != "BTC/USDT" || fee.RefID != "28457" {
+		t.Errorf("bad commission entry: %+v", fee)
+	}
+	if last := res[len(res)-1]; last.RefID != "last" {
+		t.Errorf("deposit of second page should be the last entry: %+v", last)
+	}
+}
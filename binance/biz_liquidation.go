@@ -0,0 +1,253 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+	"strings"
+)
+
+/*
+FetchLiquidations
+fetch forced close orders of account, includes liquidation and ADL for linear/inverse
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#user-39-s-force-orders-user_data
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#user-39-s-force-orders-user_data
+	:see: https://binance-docs.github.io/apidocs/spot/en/#get-force-liquidation-record-user_data
+	:see: https://binance-docs.github.io/apidocs/pm/en/#query-user-39-s-um-force-orders-user_data
+	:param str symbol: unified market symbol, all if empty
+	:param int since: start time in ms
+	:param int limit: max 100
+	:param str [params.autoCloseType]: LIQUIDATION or ADL, contracts only
+	:param int [params.until]: end time in ms
+	:param bool [params.portfolioMargin]: query portfolio margin account, default OptPortfolioMargin
+*/
+func (e *Binance) FetchLiquidations(symbol string, since int64, limit int, params map[string]interface{}) ([]*banexg.Liquidation, *errs.Error) {
+	var args map[string]interface{}
+	var marketType string
+	if symbol != "" {
+		argsIn, market, err := e.LoadArgsMarket(symbol, params)
+		if err != nil {
+			return nil, err
+		}
+		args = argsIn
+		args["symbol"] = market.ID
+		marketType = market.Type
+	} else {
+		args = utils.SafeParams(params)
+		var err *errs.Error
+		marketType, _, err = e.LoadArgsMarketType(args)
+		if err != nil {
+			return nil, err
+		}
+	}
+	portfolio := e.isPortfolioMargin(args)
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until > 0 {
+		args["endTime"] = until
+	}
+	if since > 0 {
+		args["startTime"] = since
+	}
+	limitKey := "limit"
+	var method string
+	switch marketType {
+	case banexg.MarketLinear:
+		method = MethodFapiPrivateGetForceOrders
+		if portfolio {
+			method = MethodPapiGetUmForceOrders
+		}
+	case banexg.MarketInverse:
+		method = MethodDapiPrivateGetForceOrders
+		if portfolio {
+			method = MethodPapiGetCmForceOrders
+		}
+	case banexg.MarketSpot, banexg.MarketMargin:
+		// margin records of all symbols are returned, filter later 返回所有标的的记录，后续过滤
+		delete(args, "symbol")
+		limitKey = "size"
+		method = MethodSapiGetMarginForceLiquidationRec
+		if portfolio {
+			method = MethodPapiGetMarginForceOrders
+		}
+	default:
+		return nil, errs.NewMsg(errs.CodeUnsupportMarket, "FetchLiquidations not support: "+marketType)
+	}
+	if limit > 0 {
+		args[limitKey] = min(limit, 100)
+	}
+	tryNum := e.GetRetryNum("FetchLiquidations", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var mapSymbol = func(mid string) string {
+		return e.SafeSymbol(mid, "", marketType)
+	}
+	switch marketType {
+	case banexg.MarketLinear:
+		return parseForceOrders[*FutureOrder](mapSymbol, rsp)
+	case banexg.MarketInverse:
+		return parseForceOrders[*InverseOrder](mapSymbol, rsp)
+	}
+	res, err := parseMarginForceOrders(mapSymbol, rsp.Content)
+	if err != nil || symbol == "" {
+		return res, err
+	}
+	var result = make([]*banexg.Liquidation, 0, len(res))
+	for _, it := range res {
+		if it.Symbol == symbol {
+			result = append(result, it)
+		}
+	}
+	return result, nil
+}
+
+func parseForceOrders[T IBnbOrder](mapSymbol func(string) string, rsp *banexg.HttpRes) ([]*banexg.Liquidation, *errs.Error) {
+	orders, err := parseOrders[T](mapSymbol, rsp)
+	if err != nil {
+		return nil, err
+	}
+	var res = make([]*banexg.Liquidation, 0, len(orders))
+	for _, od := range orders {
+		// clientOrderId: autoclose-xxx for liquidation, adl_autoclose for ADL
+		liqType := banexg.LiqTypeLiquidation
+		if strings.HasPrefix(od.ClientOrderID, "adl_") {
+			liqType = banexg.LiqTypeADL
+		}
+		res = append(res, &banexg.Liquidation{
+			ID:        od.ID,
+			Symbol:    od.Symbol,
+			Type:      liqType,
+			Side:      od.Side,
+			Price:     od.Price,
+			Average:   od.Average,
+			Amount:    od.Amount,
+			Filled:    od.Filled,
+			Status:    od.Status,
+			Timestamp: od.Timestamp,
+			Info:      od.Info,
+		})
+	}
+	return res, nil
+}
+
+func parseMarginForceOrders(mapSymbol func(string) string, content string) ([]*banexg.Liquidation, *errs.Error) {
+	var data = ForceLiquidationRsp{}
+	err := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err)
+	}
+	var res = make([]*banexg.Liquidation, 0, len(data.Rows))
+	for _, it := range data.Rows {
+		price, _ := strconv.ParseFloat(it.Price, 64)
+		average, _ := strconv.ParseFloat(it.AvgPrice, 64)
+		amount, _ := strconv.ParseFloat(it.Qty, 64)
+		filled, _ := strconv.ParseFloat(it.ExecutedQty, 64)
+		status := banexg.OdStatusFilled
+		if filled < amount {
+			status = banexg.OdStatusPartFilled
+		}
+		res = append(res, &banexg.Liquidation{
+			ID:        strconv.FormatInt(it.OrderId, 10),
+			Symbol:    mapSymbol(it.Symbol),
+			Type:      banexg.LiqTypeLiquidation,
+			Side:      strings.ToLower(it.Side),
+			Price:     price,
+			Average:   average,
+			Amount:    amount,
+			Filled:    filled,
+			Status:    status,
+			Timestamp: it.UpdatedTime,
+			Info:      it,
+		})
+	}
+	return res, nil
+}
+
+/*
+FetchADLQuantile
+fetch ADL queue position of positions, all symbols if empty
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#position-adl-quantile-estimation-user_data
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#position-adl-quantile-estimation-user_data
+	:param str[] symbols: unified market symbols of linear/inverse
+	:param bool [params.portfolioMargin]: query portfolio margin account, default OptPortfolioMargin
+*/
+func (e *Binance) FetchADLQuantile(symbols []string, params map[string]interface{}) ([]*banexg.ADLQuantile, *errs.Error) {
+	args := utils.SafeParams(params)
+	marketType, _, err := e.LoadArgsMarketType(args, symbols...)
+	if err != nil {
+		return nil, err
+	}
+	portfolio := e.isPortfolioMargin(args)
+	var method string
+	if marketType == banexg.MarketLinear {
+		method = MethodFapiPrivateGetAdlQuantile
+		if portfolio {
+			method = MethodPapiGetUmAdlQuantile
+		}
+	} else if marketType == banexg.MarketInverse {
+		method = MethodDapiPrivateGetAdlQuantile
+		if portfolio {
+			method = MethodPapiGetCmAdlQuantile
+		}
+	} else {
+		return nil, errs.NewMsg(errs.CodeUnsupportMarket, "FetchADLQuantile support linear/inverse contracts only")
+	}
+	if len(symbols) == 1 {
+		marketId, err := e.GetMarketID(symbols[0])
+		if err != nil {
+			return nil, err
+		}
+		args["symbol"] = marketId
+	}
+	tryNum := e.GetRetryNum("FetchADLQuantile", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parseADLQuantiles(e, marketType, symbols, rsp.Content)
+}
+
+func parseADLQuantiles(e *Binance, marketType string, symbols []string, content string) ([]*banexg.ADLQuantile, *errs.Error) {
+	var data = make([]*AdlQuantile, 0)
+	if strings.HasPrefix(content, "{") {
+		// single symbol returns object 单个标的时返回对象
+		var item = AdlQuantile{}
+		err := utils.UnmarshalString(content, &item, utils.JsonNumDefault)
+		if err != nil {
+			return nil, errs.New(errs.CodeUnmarshalFail, err)
+		}
+		data = append(data, &item)
+	} else {
+		err := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+		if err != nil {
+			return nil, errs.New(errs.CodeUnmarshalFail, err)
+		}
+	}
+	var wanted map[string]bool
+	if len(symbols) > 0 {
+		wanted = make(map[string]bool, len(symbols))
+		for _, s := range symbols {
+			wanted[s] = true
+		}
+	}
+	var res = make([]*banexg.ADLQuantile, 0, len(data))
+	for _, it := range data {
+		symbol := e.SafeSymbol(it.Symbol, "", marketType)
+		if wanted != nil && !wanted[symbol] {
+			continue
+		}
+		res = append(res, &banexg.ADLQuantile{
+			Symbol: symbol,
+			Long:   it.AdlQuantile["LONG"],
+			Short:  it.AdlQuantile["SHORT"],
+			Both:   it.AdlQuantile["BOTH"],
+			Info:   it,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,104 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func TestFetchLiquidations(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://fapi.binance.com").Get("/fapi/v1/forceOrders").MatchParam("symbol", "BTCUSDT").
+		Reply(200).BodyString(`[{"orderId":6071832819,"symbol":"BTCUSDT","status":"FILLED","clientOrderId":"autoclose-1596107620040000020",
+"price":"10871.09","avgPrice":"10913.21000","origQty":"0.001","executedQty":"0.001","cumQuote":"10.91321","timeInForce":"IOC",
+"type":"LIMIT","reduceOnly":false,"closePosition":false,"side":"SELL","positionSide":"BOTH","stopPrice":"0","workingType":"CONTRACT_PRICE",
+"origType":"LIMIT","time":1596107620044,"updateTime":1596107620087},
+{"orderId":6072734303,"symbol":"BTCUSDT","status":"FILLED","clientOrderId":"adl_autoclose","price":"11023.14","avgPrice":"10979.82000",
+"origQty":"0.001","executedQty":"0.001","cumQuote":"10.97982","timeInForce":"GTC","type":"LIMIT","reduceOnly":false,"closePosition":false,
+"side":"BUY","positionSide":"SHORT","stopPrice":"0","workingType":"CONTRACT_PRICE","origType":"LIMIT","time":1596110725059,"updateTime":1596110725071}]`)
+	res, err := exg.FetchLiquidations("BTC/USDT:USDT", 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("expect 2 liquidations, got %d", len(res))
+	}
+	liq := res[0]
+	if liq.Type != banexg.LiqTypeLiquidation || liq.ID != "6071832819" || liq.Symbol != "BTC/USDT:USDT" || liq.Side != banexg.OdSideSell ||
+		liq.Average != 10913.21 || liq.Filled != 0.001 || liq.Timestamp != 1596107620044 {
+		t.Errorf("bad liquidation: %+v", liq)
+	}
+	if res[1].Type != banexg.LiqTypeADL {
+		t.Errorf("adl_autoclose should be ADL: %+v", res[1])
+	}
+}
+
+func TestParseMarginForceOrders(t *testing.T) {
+	content := `{"rows":[{"avgPrice":"0.00388359","executedQty":"31.39000000","orderId":180015097,"price":"0.00388110",
+"qty":"31.39000000","side":"SELL","symbol":"BNBBTC","timeInForce":"GTC","isIsolated":true,"updatedTime":1558941374745}],"total":1}`
+	mapSymbol := func(mid string) string { return mid }
+	res, err := parseMarginForceOrders(mapSymbol, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Status != banexg.OdStatusFilled || res[0].Amount != 31.39 || res[0].Timestamp != 1558941374745 {
+		t.Errorf("bad margin liquidation: %+v", res)
+	}
+}
+
+func TestParseADLQuantiles(t *testing.T) {
+	exg := getOfflineLinear(t)
+	content := `[{"symbol":"ETHUSDT","adlQuantile":{"LONG":3,"SHORT":3,"HEDGE":0}},{"symbol":"BTCUSDT","adlQuantile":{"LONG":1,"SHORT":2,"BOTH":0}}]`
+	res, err := parseADLQuantiles(exg, banexg.MarketLinear, []string{"BTC/USDT:USDT"}, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Symbol != "BTC/USDT:USDT" || res[0].Long != 1 || res[0].Short != 2 {
+		t.Errorf("bad adl quantile: %+v", res)
+	}
+	res, err = parseADLQuantiles(exg, banexg.MarketLinear, nil, `{"symbol":"BTCUSDT","adlQuantile":{"LONG":0,"SHORT":0,"BOTH":4}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Both != 4 {
+		t.Errorf("bad single adl quantile: %+v", res)
+	}
+}
+
+func TestHandleLiquidationEvents(t *testing.T) {
+	exg := getOfflineLinear(t)
+	client := &banexg.WsClient{Exg: exg.Exchange, AccName: exg.DefAccName, MarketType: banexg.MarketLinear}
+	liqOut := banexg.GetWsOutChan(exg.Exchange, client.Prefix(banexg.MarketLinear+"@forceOrder"),
+		func(cap int) chan *banexg.Liquidation { return make(chan *banexg.Liquidation, cap) }, nil)
+	exg.handleForceOrder(client, map[string]string{"e": "forceOrder", "E": "1568014460893",
+		"o": `{"s":"BTCUSDT","S":"SELL","o":"LIMIT","f":"IOC","q":"0.014","p":"9910","ap":"9910","X":"FILLED","l":"0.014","z":"0.014","T":1568014460893}`})
+	liq := <-liqOut
+	if liq.Symbol != "BTC/USDT:USDT" || liq.Side != banexg.OdSideSell || liq.Amount != 0.014 || liq.Status != banexg.OdStatusFilled {
+		t.Errorf("bad ws liquidation: %+v", liq)
+	}
+	// all-market stream is on another stream with its own chan
+	symHash, arrHash := banexg.MarketLinear+"@forceOrder", banexg.MarketLinear+"@forceOrder@arr"
+	if exg.Stream(banexg.MarketLinear, symHash) == exg.Stream(banexg.MarketLinear, arrHash) {
+		t.Error("all-market liquidations should use another stream")
+	}
+	arrClient := &banexg.WsClient{Exg: exg.Exchange, URL: "wss://fstream.binance.com/ws/1", MarketType: banexg.MarketLinear}
+	arrOut := banexg.GetWsOutChan(exg.Exchange, arrClient.Prefix(arrHash),
+		func(cap int) chan *banexg.Liquidation { return make(chan *banexg.Liquidation, cap) }, nil)
+	exg.handleForceOrder(arrClient, map[string]string{"e": "forceOrder", "E": "1568014460893",
+		"o": `{"s":"BTCUSDT","S":"BUY","o":"LIMIT","f":"IOC","q":"0.02","p":"9910","ap":"9910","X":"FILLED","l":"0.02","z":"0.02","T":1568014460893}`})
+	if len(arrOut) != 1 || len(liqOut) != 0 {
+		t.Errorf("all-market liquidation should only be sent to its chan, got %v %v", len(arrOut), len(liqOut))
+	}
+	callOut := banexg.GetWsOutChan(exg.Exchange, client.Prefix("marginCall"),
+		func(cap int) chan *banexg.MarginCall { return make(chan *banexg.MarginCall, cap) }, nil)
+	exg.handleMarginCall(client, map[string]string{"e": "MARGIN_CALL", "E": "1587727187525", "cw": "3.16812045",
+		"p": `[{"s":"BTCUSDT","ps":"LONG","pa":"1.327","mt":"CROSSED","iw":"0","mp":"187.17127","up":"-1.166074","mm":"1.614445"}]`})
+	call := <-callOut
+	if call.Symbol != "BTC/USDT:USDT" || call.PosSide != banexg.PosSideLong || call.MarginMode != banexg.MarginCross ||
+		call.CrossWallet != 3.16812045 || call.MaintMargin != 1.614445 || call.Timestamp != 1587727187525 {
+		t.Errorf("bad margin call: %+v", call)
+	}
+}
@@ -0,0 +1,255 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+	"strings"
+)
+
+/*
+loadMarginArgs
+pop params.symbol and set it to symbolKey as isolated margin symbol, return unified symbol, empty for cross margin
+取出params.symbol作为逐仓交易对设置到symbolKey，返回标准交易对，全仓时为空
+*/
+func (e *Binance) loadMarginArgs(params map[string]interface{}, symbolKey string) (map[string]interface{}, string, *errs.Error) {
+	args := utils.SafeParams(params)
+	symbol := utils.PopMapVal(args, banexg.ParamSymbol, "")
+	if symbol == "" {
+		return args, "", nil
+	}
+	_, err := e.LoadMarkets(false, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	// GetMarket returns contract market in contract mode, use spot market directly
+	market, ok := e.Markets[symbol]
+	if !ok || !market.Spot {
+		return nil, "", errs.NewMsg(errs.CodeNoMarketForPair, "no spot market for isolated margin: %s", symbol)
+	}
+	if symbolKey == "symbol" {
+		args["isIsolated"] = "TRUE"
+	}
+	args[symbolKey] = market.ID
+	return args, market.Symbol, nil
+}
+
+/*
+Borrow
+borrow currency in margin account
+
+	:see: https://developers.binance.com/docs/margin_trading/borrow-and-repay/Margin-Account-Borrow-Repay
+	:see: https://developers.binance.com/docs/derivatives/portfolio-margin/trade/Margin-Account-Borrow
+	:param str code: unified currency code
+	:param float amount: amount to borrow
+	:param str [params.symbol]: unified symbol for isolated margin, cross margin if empty
+	:param bool [params.portfolioMargin]: borrow in portfolio margin account, default OptPortfolioMargin
+*/
+func (e *Binance) Borrow(code string, amount float64, params map[string]interface{}) (*banexg.MarginLoan, *errs.Error) {
+	return e.marginLoan("Borrow", "BORROW", MethodPapiPostMarginLoan, code, amount, params)
+}
+
+/*
+Repay
+repay borrowed currency in margin account
+
+	:see: https://developers.binance.com/docs/margin_trading/borrow-and-repay/Margin-Account-Borrow-Repay
+	:see: https://developers.binance.com/docs/derivatives/portfolio-margin/trade/Margin-Account-Repay
+	:param str code: unified currency code
+	:param float amount: amount to repay
+	:param str [params.symbol]: unified symbol for isolated margin, cross margin if empty
+	:param bool [params.portfolioMargin]: repay in portfolio margin account, default OptPortfolioMargin
+*/
+func (e *Binance) Repay(code string, amount float64, params map[string]interface{}) (*banexg.MarginLoan, *errs.Error) {
+	return e.marginLoan("Repay", "REPAY", MethodPapiPostRepayLoan, code, amount, params)
+}
+
+// marginLoan 普通账户使用borrow-repay接口，统一账户使用papi的全仓杠杆借还款接口
+func (e *Binance) marginLoan(apiName, loanType, pmMethod, code string, amount float64, params map[string]interface{}) (*banexg.MarginLoan, *errs.Error) {
+	if code == "" || amount <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "code and positive amount are required for %s", apiName)
+	}
+	args, symbol, err := e.loadMarginArgs(params, "symbol")
+	if err != nil {
+		return nil, err
+	}
+	method := MethodSapiPostMarginBorrowRepay
+	if e.isPortfolioMargin(args) {
+		if symbol != "" {
+			return nil, errs.NewMsg(errs.CodeNotSupport, "portfolio margin not support isolated %s", apiName)
+		}
+		method = pmMethod
+	} else {
+		args["type"] = loanType
+		if symbol == "" {
+			args["isIsolated"] = "FALSE"
+		}
+	}
+	args["asset"] = code
+	args["amount"] = strconv.FormatFloat(amount, 'f', -1, 64)
+	tryNum := e.GetRetryNum(apiName, 0)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = MarginTranRsp{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	return &banexg.MarginLoan{
+		ID:        strconv.FormatInt(data.TranId, 10),
+		Code:      code,
+		Amount:    amount,
+		Symbol:    symbol,
+		Timestamp: e.MilliSeconds(),
+		Info:      data,
+	}, nil
+}
+
+/*
+FetchBorrowInterest
+fetch interest charged for borrowing in margin account
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#get-interest-history-user_data
+	:param str code: unified currency code, all if empty
+	:param int since: start time in ms
+	:param int limit: max 100
+	:param str [params.symbol]: unified symbol for isolated margin, cross margin if empty
+	:param int [params.until]: end time in ms
+*/
+func (e *Binance) FetchBorrowInterest(code string, since int64, limit int, params map[string]interface{}) ([]*banexg.BorrowInterest, *errs.Error) {
+	args, _, err := e.loadMarginArgs(params, "isolatedSymbol")
+	if err != nil {
+		return nil, err
+	}
+	if code != "" {
+		args["asset"] = code
+	}
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until > 0 {
+		args["endTime"] = until
+	}
+	if since > 0 {
+		args["startTime"] = since
+	}
+	if limit > 0 {
+		args["size"] = min(limit, 100)
+	}
+	tryNum := e.GetRetryNum("FetchBorrowInterest", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetMarginInterestHistory, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parseMarginInterests(e, rsp.Content)
+}
+
+func parseMarginInterests(e *Binance, content string) ([]*banexg.BorrowInterest, *errs.Error) {
+	var data = MarginInterestRsp{}
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.BorrowInterest, 0, len(data.Rows))
+	for _, it := range data.Rows {
+		symbol := ""
+		if it.IsolatedSymbol != "" {
+			symbol = it.IsolatedSymbol
+			if market := e.GetMarketById(it.IsolatedSymbol, banexg.MarketMargin); market != nil {
+				symbol = market.Symbol
+			}
+		}
+		interest, _ := strconv.ParseFloat(it.Interest, 64)
+		rate, _ := strconv.ParseFloat(it.InterestRate, 64)
+		principal, _ := strconv.ParseFloat(it.Principal, 64)
+		res = append(res, &banexg.BorrowInterest{
+			Symbol:    symbol,
+			Code:      e.SafeCurrencyCode(it.Asset),
+			Interest:  interest,
+			Rate:      rate,
+			Amount:    principal,
+			Type:      it.Type,
+			Timestamp: it.InterestAccuredTime,
+			Info:      it,
+		})
+	}
+	return res, nil
+}
+
+/*
+FetchMaxBorrowable
+return max amount of currency can be borrowed in margin account
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#query-max-borrow-user_data
+	:param str code: unified currency code
+	:param str [params.symbol]: unified symbol for isolated margin, cross margin if empty
+*/
+func (e *Binance) FetchMaxBorrowable(code string, params map[string]interface{}) (float64, *errs.Error) {
+	if code == "" {
+		return 0, errs.NewMsg(errs.CodeParamRequired, "code is required for FetchMaxBorrowable")
+	}
+	args, _, err := e.loadMarginArgs(params, "isolatedSymbol")
+	if err != nil {
+		return 0, err
+	}
+	args["asset"] = code
+	tryNum := e.GetRetryNum("FetchMaxBorrowable", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetMarginMaxBorrowable, args, tryNum)
+	if rsp.Error != nil {
+		return 0, rsp.Error
+	}
+	var data = MaxBorrowable{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return 0, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	amount, _ := strconv.ParseFloat(data.Amount, 64)
+	return amount, nil
+}
+
+/*
+FetchBorrowRates
+fetch next hourly borrow rates of currencies
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#get-future-hourly-interest-rate-user_data
+	:param []str codes: unified currency codes, max 20
+	:param str [params.marginMode]: 'isolated' for isolated margin rates, default cross
+*/
+func (e *Binance) FetchBorrowRates(codes []string, params map[string]interface{}) ([]*banexg.BorrowRate, *errs.Error) {
+	if len(codes) == 0 || len(codes) > 20 {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "FetchBorrowRates requires 1-20 codes")
+	}
+	args := utils.SafeParams(params)
+	isolated := utils.PopMapVal(args, banexg.ParamMarginMode, "") == banexg.MarginIsolated
+	args["assets"] = strings.Join(codes, ",")
+	args["isIsolated"] = strings.ToUpper(strconv.FormatBool(isolated))
+	tryNum := e.GetRetryNum("FetchBorrowRates", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetMarginNextHourlyInterestRate, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parseBorrowRates(e, rsp.Content)
+}
+
+func parseBorrowRates(e *Binance, content string) ([]*banexg.BorrowRate, *errs.Error) {
+	var data = make([]*NextHourlyRate, 0)
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	stamp := e.MilliSeconds()
+	var res = make([]*banexg.BorrowRate, 0, len(data))
+	for _, it := range data {
+		rate, _ := strconv.ParseFloat(it.NextHourlyInterestRate, 64)
+		res = append(res, &banexg.BorrowRate{
+			Code:      e.SafeCurrencyCode(it.Asset),
+			Rate:      rate,
+			Period:    3600000,
+			Timestamp: stamp,
+			Info:      it,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,85 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func TestMarginLoan(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	exg.Markets["BTC/USDT"] = &banexg.Market{ID: "BTCUSDT", Symbol: "BTC/USDT", Type: banexg.MarketSpot,
+		Spot: true, Margin: true, Base: "BTC", Quote: "USDT"}
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://api.binance.com").Post("/sapi/v1/margin/borrow-repay").
+		BodyString("type=BORROW").
+		Reply(200).JSON(map[string]interface{}{"tranId": 100000001})
+	gock.New("https://api.binance.com").Post("/sapi/v1/margin/borrow-repay").
+		BodyString("type=REPAY").
+		Reply(200).JSON(map[string]interface{}{"tranId": 100000002})
+	gock.New("https://api.binance.com").Get("/sapi/v1/margin/maxBorrowable").
+		MatchParam("asset", "USDT").
+		Reply(200).JSON(map[string]interface{}{"amount": "1.69248805", "borrowLimit": "60"})
+	res, err := exg.Borrow("USDT", 10, map[string]interface{}{banexg.ParamSymbol: "BTC/USDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != "100000001" || res.Symbol != "BTC/USDT" || res.Amount != 10 {
+		t.Errorf("bad loan: %+v", res)
+	}
+	res, err = exg.Repay("USDT", 10, nil)
+	if err != nil || res.ID != "100000002" || res.Symbol != "" {
+		t.Errorf("bad repay: %+v %v", res, err)
+	}
+	amount, err := exg.FetchMaxBorrowable("USDT", nil)
+	if err != nil || amount != 1.69248805 {
+		t.Errorf("bad max borrowable: %v %v", amount, err)
+	}
+	if !gock.IsDone() {
+		t.Error("pending mocks")
+	}
+}
+
+func TestParseMarginInterests(t *testing.T) {
+	exg := getOfflineLinear(t)
+	content := `{"rows":[{"txId":1352286576452864727,"interestAccuredTime":1672160400000,"asset":"USDT","rawAsset":"USDT",
+"principal":"45.3313","interest":"0.00024995","interestRate":"0.00013233","type":"ON_BORROW","isolatedSymbol":"BNBUSDT"}],"total":1}`
+	res, err := parseMarginInterests(exg, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Interest != 0.00024995 || res[0].Amount != 45.3313 || res[0].Symbol != "BNBUSDT" {
+		t.Fatalf("bad interests: %+v", res[0])
+	}
+	rates, err := parseBorrowRates(exg, `[{"asset":"BTC","nextHourlyInterestRate":"0.00000571"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 1 || rates[0].Code != "BTC" || rates[0].Rate != 0.00000571 || rates[0].Period != 3600000 {
+		t.Errorf("bad rates: %+v", rates)
+	}
+}
+
+func TestPortfolioMarginLoan(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflinePortfolio(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://papi.binance.com").Post("/papi/v1/marginLoan").BodyString("asset=USDT").
+		Reply(200).JSON(map[string]interface{}{"tranId": 100000003})
+	gock.New("https://papi.binance.com").Post("/papi/v1/repayLoan").BodyString("asset=USDT").
+		Reply(200).JSON(map[string]interface{}{"tranId": 100000004})
+	res, err := exg.Borrow("USDT", 10, nil)
+	if err != nil || res.ID != "100000003" {
+		t.Fatalf("bad portfolio borrow: %+v %v", res, err)
+	}
+	res, err = exg.Repay("USDT", 10, nil)
+	if err != nil || res.ID != "100000004" {
+		t.Fatalf("bad portfolio repay: %+v %v", res, err)
+	}
+	if !gock.IsDone() {
+		t.Error("pending mocks")
+	}
+}
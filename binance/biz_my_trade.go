@@ -0,0 +1,188 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+	"strings"
+)
+
+/*
+FetchMyTrades
+fetch all trades made by the user
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#account-trade-list-user_data
+	:see: https://binance-docs.github.io/apidocs/spot/en/#query-margin-account-39-s-trade-list-user_data
+	:see: https://binance-docs.github.io/apidocs/futures/en/#account-trade-list-user_data
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#account-trade-list-user_data
+	:see: https://binance-docs.github.io/apidocs/voptions/en/#account-trade-list-user_data
+	:param str symbol: unified market symbol, optional for option
+	:param int [since]: the earliest time in ms to fetch trades for
+	:param int [limit]: the maximum number of trades to retrieve
+	:param int [params.until]: the latest time in ms to fetch trades for
+	:param str [params.marginMode]: 'cross' or 'isolated', for spot margin trading
+	:returns Trade[]: a list of `trade structures <https://docs.ccxt.com/#/?id=trade-structure>`
+*/
+func (e *Binance) FetchMyTrades(symbol string, since int64, limit int, params map[string]interface{}) ([]*banexg.MyTrade, *errs.Error) {
+	var args map[string]interface{}
+	var marketType string
+	if symbol != "" {
+		argsIn, market, err := e.LoadArgsMarket(symbol, params)
+		if err != nil {
+			return nil, err
+		}
+		args = argsIn
+		args["symbol"] = market.ID
+		marketType = market.Type
+	} else {
+		args = utils.SafeParams(params)
+		var err *errs.Error
+		marketType, _, err = e.LoadArgsMarketType(args)
+		if err != nil {
+			return nil, err
+		}
+		if marketType != banexg.MarketOption {
+			return nil, errs.NewMsg(errs.CodeParamRequired, "FetchMyTrades requires a symbol for %s", marketType)
+		}
+	}
+	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
+	method := MethodPrivateGetMyTrades
+	if marketType == banexg.MarketOption {
+		method = MethodEapiPrivateGetUserTrades
+	} else if marketType == banexg.MarketLinear {
+		method = MethodFapiPrivateGetUserTrades
+	} else if marketType == banexg.MarketInverse {
+		method = MethodDapiPrivateGetUserTrades
+	} else if marketType == banexg.MarketMargin || marginMode != "" {
+		method = MethodSapiGetMarginMyTrades
+		if marginMode == banexg.MarginIsolated {
+			args["isIsolated"] = true
+		}
+	}
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until > 0 {
+		args["endTime"] = until
+	}
+	if since > 0 {
+		args["startTime"] = since
+	}
+	if limit > 0 {
+		args["limit"] = limit
+	}
+	tryNum := e.GetRetryNum("FetchMyTrades", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var mapSymbol = func(mid string) string {
+		return e.SafeSymbol(mid, "", marketType)
+	}
+	var res []*banexg.MyTrade
+	var err *errs.Error
+	switch method {
+	case MethodEapiPrivateGetUserTrades:
+		res, err = parseMyTrades[*OptionMyTrade](mapSymbol, rsp.Content)
+	case MethodFapiPrivateGetUserTrades, MethodDapiPrivateGetUserTrades:
+		res, err = parseMyTrades[*FutureMyTrade](mapSymbol, rsp.Content)
+	default:
+		res, err = parseMyTrades[*SpotMyTrade](mapSymbol, rsp.Content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, trade := range res {
+		trade.Fee.Currency = e.SafeCurrencyCode(trade.Fee.Currency)
+	}
+	return res, nil
+}
+
+func parseMyTrades[T IBnbMyTrade](mapSymbol func(string) string, content string) ([]*banexg.MyTrade, *errs.Error) {
+	var data = make([]T, 0)
+	err := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err)
+	}
+	var res = make([]*banexg.MyTrade, 0, len(data))
+	for _, it := range data {
+		res = append(res, it.ToStdTrade(mapSymbol))
+	}
+	return res, nil
+}
+
+func (t *SpotMyTrade) ToStdTrade(mapSymbol func(string) string) *banexg.MyTrade {
+	price, _ := strconv.ParseFloat(t.Price, 64)
+	amount, _ := strconv.ParseFloat(t.Qty, 64)
+	cost, _ := strconv.ParseFloat(t.QuoteQty, 64)
+	fee, _ := strconv.ParseFloat(t.Commission, 64)
+	side := banexg.OdSideSell
+	if t.IsBuyer {
+		side = banexg.OdSideBuy
+	}
+	return &banexg.MyTrade{
+		Trade: banexg.Trade{
+			ID:        strconv.FormatInt(t.Id, 10),
+			Symbol:    mapSymbol(t.Symbol),
+			Side:      side,
+			Amount:    amount,
+			Price:     price,
+			Cost:      cost,
+			Order:     strconv.FormatInt(t.OrderId, 10),
+			Timestamp: t.Time,
+			Maker:     t.IsMaker,
+			Fee:       &banexg.Fee{IsMaker: t.IsMaker, Currency: t.CommissionAsset, Cost: fee},
+		},
+		Info: t,
+	}
+}
+
+func (t *FutureMyTrade) ToStdTrade(mapSymbol func(string) string) *banexg.MyTrade {
+	price, _ := strconv.ParseFloat(t.Price, 64)
+	amount, _ := strconv.ParseFloat(t.Qty, 64)
+	costText := t.QuoteQty
+	if costText == "" {
+		costText = t.BaseQty
+	}
+	cost, _ := strconv.ParseFloat(costText, 64)
+	fee, _ := strconv.ParseFloat(t.Commission, 64)
+	return &banexg.MyTrade{
+		Trade: banexg.Trade{
+			ID:        strconv.FormatInt(t.Id, 10),
+			Symbol:    mapSymbol(t.Symbol),
+			Side:      strings.ToLower(t.Side),
+			Amount:    amount,
+			Price:     price,
+			Cost:      cost,
+			Order:     strconv.FormatInt(t.OrderId, 10),
+			Timestamp: t.Time,
+			Maker:     t.Maker,
+			Fee:       &banexg.Fee{IsMaker: t.Maker, Currency: t.CommissionAsset, Cost: fee},
+		},
+		PosSide: strings.ToLower(t.PositionSide),
+		Info:    t,
+	}
+}
+
+func (t *OptionMyTrade) ToStdTrade(mapSymbol func(string) string) *banexg.MyTrade {
+	price, _ := strconv.ParseFloat(t.Price, 64)
+	amount, _ := strconv.ParseFloat(t.Quantity, 64)
+	fee, _ := strconv.ParseFloat(t.Fee, 64)
+	isMaker := t.Liquidity == "MAKER"
+	return &banexg.MyTrade{
+		Trade: banexg.Trade{
+			ID:        strconv.FormatInt(t.TradeId, 10),
+			Symbol:    mapSymbol(t.Symbol),
+			Side:      strings.ToLower(t.Side),
+			Type:      strings.ToLower(t.Type),
+			Amount:    amount,
+			Price:     price,
+			Cost:      price * amount,
+			Order:     strconv.FormatInt(t.OrderId, 10),
+			Timestamp: t.Time,
+			Maker:     isMaker,
+			Fee:       &banexg.Fee{IsMaker: isMaker, Currency: t.QuoteAsset, Cost: fee},
+		},
+		Info: t,
+	}
+}
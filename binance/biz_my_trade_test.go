@@ -0,0 +1,55 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func TestFetchMyTrades(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://fapi.binance.com").Get("/fapi/v1/userTrades").MatchParam("symbol", "BTCUSDT").
+		Reply(200).BodyString(`[{"buyer":false,"commission":"-0.07819010","commissionAsset":"USDT","id":698759,"maker":false,
+"orderId":25851813,"price":"7819.01","qty":"0.002","quoteQty":"15.63802","realizedPnl":"-0.91539999","side":"SELL",
+"positionSide":"SHORT","symbol":"BTCUSDT","time":1569514978020}]`)
+	res, err := exg.FetchMyTrades("BTC/USDT:USDT", 0, 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("expect 1 trade, got %d", len(res))
+	}
+	td := res[0]
+	if td.Symbol != "BTC/USDT:USDT" || td.Side != banexg.OdSideSell || td.Order != "25851813" || td.PosSide != banexg.PosSideShort {
+		t.Errorf("bad trade: %+v", td)
+	}
+	if td.Fee == nil || td.Fee.Currency != "USDT" || td.Fee.Cost != -0.0781901 || td.Maker || td.Cost != 15.63802 {
+		t.Errorf("bad fee: %+v", td.Fee)
+	}
+}
+
+func TestParseMyTrades(t *testing.T) {
+	mapSymbol := func(id string) string { return id }
+	spot := `[{"symbol":"BNBBTC","id":28457,"orderId":100234,"price":"4.00000100","qty":"12.00000000",
+"quoteQty":"48.000012","commission":"10.10000000","commissionAsset":"BNB","time":1499865549590,"isBuyer":true,"isMaker":false}]`
+	res, err := parseMyTrades[*SpotMyTrade](mapSymbol, spot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Side != banexg.OdSideBuy || res[0].Amount != 12 || res[0].Fee.Currency != "BNB" {
+		t.Errorf("bad spot trade: %+v", res)
+	}
+	option := `[{"id":4611875134427365377,"tradeId":239,"orderId":4611875134427365377,"symbol":"BTC-200730-9000-C",
+"price":"100","quantity":"1","fee":"0.5","realizedProfit":"0.0","side":"BUY","type":"LIMIT","liquidity":"MAKER",
+"time":1592465880683,"quoteAsset":"USDT"}]`
+	res, err = parseMyTrades[*OptionMyTrade](mapSymbol, option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || !res[0].Maker || res[0].ID != "239" || res[0].Cost != 100 || res[0].Fee.Cost != 0.5 {
+		t.Errorf("bad option trade: %+v", res)
+	}
+}
@@ -4,19 +4,34 @@ import (
 	"context"
 	"github.com/banbox/banexg"
 	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
 	"github.com/banbox/banexg/utils"
+	"go.uber.org/zap"
 	"strconv"
 	"strings"
 )
 
+/*
+FetchOrder
+fetch an order by id or params.clientOrderId
+
+	:param bool [params.portfolioMargin]: query portfolio margin account, default OptPortfolioMargin
+	:param bool [params.conditional]: query conditional order of contracts in portfolio margin account
+*/
 func (e *Binance) FetchOrder(symbol, orderId string, params map[string]interface{}) (*banexg.Order, *errs.Error) {
 	args, market, err := e.LoadArgsMarket(symbol, params)
 	if err != nil {
 		return nil, err
 	}
 	args["symbol"] = market.ID
-	args["orderId"] = orderId
+	if orderId != "" {
+		args["orderId"] = orderId
+	}
+	portfolio := e.isPortfolioMargin(args)
 	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
+	if portfolio {
+		return e.fetchPmOrder(market, marginMode, args)
+	}
 	method := MethodPrivateGetOrder
 	if market.Option {
 		method = MethodEapiPrivateGetOrder
@@ -65,6 +80,8 @@ func (e *Binance) FetchOrder(symbol, orderId string, params map[string]interface
 /*
 FetchOrders 获取自己的订单
 symbol: 必填，币种
+params.portfolioMargin: 查询统一账户，默认OptPortfolioMargin
+params.conditional: 查询统一账户的合约条件单
 */
 func (e *Binance) FetchOrders(symbol string, since int64, limit int, params map[string]interface{}) ([]*banexg.Order, *errs.Error) {
 	args, market, err := e.LoadArgsMarket(symbol, params)
@@ -72,9 +89,15 @@ func (e *Binance) FetchOrders(symbol string, since int64, limit int, params map[
 		return nil, err
 	}
 	args["symbol"] = market.ID
+	portfolio := e.isPortfolioMargin(args)
 	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
 	method := MethodPrivateGetAllOrders
-	if market.Option {
+	if portfolio {
+		method, err = pmFetchOrders.pick("FetchOrders", market.Type, marginMode, args)
+		if err != nil {
+			return nil, err
+		}
+	} else if market.Option {
 		method = MethodEapiPrivateGetHistoryOrders
 	} else if market.Linear {
 		method = MethodFapiPrivateGetAllOrders
@@ -116,7 +139,7 @@ func (e *Binance) FetchOrders(symbol string, since int64, limit int, params map[
 	case MethodSapiGetMarginAllOrders:
 		return parseOrders[*MarginOrder](mapSymbol, rsp)
 	default:
-		return nil, errs.NewMsg(errs.CodeNotSupport, "not support order method %s", method)
+		return parsePmOrders(method, mapSymbol, rsp)
 	}
 }
 
@@ -138,6 +161,8 @@ fetch all unfilled currently open orders
 :param int [limit]: the maximum number of open orders structures to retrieve
 :param dict [params]: extra parameters specific to the exchange API endpoint
 :param str [params.marginMode]: 'cross' or 'isolated', for spot margin trading
+:param bool [params.portfolioMargin]: query portfolio margin account, default OptPortfolioMargin
+:param bool [params.conditional]: query conditional orders of contracts in portfolio margin account
 :returns Order[]: a list of `order structures <https://docs.ccxt.com/#/?id=order-structure>`
 */
 func (e *Binance) FetchOpenOrders(symbol string, since int64, limit int, params map[string]interface{}) ([]*banexg.Order, *errs.Error) {
@@ -155,9 +180,16 @@ func (e *Binance) FetchOpenOrders(symbol string, since int64, limit int, params
 		args = utils.SafeParams(params)
 		marketType, _ = e.GetArgsMarketType(args, "")
 	}
+	portfolio := e.isPortfolioMargin(args)
 	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
 	method := MethodPrivateGetOpenOrders
-	if marketType == banexg.MarketOption {
+	if portfolio {
+		var err *errs.Error
+		method, err = pmOpenOrders.pick("FetchOpenOrders", marketType, marginMode, args)
+		if err != nil {
+			return nil, err
+		}
+	} else if marketType == banexg.MarketOption {
 		method = MethodEapiPrivateGetOpenOrders
 		if since > 0 {
 			args["startTime"] = since
@@ -204,15 +236,38 @@ func (e *Binance) FetchOpenOrders(symbol string, since int64, limit int, params
 	case MethodSapiGetMarginOpenOrders:
 		return parseOrders[*MarginOrder](mapSymbol, rsp)
 	default:
-		return nil, errs.NewMsg(errs.CodeNotSupport, "not support order method %s", method)
+		return parsePmOrders(method, mapSymbol, rsp)
 	}
 }
 
 func (e *Binance) EditOrder(symbol, orderId, side string, amount, price float64, params map[string]interface{}) (*banexg.Order, *errs.Error) {
-	args, market, err := e.LoadArgsMarket(symbol, params)
+	args, market, method, err := e.makeEditOrderArgs(symbol, orderId, side, amount, price, params)
 	if err != nil {
 		return nil, err
 	}
+	tryNum := e.GetRetryNum("EditOrder", 1)
+	rsp := e.requestOrderApi(method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var mapSymbol = func(mid string) string {
+		return market.Symbol
+	}
+	if method == MethodFapiPrivatePutOrder || method == MethodPapiPutUmOrder {
+		return parseOrder[*FutureOrder](mapSymbol, rsp)
+	} else if method == MethodDapiPrivatePutOrder || method == MethodPapiPutCmOrder {
+		return parseOrder[*InverseOrder](mapSymbol, rsp)
+	} else {
+		return nil, errs.NewMsg(errs.CodeRunTime, "invalid method for EditOrder: %s", method)
+	}
+}
+
+func (e *Binance) makeEditOrderArgs(symbol, orderId, side string, amount, price float64, params map[string]interface{}) (map[string]interface{}, *banexg.Market, string, *errs.Error) {
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	portfolio := e.isPortfolioMargin(args)
 	clientOrderId := utils.PopMapVal(args, banexg.ParamClientOrderId, "")
 	args["symbol"] = market.ID
 	args["side"] = strings.ToUpper(side)
@@ -223,31 +278,21 @@ func (e *Binance) EditOrder(symbol, orderId, side string, amount, price float64,
 	} else {
 		args["orderId"] = orderId
 	}
+	if portfolio {
+		method, err := pmEditOrder.pick("EditOrder", market.Type, "", args)
+		return args, market, method, err
+	}
 	var method string
 	if market.Option {
-		return nil, errs.NewMsg(errs.CodeParamInvalid, "EditOrder not available in option market")
+		return nil, nil, "", errs.NewMsg(errs.CodeParamInvalid, "EditOrder not available in option market")
 	} else if market.Linear {
 		method = MethodFapiPrivatePutOrder
 	} else if market.Inverse {
 		method = MethodDapiPrivatePutOrder
 	} else {
-		return nil, errs.NewMsg(errs.CodeParamInvalid, "EditOrder not available in spot/margin market")
-	}
-	tryNum := e.GetRetryNum("EditOrder", 1)
-	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
-	if rsp.Error != nil {
-		return nil, rsp.Error
-	}
-	var mapSymbol = func(mid string) string {
-		return market.Symbol
-	}
-	if method == MethodFapiPrivatePutOrder {
-		return parseOrder[*FutureOrder](mapSymbol, rsp)
-	} else if method == MethodDapiPrivatePutOrder {
-		return parseOrder[*InverseOrder](mapSymbol, rsp)
-	} else {
-		return nil, errs.NewMsg(errs.CodeRunTime, "invalid method for EditOrder: %s", method)
+		return nil, nil, "", errs.NewMsg(errs.CodeParamInvalid, "EditOrder not available in spot/margin market")
 	}
+	return args, market, method, nil
 }
 
 /*
@@ -262,13 +307,44 @@ cancels an open order
 	:param str id: order id
 	:param str symbol: unified symbol of the market the order was made in
 	:param dict [params]: extra parameters specific to the exchange API endpoint
+	:param boolean [params.wsApi]: send over websocket api, fallback to rest when unavailable, default OptWsApi
+	:param boolean [params.portfolioMargin]: cancel order of portfolio margin account, default OptPortfolioMargin
+	:param boolean [params.conditional]: cancel conditional order of contracts in portfolio margin account
 	:returns dict: An `order structure <https://docs.ccxt.com/#/?id=order-structure>`
 */
 func (e *Binance) CancelOrder(id string, symbol string, params map[string]interface{}) (*banexg.Order, *errs.Error) {
-	args, market, err := e.LoadArgsMarket(symbol, params)
+	args, market, method, err := e.makeCancelOrderArgs(id, symbol, params)
 	if err != nil {
 		return nil, err
 	}
+	tryNum := e.GetRetryNum("CancelOrder", 1)
+	rsp := e.requestOrderApi(method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var mapSymbol = func(mid string) string {
+		return market.Symbol
+	}
+	if pmOrderKind(method) != "" {
+		return parsePmOrder(method, mapSymbol, rsp)
+	} else if method == MethodFapiPrivateDeleteOrder {
+		return parseOrder[*FutureOrder](mapSymbol, rsp)
+	} else if method == MethodDapiPrivateDeleteOrder {
+		return parseOrder[*InverseOrder](mapSymbol, rsp)
+	} else if method == MethodEapiPrivateDeleteOrder {
+		return parseOrder[*OptionOrder](mapSymbol, rsp)
+	} else {
+		// spot margin sor
+		return parseOrder[*SpotOrder](mapSymbol, rsp)
+	}
+}
+
+func (e *Binance) makeCancelOrderArgs(id string, symbol string, params map[string]interface{}) (map[string]interface{}, *banexg.Market, string, *errs.Error) {
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	portfolio := e.isPortfolioMargin(args)
 	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
 	clientOrderId := utils.PopMapVal(args, banexg.ParamClientOrderId, "")
 	args["symbol"] = market.ID
@@ -281,6 +357,10 @@ func (e *Binance) CancelOrder(id string, symbol string, params map[string]interf
 	} else {
 		args["orderId"] = id
 	}
+	if portfolio {
+		method, err := pmCancelOrder.pick("CancelOrder", market.Type, marginMode, args)
+		return args, market, method, err
+	}
 	method := MethodPrivateDeleteOrder
 	if market.Option {
 		method = MethodEapiPrivateDeleteOrder
@@ -294,24 +374,7 @@ func (e *Binance) CancelOrder(id string, symbol string, params map[string]interf
 			args["isIsolated"] = true
 		}
 	}
-	tryNum := e.GetRetryNum("CancelOrder", 1)
-	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
-	if rsp.Error != nil {
-		return nil, rsp.Error
-	}
-	var mapSymbol = func(mid string) string {
-		return market.Symbol
-	}
-	if method == MethodFapiPrivateDeleteOrder {
-		return parseOrder[*FutureOrder](mapSymbol, rsp)
-	} else if method == MethodDapiPrivateDeleteOrder {
-		return parseOrder[*InverseOrder](mapSymbol, rsp)
-	} else if method == MethodEapiPrivateDeleteOrder {
-		return parseOrder[*OptionOrder](mapSymbol, rsp)
-	} else {
-		// spot margin sor
-		return parseOrder[*SpotOrder](mapSymbol, rsp)
-	}
+	return args, market, method, nil
 }
 
 func parseOrders[T IBnbOrder](mapSymbol func(string) string, rsp *banexg.HttpRes) ([]*banexg.Order, *errs.Error) {
@@ -535,3 +598,123 @@ func calcFills(fills []*SpotFill) (float64, float64, float64, string) {
 	}
 	return average, totAmt, totFee, feeCurr
 }
+
+/*
+CancelAllOrders
+cancel all open orders of symbol; when symbol is empty, open orders of market type are fetched and canceled by symbol
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#cancel-all-open-orders-on-a-symbol-trade
+	:see: https://binance-docs.github.io/apidocs/futures/en/#cancel-all-open-orders-trade
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#cancel-all-open-orders-trade
+	:see: https://binance-docs.github.io/apidocs/voptions/en/#cancel-all-option-orders-on-specific-symbol-trade
+	:see: https://binance-docs.github.io/apidocs/spot/en/#margin-account-cancel-all-open-orders-on-a-symbol-trade
+	:param bool [params.portfolioMargin]: cancel orders of portfolio margin account, default OptPortfolioMargin
+	:param bool [params.conditional]: cancel conditional orders of contracts in portfolio margin account
+	:returns: canceled orders; linear/inverse/option only return a message, so open orders fetched before canceling are returned as canceled
+*/
+func (e *Binance) CancelAllOrders(symbol string, params map[string]interface{}) ([]*banexg.Order, *errs.Error) {
+	if symbol == "" {
+		ods, err := e.FetchOpenOrders("", 0, 0, params)
+		if err != nil {
+			return nil, err
+		}
+		var result []*banexg.Order
+		symbols := make(map[string]bool)
+		for _, od := range ods {
+			if symbols[od.Symbol] {
+				continue
+			}
+			symbols[od.Symbol] = true
+			res, err := e.CancelAllOrders(od.Symbol, params)
+			if err != nil {
+				return result, err
+			}
+			result = append(result, res...)
+		}
+		return result, nil
+	}
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return nil, err
+	}
+	portfolio := e.isPortfolioMargin(args)
+	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
+	args["symbol"] = market.ID
+	method := MethodPrivateDeleteOpenOrders
+	if portfolio {
+		method, err = pmCancelAll.pick("CancelAllOrders", market.Type, marginMode, args)
+		if err != nil {
+			return nil, err
+		}
+	} else if market.Option {
+		method = MethodEapiPrivateDeleteAllOpenOrders
+	} else if market.Linear {
+		method = MethodFapiPrivateDeleteAllOpenOrders
+	} else if market.Inverse {
+		method = MethodDapiPrivateDeleteAllOpenOrders
+	} else if market.Type == banexg.MarketMargin || marginMode != "" {
+		method = MethodSapiDeleteMarginOpenOrders
+		if marginMode == banexg.MarginIsolated {
+			args["isIsolated"] = true
+		}
+	}
+	// 合约和期权仅返回{"code":200,"msg":"..."}，撤单前获取挂单作为结果
+	var opens []*banexg.Order
+	returnOrders := method == MethodPrivateDeleteOpenOrders || method == MethodSapiDeleteMarginOpenOrders ||
+		method == MethodPapiDeleteMarginAllOpenOrders
+	if !returnOrders {
+		opens, err = e.FetchOpenOrders(symbol, 0, 0, params)
+		if err != nil {
+			// 获取失败时仍需撤单
+			log.Warn("fetch open orders before cancel all fail", zap.String("symbol", symbol), zap.Error(err))
+		}
+	}
+	tryNum := e.GetRetryNum("CancelAllOrders", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var mapSymbol = func(mid string) string {
+		return market.Symbol
+	}
+	if method == MethodPapiDeleteMarginAllOpenOrders {
+		return parsePmOrders(method, mapSymbol, rsp)
+	} else if !returnOrders {
+		for _, od := range opens {
+			od.Status = banexg.OdStatusCanceled
+		}
+		return opens, nil
+	}
+	if method == MethodSapiDeleteMarginOpenOrders {
+		return parseOrders[*MarginOrder](mapSymbol, rsp)
+	}
+	return parseOrders[*SpotOrder](mapSymbol, rsp)
+}
+
+/*
+SetCancelAllCountdown
+cancel all open orders of symbol when countdown(milliseconds) ends, should be called repeatedly as heartbeat.
+countdown=0 to disable. Only linear/inverse are supported.
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#auto-cancel-all-open-orders-trade
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#auto-cancel-all-open-orders-trade
+*/
+func (e *Binance) SetCancelAllCountdown(symbol string, countdown int64, params map[string]interface{}) *errs.Error {
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return err
+	}
+	var method string
+	if market.Linear {
+		method = MethodFapiPrivatePostCountdownCancelAll
+	} else if market.Inverse {
+		method = MethodDapiPrivatePostCountdownCancelAll
+	} else {
+		return errs.NewMsg(errs.CodeNotSupport, "SetCancelAllCountdown not support %s market", market.Type)
+	}
+	args["symbol"] = market.ID
+	args["countdownTime"] = countdown
+	tryNum := e.GetRetryNum("SetCancelAllCountdown", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	return rsp.Error
+}
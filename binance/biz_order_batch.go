@@ -0,0 +1,265 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strings"
+)
+
+// single order method: batch order method
+var batchOdMethods = map[string]string{
+	MethodFapiPrivatePostOrder:   MethodFapiPrivatePostBatchOrders,
+	MethodDapiPrivatePostOrder:   MethodDapiPrivatePostBatchOrders,
+	MethodEapiPrivatePostOrder:   MethodEapiPrivatePostBatchOrders,
+	MethodFapiPrivatePutOrder:    MethodFapiPrivatePutBatchOrders,
+	MethodDapiPrivatePutOrder:    MethodDapiPrivatePutBatchOrders,
+	MethodFapiPrivateDeleteOrder: MethodFapiPrivateDeleteBatchOrders,
+	MethodDapiPrivateDeleteOrder: MethodDapiPrivateDeleteBatchOrders,
+	MethodEapiPrivateDeleteOrder: MethodEapiPrivateDeleteBatchOrders,
+}
+
+// max orders per batch request 每次批量请求的最大订单数
+var batchOdLimits = map[string]int{
+	MethodFapiPrivatePostBatchOrders:   5,
+	MethodDapiPrivatePostBatchOrders:   5,
+	MethodEapiPrivatePostBatchOrders:   10,
+	MethodFapiPrivatePutBatchOrders:    5,
+	MethodDapiPrivatePutBatchOrders:    5,
+	MethodFapiPrivateDeleteBatchOrders: 10,
+	MethodDapiPrivateDeleteBatchOrders: 10,
+	MethodEapiPrivateDeleteBatchOrders: 10,
+}
+
+type batchOdGroup struct {
+	method  string
+	account string
+	symbol  string // only for cancel
+	idKey   string // only for cancel: orderId/origClientOrderId/clientOrderId
+	idxs    []int
+	items   []map[string]interface{}
+	symbols []string
+}
+
+/*
+CreateOrders
+create orders in batch. linear/inverse/option use batchOrders api, spot/margin and portfolio margin are emulated
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#place-multiple-orders-trade
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#place-multiple-orders-trade
+	:see: https://binance-docs.github.io/apidocs/voptions/en/#place-multiple-orders-trade
+*/
+func (e *Binance) CreateOrders(reqs []*banexg.OrderReq, params map[string]interface{}) ([]*banexg.OrderRes, *errs.Error) {
+	return e.batchOrders(reqs, params, func(req *banexg.OrderReq, args map[string]interface{}) (map[string]interface{}, *banexg.Market, string, *errs.Error) {
+		return e.makeCreateOrderArgs(req.Symbol, req.Type, req.Side, req.Amount, req.Price, args)
+	}, func(req *banexg.OrderReq, args map[string]interface{}) (*banexg.Order, *errs.Error) {
+		return e.CreateOrder(req.Symbol, req.Type, req.Side, req.Amount, req.Price, args)
+	})
+}
+
+/*
+EditOrders
+edit orders in batch, only linear/inverse are supported, not for portfolio margin
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#modify-multiple-orders-trade
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#modify-multiple-orders-trade
+*/
+func (e *Binance) EditOrders(reqs []*banexg.OrderReq, params map[string]interface{}) ([]*banexg.OrderRes, *errs.Error) {
+	return e.batchOrders(reqs, params, func(req *banexg.OrderReq, args map[string]interface{}) (map[string]interface{}, *banexg.Market, string, *errs.Error) {
+		return e.makeEditOrderArgs(req.Symbol, req.ID, req.Side, req.Amount, req.Price, args)
+	}, nil)
+}
+
+/*
+CancelOrders
+cancel orders in batch. linear/inverse/option use batchOrders api, spot/margin and portfolio margin are emulated
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#cancel-multiple-orders-trade
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#cancel-multiple-orders-trade
+	:see: https://binance-docs.github.io/apidocs/voptions/en/#cancel-multiple-option-orders-trade
+*/
+func (e *Binance) CancelOrders(reqs []*banexg.OrderReq, params map[string]interface{}) ([]*banexg.OrderRes, *errs.Error) {
+	return e.batchOrders(reqs, params, func(req *banexg.OrderReq, args map[string]interface{}) (map[string]interface{}, *banexg.Market, string, *errs.Error) {
+		return e.makeCancelOrderArgs(req.ID, req.Symbol, args)
+	}, func(req *banexg.OrderReq, args map[string]interface{}) (*banexg.Order, *errs.Error) {
+		return e.CancelOrder(req.ID, req.Symbol, args)
+	})
+}
+
+/*
+batchOrders
+group requests by batch method and account, request batch api for each group;
+requests without batch api are emulated by single calls
+按批量接口和账户分组请求；没有批量接口的请求通过单个调用模拟
+*/
+func (e *Binance) batchOrders(reqs []*banexg.OrderReq, params map[string]interface{},
+	makeArgs func(req *banexg.OrderReq, args map[string]interface{}) (map[string]interface{}, *banexg.Market, string, *errs.Error),
+	single func(req *banexg.OrderReq, args map[string]interface{}) (*banexg.Order, *errs.Error)) ([]*banexg.OrderRes, *errs.Error) {
+	res := make([]*banexg.OrderRes, len(reqs))
+	groups := make(map[string]*batchOdGroup)
+	groupKeys := make([]string, 0, 4)
+	var singleIdxs []int
+	for i, req := range reqs {
+		args, market, method, err := makeArgs(req, banexg.MergeOrderParams(params, req))
+		if err != nil {
+			res[i] = &banexg.OrderRes{Err: err}
+			continue
+		}
+		batchMethod, ok := batchOdMethods[method]
+		if !ok {
+			if single == nil {
+				res[i] = &banexg.OrderRes{Err: errs.NewMsg(errs.CodeNotSupport, "batch not support for %s", method)}
+			} else {
+				singleIdxs = append(singleIdxs, i)
+			}
+			continue
+		}
+		account := utils.PopMapVal(args, banexg.ParamAccount, "")
+		key := batchMethod + "@" + account
+		var idKey string
+		if strings.Contains(batchMethod, "Delete") {
+			// 批量撤单需同一标的，使用相同ID类型
+			idKey = "orderId"
+			for _, k := range []string{"origClientOrderId", "clientOrderId"} {
+				if _, ok = args[k]; ok {
+					idKey = k
+				}
+			}
+			key += "@" + market.ID + "@" + idKey
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &batchOdGroup{method: batchMethod, account: account, symbol: market.ID, idKey: idKey}
+			groups[key] = group
+			groupKeys = append(groupKeys, key)
+		}
+		group.idxs = append(group.idxs, i)
+		group.items = append(group.items, args)
+		group.symbols = append(group.symbols, market.Symbol)
+	}
+	if len(singleIdxs) > 0 {
+		subReqs := make([]*banexg.OrderReq, len(singleIdxs))
+		for i, idx := range singleIdxs {
+			subReqs[i] = reqs[idx]
+		}
+		subRes := banexg.EmulateBatch(subReqs, params, single)
+		for i, idx := range singleIdxs {
+			res[idx] = subRes[i]
+		}
+	}
+	for _, key := range groupKeys {
+		group := groups[key]
+		limit := batchOdLimits[group.method]
+		for start := 0; start < len(group.idxs); start += limit {
+			stop := min(start+limit, len(group.idxs))
+			items := e.requestBatchOrders(group, start, stop)
+			for i, item := range items {
+				res[group.idxs[start+i]] = item
+			}
+		}
+	}
+	return res, nil
+}
+
+func (e *Binance) requestBatchOrders(group *batchOdGroup, start, stop int) []*banexg.OrderRes {
+	num := stop - start
+	args := make(map[string]interface{})
+	if group.account != "" {
+		args[banexg.ParamAccount] = group.account
+	}
+	if group.idKey != "" {
+		ids := make([]string, 0, num)
+		for _, item := range group.items[start:stop] {
+			ids = append(ids, utils.GetMapVal(item, group.idKey, ""))
+		}
+		args["symbol"] = group.symbol
+		if group.method == MethodEapiPrivateDeleteBatchOrders {
+			if group.idKey == "orderId" {
+				args["orderIds"] = "[" + strings.Join(ids, ",") + "]"
+			} else {
+				args["clientOrderIds"] = marshalText(ids)
+			}
+		} else if group.idKey == "orderId" {
+			args["orderIdList"] = "[" + strings.Join(ids, ",") + "]"
+		} else {
+			args["origClientOrderIdList"] = marshalText(ids)
+		}
+	} else {
+		items := make([]map[string]string, 0, num)
+		for _, item := range group.items[start:stop] {
+			items = append(items, utils.MapValStr(item))
+		}
+		args["batchOrders"] = marshalText(items)
+	}
+	tryNum := e.GetRetryNum("BatchOrders", 1)
+	rsp := e.RequestApiRetry(context.Background(), group.method, args, tryNum)
+	res := make([]*banexg.OrderRes, num)
+	var items []*banexg.OrderRes
+	var err *errs.Error
+	if rsp.Error != nil {
+		err = rsp.Error
+	} else {
+		items, err = parseBatchOrders(group.method, group.symbols[start:stop], rsp.Content)
+	}
+	for i := range res {
+		if i < len(items) {
+			res[i] = items[i]
+		} else if err != nil {
+			res[i] = &banexg.OrderRes{Err: err}
+		} else {
+			res[i] = &banexg.OrderRes{Err: errs.NewMsg(errs.CodeInvalidResponse, "missing batch result")}
+		}
+	}
+	return res
+}
+
+/*
+parseBatchOrders
+items of batch response are orders or errors like {"code":-2011,"msg":"Unknown order sent."}
+批量接口返回的每一项为订单或错误
+*/
+func parseBatchOrders(method string, symbols []string, content string) ([]*banexg.OrderRes, *errs.Error) {
+	var items = make([]map[string]interface{}, 0)
+	err_ := utils.UnmarshalString(content, &items, utils.JsonNumAuto)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	res := make([]*banexg.OrderRes, len(items))
+	for i, item := range items {
+		if _, ok := item["symbol"]; !ok {
+			msg, _ := utils.MarshalString(item)
+			err := errs.NewMsg(400, msg)
+			code, _ := utils.SafeMapVal(utils.MapValStr(item), "code", int64(0))
+			err.BizCode = int(code)
+			res[i] = &banexg.OrderRes{Err: err}
+			continue
+		}
+		var symbol string
+		if i < len(symbols) {
+			symbol = symbols[i]
+		}
+		var mapSymbol = func(mid string) string {
+			return symbol
+		}
+		text, _ := utils.MarshalString(item)
+		rsp := &banexg.HttpRes{Content: text}
+		var od *banexg.Order
+		var err *errs.Error
+		switch method {
+		case MethodFapiPrivatePostBatchOrders, MethodFapiPrivatePutBatchOrders, MethodFapiPrivateDeleteBatchOrders:
+			od, err = parseOrder[*FutureOrder](mapSymbol, rsp)
+		case MethodDapiPrivatePostBatchOrders, MethodDapiPrivatePutBatchOrders, MethodDapiPrivateDeleteBatchOrders:
+			od, err = parseOrder[*InverseOrder](mapSymbol, rsp)
+		default:
+			od, err = parseOrder[*OptionOrder](mapSymbol, rsp)
+		}
+		res[i] = &banexg.OrderRes{Order: od, Err: err}
+	}
+	return res, nil
+}
+
+func marshalText(v any) string {
+	text, _ := utils.MarshalString(v)
+	return text
+}
@@ -1,7 +1,6 @@
 package binance
 
 import (
-	"context"
 	"github.com/banbox/banexg"
 	"github.com/banbox/banexg/errs"
 	"github.com/banbox/banexg/utils"
@@ -35,23 +34,86 @@ CreateOrder 提交订单到交易所
 	:param str [params.marginMode]: 'cross' or 'isolated', for spot margin trading
 	:param boolean [params.sor]: *spot only* whether to use SOR(Smart Order Routing) or not, default is False
 	:param boolean [params.test]: *spot only* whether to use the test endpoint or not, default is False
+	:param boolean [params.validate]: call ValidateOrder before sending, rounded amount/price are used with params.autoRound
+	:param boolean [params.wsApi]: send over websocket api, fallback to rest when unavailable, default OptWsApi
+	:param boolean [params.portfolioMargin]: send to portfolio margin api, default OptPortfolioMargin
 	:returns dict: an `order structure <https://docs.ccxt.com/#/?id=order-structure>`
 */
 func (e *Binance) CreateOrder(symbol, odType, side string, amount float64, price float64, params map[string]interface{}) (*banexg.Order, *errs.Error) {
-	args, market, err := e.LoadArgsMarket(symbol, params)
+	if utils.GetMapVal(params, banexg.ParamValidate, false) {
+		check, err := e.ValidateOrder(symbol, odType, side, amount, price, params)
+		if err != nil {
+			return nil, err
+		}
+		if err = check.Error(); err != nil {
+			return nil, err
+		}
+		amount, price = check.Amount, check.Price
+	}
+	args, market, method, err := e.makeCreateOrderArgs(symbol, odType, side, amount, price, params)
 	if err != nil {
 		return nil, err
 	}
+	tryNum := e.GetRetryNum("CreateOrder", 1)
+	rsp := e.requestOrderApi(method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var mapSymbol = func(mid string) string {
+		return market.Symbol
+	}
+	if method == MethodFapiPrivatePostOrder || method == MethodPapiPostUmOrder {
+		return parseOrder[*FutureOrder](mapSymbol, rsp)
+	} else if method == MethodDapiPrivatePostOrder || method == MethodPapiPostCmOrder {
+		return parseOrder[*InverseOrder](mapSymbol, rsp)
+	} else if method == MethodPapiPostUmConditionalOrder || method == MethodPapiPostCmConditionalOrder {
+		return parseOrder[*PmConditionalOrder](mapSymbol, rsp)
+	} else if method == MethodEapiPrivatePostOrder {
+		return parseOrder[*OptionOrder](mapSymbol, rsp)
+	} else {
+		// spot margin sor
+		return parseOrder[*SpotOrder](mapSymbol, rsp)
+	}
+}
+
+/*
+ValidateOrder
+check order against market precision, limits, leverage brackets and balance
+
+	:see: banexg.CheckOrder
+*/
+func (e *Binance) ValidateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*banexg.OrderCheck, *errs.Error) {
+	return banexg.CheckOrder(e, symbol, odType, side, amount, price, params)
+}
+
+/*
+makeCreateOrderArgs
+build request args and method for CreateOrder, shared by CreateOrders
+构建CreateOrder的请求参数和方法，CreateOrders复用
+*/
+func (e *Binance) makeCreateOrderArgs(symbol, odType, side string, amount float64, price float64, params map[string]interface{}) (map[string]interface{}, *banexg.Market, string, *errs.Error) {
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	accName := e.GetAccName(args)
+	portfolio := e.isPortfolioMargin(args)
 	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
+	if marginMode == "" && market.Type == banexg.MarketMargin {
+		marginMode = e.GetAccMarginMode(market.Symbol, accName)
+	}
+	for _, key := range []string{banexg.ParamValidate, banexg.ParamAutoRound, banexg.ParamCheckBalance, banexg.ParamLeverage} {
+		delete(args, key)
+	}
 	sor := utils.PopMapVal(args, banexg.ParamSor, false)
 	clientOrderId := utils.PopMapVal(args, banexg.ParamClientOrderId, "")
 	postOnly := utils.PopMapVal(args, banexg.ParamPostOnly, false)
 	timeInForce := utils.GetMapVal(args, banexg.ParamTimeInForce, "")
 	if postOnly || timeInForce == banexg.TimeInForcePO || odType == banexg.OdTypeLimitMaker {
 		if timeInForce == banexg.TimeInForceIOC || timeInForce == banexg.TimeInForceFOK {
-			return nil, errs.NewMsg(errs.CodeParamInvalid, "postOnly orders cannot have timeInForce: %s", timeInForce)
+			return nil, nil, "", errs.NewMsg(errs.CodeParamInvalid, "postOnly orders cannot have timeInForce: %s", timeInForce)
 		} else if odType == banexg.OdTypeMarket {
-			return nil, errs.NewMsg(errs.CodeParamInvalid, "market orders cannot be postOnly")
+			return nil, nil, "", errs.NewMsg(errs.CodeParamInvalid, "market orders cannot be postOnly")
 		}
 		postOnly = true
 	}
@@ -76,6 +138,17 @@ func (e *Binance) CreateOrder(symbol, odType, side string, amount float64, price
 		if reduceOnly {
 			args["sideEffectType"] = "AUTO_REPAY"
 		}
+	} else if (market.Linear || market.Inverse) && e.IsHedgeMode(accName, market.Type) {
+		// reduceOnly is not accepted in hedge mode, use positionSide instead
+		reduceOnly := utils.PopMapVal(args, banexg.ParamReduceOnly, false)
+		posSide := utils.GetMapVal(args, banexg.ParamPositionSide, "")
+		if posSide == "" {
+			posSide = banexg.PosSideLong
+			if (side == banexg.OdSideBuy) == reduceOnly {
+				posSide = banexg.PosSideShort
+			}
+		}
+		args[banexg.ParamPositionSide] = strings.ToUpper(posSide)
 	}
 	stopPrice := float64(0)
 	if isStopLoss {
@@ -127,10 +200,10 @@ func (e *Binance) CreateOrder(symbol, odType, side string, amount float64, price
 	exgOdType := strings.ToUpper(odType)
 	if market.Option {
 		if odType == banexg.OdTypeMarket {
-			return nil, errs.NewMsg(errs.CodeParamInvalid, "market order is invalid for option")
+			return nil, nil, "", errs.NewMsg(errs.CodeParamInvalid, "market order is invalid for option")
 		}
 	} else if !isBnbOrderType(market, exgOdType) {
-		return nil, errs.NewMsg(errs.CodeParamInvalid, "invalid order type %s for %s market", exgOdType, market.Type)
+		return nil, nil, "", errs.NewMsg(errs.CodeParamInvalid, "invalid order type %s for %s market", exgOdType, market.Type)
 	}
 	args["type"] = exgOdType
 	timeInForceRequired, priceRequired, stopPriceRequired, quantityRequired := false, false, false, false
@@ -164,7 +237,7 @@ func (e *Binance) CreateOrder(symbol, odType, side string, amount float64, price
 			if cost != 0 {
 				precRes, err := e.PrecCost(market, cost)
 				if err != nil {
-					return nil, err
+					return nil, nil, "", err
 				}
 				args["quoteOrderQty"] = precRes
 				quantityRequired = false
@@ -202,23 +275,23 @@ func (e *Binance) CreateOrder(symbol, odType, side string, amount float64, price
 		quantityRequired = true
 		callBackRate := utils.GetMapVal(args, banexg.ParamCallbackRate, 0.0)
 		if callBackRate == 0 {
-			return nil, errs.NewMsg(errs.CodeParamRequired, "createOrder require callbackRate for %s order", odType)
+			return nil, nil, "", errs.NewMsg(errs.CodeParamRequired, "createOrder require callbackRate for %s order", odType)
 		}
 	}
 	if quantityRequired {
 		amtStr, err := e.PrecAmount(market, amount)
 		if err != nil {
-			return nil, err
+			return nil, nil, "", err
 		}
 		args["quantity"] = amtStr
 	}
 	if priceRequired {
 		if price == 0 {
-			return nil, errs.NewMsg(errs.CodeParamRequired, "createOrder require price for %s order", odType)
+			return nil, nil, "", errs.NewMsg(errs.CodeParamRequired, "createOrder require price for %s order", odType)
 		}
 		priceStr, err := e.PrecPrice(market, price)
 		if err != nil {
-			return nil, err
+			return nil, nil, "", err
 		}
 		args["price"] = priceStr
 	}
@@ -234,15 +307,15 @@ func (e *Binance) CreateOrder(symbol, odType, side string, amount float64, price
 	if stopPriceRequired {
 		if market.Contract {
 			if stopPrice == 0 {
-				return nil, errs.NewMsg(errs.CodeParamRequired, "createOrder require stopPrice for %s order", odType)
+				return nil, nil, "", errs.NewMsg(errs.CodeParamRequired, "createOrder require stopPrice for %s order", odType)
 			}
 		} else if trailingDelta == 0 && stopPrice == 0 {
-			return nil, errs.NewMsg(errs.CodeParamRequired, "createOrder require stopPrice/trailingDelta for %s order", odType)
+			return nil, nil, "", errs.NewMsg(errs.CodeParamRequired, "createOrder require stopPrice/trailingDelta for %s order", odType)
 		}
 		if stopPrice != 0 {
 			stopPriceStr, err := e.PrecPrice(market, stopPrice)
 			if err != nil {
-				return nil, err
+				return nil, nil, "", err
 			}
 			args["stopPrice"] = stopPriceStr
 		}
@@ -250,6 +323,13 @@ func (e *Binance) CreateOrder(symbol, odType, side string, amount float64, price
 	if timeInForce == banexg.TimeInForcePO {
 		delete(args, banexg.ParamTimeInForce)
 	}
+	if portfolio {
+		if sor {
+			return nil, nil, "", errs.NewMsg(errs.CodeNotSupport, "portfolio margin not support sor order")
+		}
+		method, err := papiOrderMethod(market, marginMode, args)
+		return args, market, method, err
+	}
 	method := MethodPrivatePostOrder
 	if sor {
 		method = MethodPrivatePostSorOrder
@@ -268,22 +348,5 @@ func (e *Binance) CreateOrder(symbol, odType, side string, amount float64, price
 			method += "Test"
 		}
 	}
-	tryNum := e.GetRetryNum("CreateOrder", 1)
-	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
-	if rsp.Error != nil {
-		return nil, rsp.Error
-	}
-	var mapSymbol = func(mid string) string {
-		return market.Symbol
-	}
-	if method == MethodFapiPrivatePostOrder {
-		return parseOrder[*FutureOrder](mapSymbol, rsp)
-	} else if method == MethodDapiPrivatePostOrder {
-		return parseOrder[*InverseOrder](mapSymbol, rsp)
-	} else if method == MethodEapiPrivatePostOrder {
-		return parseOrder[*OptionOrder](mapSymbol, rsp)
-	} else {
-		// spot margin sor
-		return parseOrder[*SpotOrder](mapSymbol, rsp)
-	}
+	return args, market, method, nil
 }
@@ -0,0 +1,150 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+	"strings"
+)
+
+/*
+CreateOCO
+place limit maker order at price and stop loss order at stopPrice on the same side.
+spot/margin use native OCO order list, linear/inverse are emulated by banexg.OrderGroupMgr
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#new-oco-trade
+	:see: https://binance-docs.github.io/apidocs/spot/en/#margin-account-new-oco-trade
+	:param float [params.stopLimitPrice]: limit price of stop loss leg, stop market if empty
+	:param str [params.clientOrderId]: listClientOrderId for native OCO
+	:param str [params.marginMode]: 'cross' or 'isolated', for spot margin trading
+*/
+func (e *Binance) CreateOCO(symbol, side string, amount, price, stopPrice float64, params map[string]interface{}) (*banexg.OrderGroup, *errs.Error) {
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return nil, err
+	}
+	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
+	isMargin := market.Type == banexg.MarketMargin || marginMode != ""
+	if !market.Spot && !isMargin {
+		if market.Option {
+			return nil, errs.NewMsg(errs.CodeNotSupport, "CreateOCO not support option")
+		}
+		return banexg.GetOrderGroupMgr(e, params).CreateOCO(symbol, side, amount, price, stopPrice, params)
+	}
+	if price <= 0 || stopPrice <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "price and stopPrice are required for OCO")
+	}
+	stopLimit := utils.PopMapVal(args, banexg.ParamStopLimitPrice, float64(0))
+	clientOrderId := utils.PopMapVal(args, banexg.ParamClientOrderId, "")
+	timeInForce := utils.PopMapVal(args, banexg.ParamTimeInForce, "")
+	reduceOnly := utils.PopMapVal(args, banexg.ParamReduceOnly, false)
+	args["symbol"] = market.ID
+	args["side"] = strings.ToUpper(side)
+	if clientOrderId != "" {
+		args["listClientOrderId"] = clientOrderId
+	}
+	amtVal, err := e.PrecAmount(market, amount)
+	if err != nil {
+		return nil, err
+	}
+	args["quantity"] = amtVal
+	priceVal, err := e.PrecPrice(market, price)
+	if err != nil {
+		return nil, err
+	}
+	args["price"] = priceVal
+	stopVal, err := e.PrecPrice(market, stopPrice)
+	if err != nil {
+		return nil, err
+	}
+	args["stopPrice"] = stopVal
+	if stopLimit > 0 {
+		stopLimitVal, err := e.PrecPrice(market, stopLimit)
+		if err != nil {
+			return nil, err
+		}
+		args["stopLimitPrice"] = stopLimitVal
+		if timeInForce == "" {
+			timeInForce = banexg.TimeInForceGTC
+		}
+		args["stopLimitTimeInForce"] = timeInForce
+	}
+	args["newOrderRespType"] = "RESULT"
+	method := MethodPrivatePostOrderOco
+	if isMargin {
+		method = MethodSapiPostMarginOrderOco
+		if marginMode == banexg.MarginIsolated {
+			args["isIsolated"] = true
+		}
+		if reduceOnly {
+			args["sideEffectType"] = "AUTO_REPAY"
+		}
+	}
+	tryNum := e.GetRetryNum("CreateOCO", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parseOcoOrder(market.Symbol, isMargin, rsp.Content)
+}
+
+/*
+CreateBracketOrder
+place entry order, exit legs are placed by CreateOCO after entry filled, emulated by banexg.OrderGroupMgr
+下入场订单，入场成交后通过CreateOCO下出场订单，由banexg.OrderGroupMgr模拟
+*/
+func (e *Binance) CreateBracketOrder(req *banexg.BracketReq, params map[string]interface{}) (*banexg.OrderGroup, *errs.Error) {
+	return banexg.GetOrderGroupMgr(e, params).CreateBracket(req, params)
+}
+
+/*
+parseOcoOrder
+parse order list of OCO, orderReports are parsed as orders
+解析OCO订单列表，orderReports解析为订单
+*/
+func parseOcoOrder(symbol string, isMargin bool, content string) (*banexg.OrderGroup, *errs.Error) {
+	var data OcoOrderList
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumAuto)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var mapSymbol = func(mid string) string {
+		return symbol
+	}
+	legs := make([]*banexg.Order, 0, len(data.OrderReports))
+	for _, item := range data.OrderReports {
+		text, _ := utils.MarshalString(item)
+		rsp := &banexg.HttpRes{Content: text}
+		var od *banexg.Order
+		var err *errs.Error
+		if isMargin {
+			od, err = parseOrder[*MarginOrder](mapSymbol, rsp)
+		} else {
+			od, err = parseOrder[*SpotOrder](mapSymbol, rsp)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(od.Type, "stop") {
+			legs = append(legs, od)
+		} else {
+			// take profit leg first
+			legs = append([]*banexg.Order{od}, legs...)
+		}
+	}
+	status := banexg.OdStatusOpen
+	if data.ListOrderStatus == "ALL_DONE" {
+		status = banexg.OdStatusFilled
+	} else if data.ListOrderStatus == "REJECT" {
+		status = banexg.OdStatusRejected
+	}
+	return &banexg.OrderGroup{
+		ID:     strconv.FormatInt(data.OrderListId, 10),
+		Symbol: symbol,
+		Native: true,
+		Status: status,
+		Legs:   legs,
+	}, nil
+}
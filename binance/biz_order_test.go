@@ -108,3 +108,39 @@ func TestCalcelOrder(t *testing.T) {
 	resStr, _ := utils.MarshalString(res)
 	log.Info("cancel order", zap.String("res", resStr))
 }
+
+func TestParseBatchOrders(t *testing.T) {
+	content := `[{"orderId":123,"symbol":"BTCUSDT","status":"NEW","clientOrderId":"a1","price":"30000","origQty":"0.01",
+"executedQty":"0","type":"LIMIT","side":"BUY","updateTime":1700000000000},{"code":-2022,"msg":"ReduceOnly Order is rejected."}]`
+	res, err := parseBatchOrders(MethodFapiPrivatePostBatchOrders, []string{"BTC/USDT:USDT", "BTC/USDT:USDT"}, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("expect 2 results, got %d", len(res))
+	}
+	od := res[0].Order
+	if res[0].Err != nil || od == nil || od.ID != "123" || od.Symbol != "BTC/USDT:USDT" || od.Amount != 0.01 {
+		t.Errorf("bad order: %+v %v", od, res[0].Err)
+	}
+	if res[1].Err == nil || res[1].Err.BizCode != -2022 {
+		t.Errorf("expect biz error, got: %v", res[1].Err)
+	}
+}
+
+func TestParseOcoOrder(t *testing.T) {
+	content := `{"orderListId":12,"contingencyType":"OCO","listStatusType":"EXEC_STARTED","listOrderStatus":"EXECUTING",
+"listClientOrderId":"abc","transactionTime":1563417480525,"symbol":"BTCUSDT","orderReports":[
+{"symbol":"BTCUSDT","orderId":2,"orderListId":12,"clientOrderId":"a1","transactTime":1563417480525,"price":"0.00000","origQty":"1.00","executedQty":"0.00","cummulativeQuoteQty":"0.00","status":"NEW","timeInForce":"GTC","type":"STOP_LOSS","side":"SELL","stopPrice":"90.00"},
+{"symbol":"BTCUSDT","orderId":3,"orderListId":12,"clientOrderId":"a2","transactTime":1563417480525,"price":"120.00","origQty":"1.00","executedQty":"0.00","cummulativeQuoteQty":"0.00","status":"NEW","timeInForce":"GTC","type":"LIMIT_MAKER","side":"SELL"}]}`
+	group, err := parseOcoOrder("BTC/USDT", false, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.ID != "12" || !group.Native || group.Status != banexg.OdStatusOpen || len(group.Legs) != 2 {
+		t.Fatalf("bad group: %+v", group)
+	}
+	if group.Legs[0].ID != "3" || group.Legs[1].ID != "2" || group.Legs[0].Symbol != "BTC/USDT" {
+		t.Errorf("take profit leg should be first: %+v %+v", group.Legs[0], group.Legs[1])
+	}
+}
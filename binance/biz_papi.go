@@ -0,0 +1,289 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+	"strings"
+)
+
+// pmCondOrderTypes contract order types sent to conditional api of portfolio margin 统一账户需走条件单接口的合约订单类型
+var pmCondOrderTypes = map[string]bool{
+	"STOP":                 true,
+	"TAKE_PROFIT":          true,
+	"STOP_MARKET":          true,
+	"TAKE_PROFIT_MARKET":   true,
+	"TRAILING_STOP_MARKET": true,
+}
+
+/*
+isPortfolioMargin
+whether to use portfolio margin(papi) routes, params.portfolioMargin overrides OptPortfolioMargin
+是否使用统一账户(papi)接口，params.portfolioMargin优先于OptPortfolioMargin
+*/
+func (e *Binance) isPortfolioMargin(args map[string]interface{}) bool {
+	return utils.PopMapVal(args, banexg.ParamPortfolioMargin, utils.GetMapVal(e.Options, banexg.OptPortfolioMargin, false))
+}
+
+/*
+papiOrderMethod
+get papi method for CreateOrder, conditional orders of contracts are sent as strategy orders.
+spot orders are placed in cross margin of portfolio margin account.
+获取统一账户下单接口，合约条件单走策略单接口；现货订单在统一账户的全仓杠杆下单
+*/
+func papiOrderMethod(market *banexg.Market, marginMode string, args map[string]interface{}) (string, *errs.Error) {
+	if market.Option {
+		return "", errs.NewMsg(errs.CodeNotSupport, "portfolio margin not support option")
+	}
+	if market.Linear || market.Inverse {
+		exgOdType := utils.GetMapVal(args, "type", "")
+		if !pmCondOrderTypes[exgOdType] {
+			if market.Linear {
+				return MethodPapiPostUmOrder, nil
+			}
+			return MethodPapiPostCmOrder, nil
+		}
+		delete(args, "type")
+		delete(args, "newOrderRespType")
+		args["strategyType"] = exgOdType
+		args["newClientStrategyId"] = utils.PopMapVal(args, "newClientOrderId", "")
+		if market.Linear {
+			return MethodPapiPostUmConditionalOrder, nil
+		}
+		return MethodPapiPostCmConditionalOrder, nil
+	}
+	if marginMode == banexg.MarginIsolated {
+		return "", errs.NewMsg(errs.CodeNotSupport, "portfolio margin support cross margin only")
+	}
+	if utils.PopMapVal(args, banexg.ParamTest, false) {
+		return "", errs.NewMsg(errs.CodeNotSupport, "portfolio margin not support test order")
+	}
+	delete(args, "isIsolated")
+	return MethodPapiPostMarginOrder, nil
+}
+
+// pmRoute papi methods of an order api for um/cm/margin orders and um/cm conditional orders, empty if not supported
+type pmRoute struct {
+	Um     string
+	Cm     string
+	Margin string
+	UmCond string
+	CmCond string
+}
+
+var (
+	pmFetchOrder  = &pmRoute{MethodPapiGetUmOrder, MethodPapiGetCmOrder, MethodPapiGetMarginOrder, MethodPapiGetUmConditionalOpenOrder, MethodPapiGetCmConditionalOpenOrder}
+	pmOrderHis    = &pmRoute{UmCond: MethodPapiGetUmConditionalOrderHistory, CmCond: MethodPapiGetCmConditionalOrderHistory}
+	pmFetchOrders = &pmRoute{MethodPapiGetUmAllOrders, MethodPapiGetCmAllOrders, MethodPapiGetMarginAllOrders, MethodPapiGetUmConditionalAllOrders, MethodPapiGetCmConditionalAllOrders}
+	pmOpenOrders  = &pmRoute{MethodPapiGetUmOpenOrders, MethodPapiGetCmOpenOrders, MethodPapiGetMarginOpenOrders, MethodPapiGetUmConditionalOpenOrders, MethodPapiGetCmConditionalOpenOrders}
+	pmEditOrder   = &pmRoute{Um: MethodPapiPutUmOrder, Cm: MethodPapiPutCmOrder}
+	pmCancelOrder = &pmRoute{MethodPapiDeleteUmOrder, MethodPapiDeleteCmOrder, MethodPapiDeleteMarginOrder, MethodPapiDeleteUmConditionalOrder, MethodPapiDeleteCmConditionalOrder}
+	pmCancelAll   = &pmRoute{MethodPapiDeleteUmAllOpenOrders, MethodPapiDeleteCmAllOpenOrders, MethodPapiDeleteMarginAllOpenOrders, MethodPapiDeleteUmConditionalAllOpenOrders, MethodPapiDeleteCmConditionalAllOpenOrders}
+	pmLeverage    = &pmRoute{Um: MethodPapiPostUmLeverage, Cm: MethodPapiPostCmLeverage}
+	pmRoutes      = []*pmRoute{pmFetchOrder, pmOrderHis, pmFetchOrders, pmOpenOrders, pmEditOrder, pmCancelOrder, pmCancelAll}
+)
+
+const (
+	pmKindCond   = "conditional"
+	pmStreamType = "portfolio" // user stream of portfolio margin account for all markets 统一账户所有市场共用的用户数据流
+)
+
+/*
+pick
+get papi method of market type, params.conditional selects conditional(strategy) orders of contracts.
+spot orders are in cross margin of portfolio margin account.
+按市场类型获取统一账户接口，params.conditional选择合约条件单(策略单)；现货订单在统一账户的全仓杠杆
+*/
+func (r *pmRoute) pick(name, marketType, marginMode string, args map[string]interface{}) (string, *errs.Error) {
+	cond := utils.PopMapVal(args, banexg.ParamConditional, false)
+	var method string
+	switch marketType {
+	case banexg.MarketLinear:
+		method = r.Um
+		if cond {
+			method = r.UmCond
+		}
+	case banexg.MarketInverse:
+		method = r.Cm
+		if cond {
+			method = r.CmCond
+		}
+	case banexg.MarketSpot, banexg.MarketMargin:
+		if marginMode == banexg.MarginIsolated {
+			return "", errs.NewMsg(errs.CodeNotSupport, "portfolio margin support cross margin only")
+		}
+		if !cond {
+			method = r.Margin
+		}
+	}
+	if method == "" {
+		if cond {
+			return "", errs.NewMsg(errs.CodeNotSupport, "portfolio margin not support %s for conditional %s orders", name, marketType)
+		}
+		return "", errs.NewMsg(errs.CodeNotSupport, "portfolio margin not support %s for %s", name, marketType)
+	}
+	if cond {
+		// 条件单使用策略单ID
+		if id, ok := args["orderId"]; ok {
+			delete(args, "orderId")
+			args["strategyId"] = id
+		}
+		if id, ok := args["origClientOrderId"]; ok {
+			delete(args, "origClientOrderId")
+			args["newClientStrategyId"] = id
+		}
+	}
+	return method, nil
+}
+
+/*
+pmOrderKind
+return market type of order returned by papi method, pmKindCond for conditional orders, empty for non-papi methods
+返回papi接口的订单市场类型，条件单返回pmKindCond，非papi接口返回空
+*/
+func pmOrderKind(method string) string {
+	for _, r := range pmRoutes {
+		switch method {
+		case r.Um:
+			return banexg.MarketLinear
+		case r.Cm:
+			return banexg.MarketInverse
+		case r.Margin:
+			return banexg.MarketMargin
+		case r.UmCond, r.CmCond:
+			return pmKindCond
+		}
+	}
+	return ""
+}
+
+func parsePmOrder(method string, mapSymbol func(string) string, rsp *banexg.HttpRes) (*banexg.Order, *errs.Error) {
+	switch pmOrderKind(method) {
+	case pmKindCond:
+		return parseOrder[*PmConditionalOrder](mapSymbol, rsp)
+	case banexg.MarketLinear:
+		return parseOrder[*FutureOrder](mapSymbol, rsp)
+	case banexg.MarketInverse:
+		return parseOrder[*InverseOrder](mapSymbol, rsp)
+	case banexg.MarketMargin:
+		return parseOrder[*MarginOrder](mapSymbol, rsp)
+	default:
+		return nil, errs.NewMsg(errs.CodeNotSupport, "not support order method %s", method)
+	}
+}
+
+func parsePmOrders(method string, mapSymbol func(string) string, rsp *banexg.HttpRes) ([]*banexg.Order, *errs.Error) {
+	switch pmOrderKind(method) {
+	case pmKindCond:
+		return parseOrders[*PmConditionalOrder](mapSymbol, rsp)
+	case banexg.MarketLinear:
+		return parseOrders[*FutureOrder](mapSymbol, rsp)
+	case banexg.MarketInverse:
+		return parseOrders[*InverseOrder](mapSymbol, rsp)
+	case banexg.MarketMargin:
+		return parseOrders[*MarginOrder](mapSymbol, rsp)
+	default:
+		return nil, errs.NewMsg(errs.CodeNotSupport, "not support order method %s", method)
+	}
+}
+
+func parsePmBalances(getCurrCode func(string) string, rsp *banexg.HttpRes) (*banexg.Balances, *errs.Error) {
+	var data = make([]*PmBalance, 0)
+	result, err := unmarshalBalance(rsp.Content, &data)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range data {
+		asset := item.ToStdAsset(getCurrCode)
+		if asset.IsEmpty() {
+			continue
+		}
+		result.Assets[asset.Code] = asset
+	}
+	return result.Init(), nil
+}
+
+func (a *PmBalance) ToStdAsset(getCurrCode func(string) string) *banexg.Asset {
+	total, _ := strconv.ParseFloat(a.TotalWalletBalance, 64)
+	lock, _ := strconv.ParseFloat(a.CrossMarginLocked, 64)
+	borr, _ := strconv.ParseFloat(a.CrossMarginBorrowed, 64)
+	inst, _ := strconv.ParseFloat(a.CrossMarginInterest, 64)
+	umPnl, _ := strconv.ParseFloat(a.UmUnrealizedPNL, 64)
+	cmPnl, _ := strconv.ParseFloat(a.CmUnrealizedPNL, 64)
+	return &banexg.Asset{
+		Code:  getCurrCode(a.Asset),
+		Free:  total - lock,
+		Used:  lock,
+		Total: total,
+		Debt:  borr + inst,
+		UPol:  umPnl + cmPnl,
+	}
+}
+
+func (o *PmConditionalOrder) ToStdOrder(mapSymbol func(string) string) *banexg.Order {
+	price, _ := strconv.ParseFloat(o.Price, 64)
+	amount, _ := strconv.ParseFloat(o.OrigQty, 64)
+	stopPrice, _ := strconv.ParseFloat(o.StopPrice, 64)
+	timeInForce := o.TimeInForce
+	if timeInForce == "GTX" {
+		timeInForce = banexg.TimeInForcePO
+	}
+	return &banexg.Order{
+		Info:                o,
+		ID:                  strconv.FormatInt(o.StrategyId, 10),
+		ClientOrderID:       o.NewClientStrategyId,
+		Timestamp:           o.BookTime,
+		Datetime:            utils.ISO8601(o.BookTime),
+		LastUpdateTimestamp: o.UpdateTime,
+		Symbol:              mapSymbol(o.Symbol),
+		Type:                strings.ToLower(o.StrategyType),
+		TimeInForce:         timeInForce,
+		PostOnly:            timeInForce == banexg.TimeInForcePO,
+		PositionSide:        strings.ToLower(o.PositionSide),
+		Side:                strings.ToLower(o.Side),
+		Price:               price,
+		TriggerPrice:        stopPrice,
+		Amount:              amount,
+		Remaining:           amount,
+		Status:              mapOrderStatus(o.StrategyStatus),
+		ReduceOnly:          o.ReduceOnly,
+		Fee:                 &banexg.Fee{},
+		Trades:              make([]*banexg.Trade, 0),
+	}
+}
+
+/*
+fetchPmOrder
+fetch order of portfolio margin account. Conditional open orders are queried first, then the history
+查询统一账户订单。条件单先查询当前委托，找不到时查询历史
+*/
+func (e *Binance) fetchPmOrder(market *banexg.Market, marginMode string, args map[string]interface{}) (*banexg.Order, *errs.Error) {
+	clientOrderId := utils.PopMapVal(args, banexg.ParamClientOrderId, "")
+	if clientOrderId != "" {
+		args["origClientOrderId"] = clientOrderId
+	}
+	cond := utils.GetMapVal(args, banexg.ParamConditional, false)
+	method, err := pmFetchOrder.pick("FetchOrder", market.Type, marginMode, args)
+	if err != nil {
+		return nil, err
+	}
+	tryNum := e.GetRetryNum("FetchOrder", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if cond && rsp.Error != nil && rsp.Error.Code < 500 {
+		// openOrder只能查到未触发的条件单
+		method = pmOrderHis.UmCond
+		if market.Inverse {
+			method = pmOrderHis.CmCond
+		}
+		rsp = e.RequestApiRetry(context.Background(), method, args, tryNum)
+	}
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var mapSymbol = func(mid string) string {
+		return market.Symbol
+	}
+	return parsePmOrder(method, mapSymbol, rsp)
+}
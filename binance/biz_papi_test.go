@@ -0,0 +1,207 @@
+package binance
+
+import (
+	"fmt"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func getOfflinePortfolio(t *testing.T) *Binance {
+	exg := getOfflineLinear(t)
+	exg.Options[banexg.OptPortfolioMargin] = true
+	btc := exg.Markets["BTC/USDT:USDT"]
+	btc.Precision = &banexg.Precision{Amount: 0.001, ModeAmount: banexg.PrecModeTickSize, Price: 0.1, ModePrice: banexg.PrecModeTickSize}
+	btc.Info = &BnbMarket{OrderTypes: []string{"LIMIT", "MARKET", "STOP", "STOP_MARKET", "TAKE_PROFIT", "TAKE_PROFIT_MARKET"}}
+	return exg
+}
+
+func TestPortfolioCreateOrder(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflinePortfolio(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://papi.binance.com").Post("/papi/v1/um/order").BodyString("type=LIMIT").
+		Reply(200).BodyString(`{"clientOrderId":"cli1","cumQty":"0","cumQuote":"0","executedQty":"0","orderId":22542179,
+"avgPrice":"0.00000","origQty":"0.010","price":"30000.0","reduceOnly":false,"side":"BUY","positionSide":"BOTH",
+"status":"NEW","symbol":"BTCUSDT","timeInForce":"GTC","type":"LIMIT","updateTime":1566818724722}`)
+	gock.New("https://papi.binance.com").Post("/papi/v1/um/conditional/order").BodyString("strategyType=STOP_MARKET").
+		Reply(200).BodyString(`{"newClientStrategyId":"cli2","strategyId":3645916,"strategyStatus":"NEW",
+"strategyType":"STOP_MARKET","origQty":"0.010","price":"0","reduceOnly":true,"side":"SELL","positionSide":"BOTH",
+"stopPrice":"28000.0","symbol":"BTCUSDT","timeInForce":"GTC","bookTime":1566818724710,"updateTime":1566818724722}`)
+	od, err := exg.CreateOrder("BTC/USDT:USDT", banexg.OdTypeLimit, banexg.OdSideBuy, 0.01, 30000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if od.ID != "22542179" || od.Amount != 0.01 || od.Status != banexg.OdStatusOpen {
+		t.Errorf("bad um order: %+v", od)
+	}
+	od, err = exg.CreateOrder("BTC/USDT:USDT", banexg.OdTypeMarket, banexg.OdSideSell, 0.01, 0,
+		map[string]interface{}{banexg.ParamStopLossPrice: 28000.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if od.ID != "3645916" || od.ClientOrderID != "cli2" || od.Type != banexg.OdTypeStopMarket || od.TriggerPrice != 28000 || !od.ReduceOnly {
+		t.Errorf("bad conditional order: %+v", od)
+	}
+	if !gock.IsDone() {
+		t.Error("papi routes not requested")
+	}
+	_, _, method, err := exg.makeCreateOrderArgs("BTC/USDT:USDT", banexg.OdTypeLimit, banexg.OdSideBuy, 0.01, 30000,
+		map[string]interface{}{banexg.ParamPortfolioMargin: false})
+	if err != nil || method != MethodFapiPrivatePostOrder {
+		t.Errorf("params should override option, got %s %v", method, err)
+	}
+}
+
+func TestPortfolioFetchBalance(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflinePortfolio(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://papi.binance.com").Get("/papi/v1/balance").
+		Reply(200).BodyString(`[{"asset":"USDT","totalWalletBalance":"1000","crossMarginAsset":"100","crossMarginBorrowed":"10",
+"crossMarginFree":"90","crossMarginInterest":"0.5","crossMarginLocked":"3","umWalletBalance":"900","umUnrealizedPNL":"20",
+"cmWalletBalance":"0","cmUnrealizedPNL":"","updateTime":1617939110373,"negativeBalance":"0"}]`)
+	res, err := exg.FetchBalance(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usdt, ok := res.Assets["USDT"]
+	if !ok {
+		t.Fatalf("USDT missing: %+v", res.Assets)
+	}
+	if usdt.Total != 1000 || usdt.Used != 3 || usdt.Free != 997 || usdt.Debt != 10.5 || usdt.UPol != 20 {
+		t.Errorf("bad portfolio asset: %+v", usdt)
+	}
+}
+
+func TestPortfolioOrderRoutes(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflinePortfolio(t)
+	gock.InterceptClient(exg.HttpClient)
+	host := "https://papi.binance.com"
+	umOrder := `{"clientOrderId":"cli1","cumQty":"0","cumQuote":"0","executedQty":"0","orderId":%s,"avgPrice":"0.00000",
+"origQty":"0.010","price":"30000.0","reduceOnly":false,"side":"BUY","positionSide":"BOTH","status":"%s",
+"symbol":"BTCUSDT","timeInForce":"GTC","type":"LIMIT","updateTime":1566818724722}`
+	condOrder := `{"newClientStrategyId":"cli2","strategyId":3645916,"strategyStatus":"%s","strategyType":"STOP_MARKET",
+"origQty":"0.010","price":"0","reduceOnly":true,"side":"SELL","positionSide":"BOTH","stopPrice":"28000.0",
+"symbol":"BTCUSDT","timeInForce":"GTC","bookTime":1566818724710,"updateTime":1566818724722}`
+	gock.New(host).Delete("/papi/v1/um/order").MatchParam("orderId", "1").
+		Reply(200).BodyString(fmt.Sprintf(umOrder, "1", "CANCELED"))
+	gock.New(host).Delete("/papi/v1/um/conditional/order").MatchParam("strategyId", "3645916").
+		Reply(200).BodyString(fmt.Sprintf(condOrder, "CANCELED"))
+	gock.New(host).Get("/papi/v1/um/conditional/openOrder").MatchParam("strategyId", "3645916").
+		Reply(400).JSON(map[string]interface{}{"code": -2013, "msg": "Order does not exist."})
+	gock.New(host).Get("/papi/v1/um/conditional/orderHistory").MatchParam("strategyId", "3645916").
+		Reply(200).BodyString(fmt.Sprintf(condOrder, "TRIGGERED"))
+	gock.New(host).Get("/papi/v1/um/openOrders").
+		Reply(200).BodyString("[" + fmt.Sprintf(umOrder, "2", "NEW") + "]")
+	gock.New(host).Put("/papi/v1/um/order").BodyString("orderId=2").
+		Reply(200).BodyString(fmt.Sprintf(umOrder, "2", "NEW"))
+	gock.New(host).Get("/papi/v1/um/conditional/openOrders").
+		Reply(200).BodyString("[" + fmt.Sprintf(condOrder, "NEW") + "]")
+	gock.New(host).Delete("/papi/v1/um/conditional/allOpenOrders").
+		Reply(200).JSON(map[string]interface{}{"code": 200, "msg": "The operation of cancel all conditional open order is done."})
+	gock.New(host).Post("/papi/v1/um/leverage").BodyString("leverage=5").
+		Reply(200).JSON(map[string]interface{}{"leverage": 5, "maxNotionalValue": "1000000", "symbol": "BTCUSDT"})
+	gock.New(host).Delete("/papi/v1/um/order").MatchParam("orderId", "3").
+		Reply(200).BodyString(fmt.Sprintf(umOrder, "3", "CANCELED"))
+	gock.New(host).Delete("/papi/v1/um/order").MatchParam("orderId", "4").
+		Reply(200).BodyString(fmt.Sprintf(umOrder, "4", "CANCELED"))
+
+	symbol := "BTC/USDT:USDT"
+	cond := map[string]interface{}{banexg.ParamConditional: true}
+	od, err := exg.CancelOrder("1", symbol, nil)
+	if err != nil || od.ID != "1" || od.Status != banexg.OdStatusCanceled {
+		t.Fatalf("CancelOrder: %+v %v", od, err)
+	}
+	od, err = exg.CancelOrder("3645916", symbol, cond)
+	if err != nil || od.ID != "3645916" || od.Status != banexg.OdStatusCanceled {
+		t.Fatalf("cancel conditional: %+v %v", od, err)
+	}
+	od, err = exg.FetchOrder(symbol, "3645916", cond)
+	if err != nil || od.ID != "3645916" || od.TriggerPrice != 28000 {
+		t.Fatalf("fetch conditional from history: %+v %v", od, err)
+	}
+	ods, err := exg.FetchOpenOrders(symbol, 0, 0, nil)
+	if err != nil || len(ods) != 1 || ods[0].ID != "2" {
+		t.Fatalf("FetchOpenOrders: %v %v", ods, err)
+	}
+	od, err = exg.EditOrder(symbol, "2", banexg.OdSideBuy, 0.01, 30000, nil)
+	if err != nil || od.ID != "2" {
+		t.Fatalf("EditOrder: %+v %v", od, err)
+	}
+	ods, err = exg.CancelAllOrders(symbol, cond)
+	if err != nil || len(ods) != 1 || ods[0].ID != "3645916" || ods[0].Status != banexg.OdStatusCanceled {
+		t.Fatalf("CancelAllOrders: %v %v", ods, err)
+	}
+	if _, err = exg.SetLeverage(5, symbol, nil); err != nil {
+		t.Fatalf("SetLeverage: %v", err)
+	}
+	res, err := exg.CancelOrders([]*banexg.OrderReq{{ID: "3", Symbol: symbol}, {ID: "4", Symbol: symbol}}, nil)
+	if err != nil || len(res) != 2 || res[0].Err != nil || res[1].Err != nil || res[1].Order.ID != "4" {
+		t.Fatalf("CancelOrders: %v %v", res, err)
+	}
+	if !gock.IsDone() {
+		t.Error("papi routes not requested")
+	}
+
+	_, err = exg.EditOrder(symbol, "3645916", banexg.OdSideBuy, 0.01, 30000, cond)
+	if err == nil || err.Code != errs.CodeNotSupport {
+		t.Errorf("edit conditional should not be supported: %v", err)
+	}
+	res, _ = exg.EditOrders([]*banexg.OrderReq{{ID: "2", Symbol: symbol, Side: banexg.OdSideBuy, Amount: 0.01, Price: 30000}}, nil)
+	if len(res) != 1 || res[0].Err == nil || res[0].Err.Code != errs.CodeNotSupport {
+		t.Errorf("batch edit should not be supported: %v", res)
+	}
+}
+
+func TestPortfolioUserStream(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflinePortfolio(t)
+	gock.InterceptClient(exg.HttpClient)
+	btcInv := &banexg.Market{ID: "BTCUSD_PERP", Symbol: "BTC/USD:BTC", Type: banexg.MarketInverse,
+		Contract: true, Inverse: true, Swap: true, Base: "BTC", Quote: "USD", Settle: "BTC"}
+	exg.Markets[btcInv.Symbol] = btcInv
+	exg.MarketsById[btcInv.ID] = []*banexg.Market{btcInv}
+	// only one listenKey for all markets of portfolio margin account
+	gock.New("https://papi.binance.com").Post("/papi/v1/listenKey").
+		Reply(200).JSON(map[string]interface{}{"listenKey": "pmKey"})
+	acc, err := exg.GetAccount(exg.DefAccName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, marketType := range []string{banexg.MarketLinear, banexg.MarketInverse} {
+		err = exg.postListenKey(acc, map[string]interface{}{banexg.ParamMarket: marketType})
+		if err != nil {
+			t.Fatalf("postListenKey %s: %v", marketType, err)
+		}
+	}
+	if key := utils.GetMapVal(acc.Data, pmStreamType+banexg.MidListenKey, ""); key != "pmKey" {
+		t.Errorf("listenKey should be keyed by account, got %v", acc.Data)
+	}
+
+	client := &banexg.WsClient{Exg: exg.Exchange, AccName: exg.DefAccName, MarketType: pmStreamType}
+	create := func(cap int) chan *banexg.MyTrade { return make(chan *banexg.MyTrade, cap) }
+	out := banexg.GetWsOutChan(exg.Exchange, client.Prefix("mytrades"), create, map[string]interface{}{})
+	for _, it := range []struct{ fs, symbol string }{{"UM", "BTCUSDT"}, {"CM", "BTCUSD_PERP"}} {
+		exg.handleOrderUpdate(client, map[string]string{"e": "ORDER_TRADE_UPDATE", "fs": it.fs, "E": "1568879465651",
+			"o": `{"s":"` + it.symbol + `","c":"cli1","S":"BUY","o":"LIMIT","q":"1","p":"100","X":"FILLED","i":8886774,
+"l":"1","z":"1","L":"100","T":1568879465650,"t":12}`})
+	}
+	for _, symbol := range []string{"BTC/USDT:USDT", "BTC/USD:BTC"} {
+		select {
+		case trade := <-out:
+			if trade.Symbol != symbol {
+				t.Errorf("trade symbol should be %s, got %s", symbol, trade.Symbol)
+			}
+		default:
+			t.Fatalf("no trade for %s", symbol)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+	"strings"
+)
+
+/*
+ModifyPositionMargin
+add or reduce isolated margin of position
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#modify-isolated-position-margin-trade
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#modify-isolated-position-margin-trade
+	:param str symbol: unified market symbol
+	:param float amount: amount of margin, in settle currency
+	:param str changeType: MarginAdd or MarginReduce
+	:param str posSide: long/short in hedge mode, empty for one-way mode
+*/
+func (e *Binance) ModifyPositionMargin(symbol string, amount float64, changeType, posSide string, params map[string]interface{}) (*banexg.MarginChange, *errs.Error) {
+	var typeVal int
+	if changeType == banexg.MarginAdd {
+		typeVal = 1
+	} else if changeType == banexg.MarginReduce {
+		typeVal = 2
+	} else {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "invalid margin change type: %s", changeType)
+	}
+	if amount <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "amount should be positive for ModifyPositionMargin")
+	}
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return nil, err
+	}
+	var method string
+	if market.Linear {
+		method = MethodFapiPrivatePostPositionMargin
+	} else if market.Inverse {
+		method = MethodDapiPrivatePostPositionMargin
+	} else {
+		return nil, errs.NewMsg(errs.CodeUnsupportMarket, "ModifyPositionMargin support linear/inverse contracts only")
+	}
+	args["symbol"] = market.ID
+	args["amount"] = amount
+	args["type"] = typeVal
+	if posSide != "" && posSide != banexg.PosSideBoth {
+		args["positionSide"] = strings.ToUpper(posSide)
+	}
+	tryNum := e.GetRetryNum("ModifyPositionMargin", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = PositionMarginRsp{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	if data.Code != 0 && data.Code != 200 {
+		return nil, errs.NewMsg(errs.CodeRunTime, "ModifyPositionMargin fail: %v %s", data.Code, data.Msg)
+	}
+	if posSide == "" {
+		posSide = banexg.PosSideBoth
+	}
+	return &banexg.MarginChange{
+		Symbol:    market.Symbol,
+		Type:      changeType,
+		Amount:    data.Amount,
+		Code:      market.Settle,
+		PosSide:   posSide,
+		Timestamp: e.MilliSeconds(),
+		Info:      data,
+	}, nil
+}
+
+/*
+FetchPositionMarginHistory
+fetch isolated margin changes of symbol
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#get-position-margin-change-history-trade
+	:see: https://binance-docs.github.io/apidocs/delivery/en/#get-position-margin-change-history-trade
+	:param int [params.until]: end time in ms
+	:param str [params.type]: MarginAdd or MarginReduce, both if empty
+*/
+func (e *Binance) FetchPositionMarginHistory(symbol string, since int64, limit int, params map[string]interface{}) ([]*banexg.MarginChange, *errs.Error) {
+	args, market, err := e.LoadArgsMarket(symbol, params)
+	if err != nil {
+		return nil, err
+	}
+	var method string
+	if market.Linear {
+		method = MethodFapiPrivateGetPositionMarginHistory
+	} else if market.Inverse {
+		method = MethodDapiPrivateGetPositionMarginHistory
+	} else {
+		return nil, errs.NewMsg(errs.CodeUnsupportMarket, "FetchPositionMarginHistory support linear/inverse contracts only")
+	}
+	args["symbol"] = market.ID
+	changeType := utils.PopMapVal(args, "type", "")
+	if changeType == banexg.MarginAdd {
+		args["type"] = 1
+	} else if changeType == banexg.MarginReduce {
+		args["type"] = 2
+	}
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until > 0 {
+		args["endTime"] = until
+	}
+	if since > 0 {
+		args["startTime"] = since
+	}
+	if limit > 0 {
+		args["limit"] = limit
+	}
+	tryNum := e.GetRetryNum("FetchPositionMarginHistory", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parsePositionMarginHistory(e, market.Type, rsp.Content)
+}
+
+func parsePositionMarginHistory(e *Binance, marketType, content string) ([]*banexg.MarginChange, *errs.Error) {
+	var data = make([]*PositionMarginItem, 0)
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.MarginChange, 0, len(data))
+	for _, it := range data {
+		symbol := it.Symbol
+		if market := e.GetMarketById(it.Symbol, marketType); market != nil {
+			symbol = market.Symbol
+		}
+		changeType := banexg.MarginAdd
+		if it.Type == 2 {
+			changeType = banexg.MarginReduce
+		}
+		amount, _ := strconv.ParseFloat(it.Amount, 64)
+		res = append(res, &banexg.MarginChange{
+			Symbol:    symbol,
+			Type:      changeType,
+			Amount:    amount,
+			Code:      e.SafeCurrencyCode(it.Asset),
+			PosSide:   strings.ToLower(it.PositionSide),
+			DeltaType: it.DeltaType,
+			Timestamp: it.Time,
+			Info:      it,
+		})
+	}
+	return res, nil
+}
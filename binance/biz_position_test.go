@@ -0,0 +1,42 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func TestModifyPositionMargin(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://fapi.binance.com").Post("/fapi/v1/positionMargin").
+		Reply(200).JSON(map[string]interface{}{"amount": 100.0, "code": 200, "msg": "Successfully modify position margin.", "type": 1})
+	res, err := exg.ModifyPositionMargin("BTC/USDT:USDT", 100, banexg.MarginAdd, banexg.PosSideLong, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Amount != 100 || res.Type != banexg.MarginAdd || res.Code != "USDT" || res.PosSide != banexg.PosSideLong {
+		t.Errorf("bad margin change: %+v", res)
+	}
+	if _, err = exg.ModifyPositionMargin("BTC/USDT:USDT", 100, "bad", "", nil); err == nil {
+		t.Error("invalid change type should fail")
+	}
+}
+
+func TestParsePositionMarginHistory(t *testing.T) {
+	exg := getOfflineLinear(t)
+	content := `[{"symbol":"BTCUSDT","type":1,"deltaType":"USER_ADJUST","amount":"23.36332311","asset":"USDT","time":1578047897183,"positionSide":"BOTH"},
+{"symbol":"BTCUSDT","type":2,"deltaType":"USER_ADJUST","amount":"100","asset":"USDT","time":1578047900425,"positionSide":"LONG"}]`
+	res, err := parsePositionMarginHistory(exg, banexg.MarketLinear, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Type != banexg.MarginAdd || res[0].Amount != 23.36332311 || res[0].PosSide != banexg.PosSideBoth {
+		t.Fatalf("bad first item: %+v", res[0])
+	}
+	if res[1].Type != banexg.MarginReduce || res[1].PosSide != banexg.PosSideLong || res[1].Timestamp != 1578047900425 {
+		t.Errorf("bad second item: %+v", res[1])
+	}
+}
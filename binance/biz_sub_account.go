@@ -0,0 +1,313 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"slices"
+	"strconv"
+)
+
+// subAccountTypes market type to account type of sub-account universal transfer 子账户万向划转的账户类型
+var subAccountTypes = map[string]string{
+	banexg.MarketSpot:    "SPOT",
+	banexg.MarketMargin:  "MARGIN",
+	banexg.MarketLinear:  "USDT_FUTURE",
+	banexg.MarketInverse: "COIN_FUTURE",
+}
+
+/*
+FetchSubAccounts
+fetch sub-accounts of master account, use broker api when params.broker is true
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#query-sub-account-list-for-master-account
+	:see: https://binance-docs.github.io/Brokerage-API/Brokerage-Operation-Endpoints/#query-sub-account
+	:param bool [params.broker]: list broker sub-accounts
+*/
+func (e *Binance) FetchSubAccounts(params map[string]interface{}) ([]*banexg.SubAccount, *errs.Error) {
+	args := utils.SafeParams(params)
+	isBroker := utils.PopMapVal(args, banexg.ParamBroker, false)
+	method := MethodSapiGetSubAccountList
+	if isBroker {
+		method = MethodSapiGetBrokerSubAccount
+	}
+	tryNum := e.GetRetryNum("FetchSubAccounts", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	if isBroker {
+		return parseBrokerSubAccounts(rsp.Content)
+	}
+	return parseSubAccounts(rsp.Content)
+}
+
+func parseSubAccounts(content string) ([]*banexg.SubAccount, *errs.Error) {
+	var data = SubAccountListRsp{}
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.SubAccount, 0, len(data.SubAccounts))
+	for _, it := range data.SubAccounts {
+		res = append(res, &banexg.SubAccount{
+			ID:        it.Email,
+			Email:     it.Email,
+			Frozen:    it.IsFreeze,
+			Managed:   it.IsManagedSubAccount,
+			Timestamp: it.CreateTime,
+			Info:      it,
+		})
+	}
+	return res, nil
+}
+
+func parseBrokerSubAccounts(content string) ([]*banexg.SubAccount, *errs.Error) {
+	var data = make([]*BrokerSubAccount, 0)
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.SubAccount, 0, len(data))
+	for _, it := range data {
+		res = append(res, &banexg.SubAccount{
+			ID:        it.SubaccountId,
+			Email:     it.Email,
+			Timestamp: it.CreateTime,
+			Info:      it,
+		})
+	}
+	return res, nil
+}
+
+/*
+FetchSubAccountBalance
+fetch balances of sub-account by email, support spot/linear/inverse
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#query-sub-account-assets-for-master-account
+	:see: https://binance-docs.github.io/apidocs/spot/en/#get-detail-on-sub-account-39-s-futures-account-v2-for-master-account
+	:param str subId: email of sub-account
+	:param str [params.market]: MarketSpot/MarketLinear/MarketInverse, default spot
+*/
+func (e *Binance) FetchSubAccountBalance(subId string, params map[string]interface{}) (*banexg.Balances, *errs.Error) {
+	if subId == "" {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "subId is required for FetchSubAccountBalance")
+	}
+	args := utils.SafeParams(params)
+	marketType := utils.PopMapVal(args, banexg.ParamMarket, banexg.MarketSpot)
+	args["email"] = subId
+	method := MethodSapiV3GetSubAccountAssets
+	if marketType == banexg.MarketLinear {
+		method = MethodSapiV2GetSubAccountFuturesAccount
+		args["futuresType"] = 1
+	} else if marketType == banexg.MarketInverse {
+		method = MethodSapiV2GetSubAccountFuturesAccount
+		args["futuresType"] = 2
+	} else if marketType != banexg.MarketSpot {
+		return nil, errs.NewMsg(errs.CodeUnsupportMarket, "FetchSubAccountBalance not support %s", marketType)
+	}
+	tryNum := e.GetRetryNum("FetchSubAccountBalance", 1)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	if marketType == banexg.MarketSpot {
+		return parseSubAccAssets(e, rsp.Content)
+	}
+	return parseSubFuturesAccount(e, rsp.Content)
+}
+
+func parseSubAccAssets(e *Binance, content string) (*banexg.Balances, *errs.Error) {
+	var data = SubAccAssetsRsp{}
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = banexg.Balances{
+		TimeStamp: e.MilliSeconds(),
+		Assets:    map[string]*banexg.Asset{},
+		Info:      data,
+	}
+	for _, it := range data.Balances {
+		code := e.SafeCurrencyCode(it.Asset)
+		res.Assets[code] = &banexg.Asset{Code: code, Free: it.Free, Used: it.Locked}
+	}
+	return res.Init(), nil
+}
+
+func parseSubFuturesAccount(e *Binance, content string) (*banexg.Balances, *errs.Error) {
+	var data = SubFuturesAccountRsp{}
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	acc := data.FutureAccountResp
+	if acc == nil {
+		acc = data.DeliveryAccountResp
+	}
+	var res = banexg.Balances{
+		TimeStamp: e.MilliSeconds(),
+		Assets:    map[string]*banexg.Asset{},
+		Info:      data,
+	}
+	if acc != nil {
+		for _, it := range acc.Assets {
+			code := e.SafeCurrencyCode(it.Asset)
+			free, _ := strconv.ParseFloat(it.MaxWithdrawAmount, 64)
+			total, _ := strconv.ParseFloat(it.MarginBalance, 64)
+			upol, _ := strconv.ParseFloat(it.UnrealizedProfit, 64)
+			res.Assets[code] = &banexg.Asset{Code: code, Free: free, Used: total - free, Total: total, UPol: upol}
+		}
+	}
+	return res.Init(), nil
+}
+
+/*
+SubAccountTransfer
+transfer between master and sub-accounts with universal transfer, empty id means master account
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#universal-transfer-for-master-account
+	:see: https://binance-docs.github.io/Brokerage-API/Brokerage-Operation-Endpoints/#universal-transfer
+	:param str fromId: email (subaccountId for broker) of source account
+	:param str toId: email (subaccountId for broker) of target account
+	:param str [params.fromMarket]: market type of source wallet, default spot
+	:param str [params.toMarket]: market type of target wallet, default spot
+	:param bool [params.broker]: use broker universal transfer
+*/
+func (e *Binance) SubAccountTransfer(code string, amount float64, fromId, toId string, params map[string]interface{}) (*banexg.Transfer, *errs.Error) {
+	if code == "" || amount <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "code and positive amount are required for SubAccountTransfer")
+	}
+	if fromId == "" && toId == "" || fromId == toId {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "SubAccountTransfer requires different accounts")
+	}
+	args := utils.SafeParams(params)
+	isBroker := utils.PopMapVal(args, banexg.ParamBroker, false)
+	fromMarket := utils.PopMapVal(args, banexg.ParamFromMarket, banexg.MarketSpot)
+	toMarket := utils.PopMapVal(args, banexg.ParamToMarket, banexg.MarketSpot)
+	fromType, ok1 := subAccountTypes[fromMarket]
+	toType, ok2 := subAccountTypes[toMarket]
+	if !ok1 || !ok2 || isBroker && (fromMarket == banexg.MarketMargin || toMarket == banexg.MarketMargin) {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "unsupported sub-account transfer: %s -> %s", fromMarket, toMarket)
+	}
+	args["fromAccountType"] = fromType
+	args["toAccountType"] = toType
+	args["asset"] = code
+	args["amount"] = strconv.FormatFloat(amount, 'f', -1, 64)
+	fromKey, toKey, method := "fromEmail", "toEmail", MethodSapiPostSubAccountUniversalTransfer
+	if isBroker {
+		fromKey, toKey, method = "fromId", "toId", MethodSapiPostBrokerUniversalTransfer
+	}
+	if fromId != "" {
+		args[fromKey] = fromId
+	}
+	if toId != "" {
+		args[toKey] = toId
+	}
+	tryNum := e.GetRetryNum("SubAccountTransfer", 0)
+	rsp := e.RequestApiRetry(context.Background(), method, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = SubTransferRsp{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	id := data.TxnId
+	if id == "" {
+		id = strconv.FormatInt(data.TranId, 10)
+	}
+	return &banexg.Transfer{
+		ID:        id,
+		Code:      code,
+		Amount:    amount,
+		From:      fromMarket,
+		To:        toMarket,
+		Status:    banexg.TxStatusOk,
+		Timestamp: e.MilliSeconds(),
+		Info:      data,
+	}, nil
+}
+
+/*
+CreateSubAccountApiKey
+create api key for broker sub-account, secret is only returned here.
+use AddAccount to register it and trade as the sub-account by params[ParamAccount]
+
+	:see: https://binance-docs.github.io/Brokerage-API/Brokerage-Operation-Endpoints/#create-api-key-for-sub-account
+	:param str subId: broker subaccountId
+	:param []str perms: MarketSpot/MarketMargin/MarketLinear, spot is always enabled
+*/
+func (e *Binance) CreateSubAccountApiKey(subId string, perms []string, params map[string]interface{}) (*banexg.SubAccApiKey, *errs.Error) {
+	if subId == "" {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "subId is required for CreateSubAccountApiKey")
+	}
+	args := utils.SafeParams(params)
+	args["subAccountId"] = subId
+	args["canTrade"] = true
+	args["marginTrade"] = slices.Contains(perms, banexg.MarketMargin)
+	args["futuresTrade"] = slices.Contains(perms, banexg.MarketLinear) || slices.Contains(perms, banexg.MarketInverse)
+	tryNum := e.GetRetryNum("CreateSubAccountApiKey", 0)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiPostBrokerSubAccountApi, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = BrokerSubApiKey{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	return data.ToStdKey(), nil
+}
+
+/*
+FetchSubAccountApiKeys
+fetch api keys of broker sub-account, secrets are not returned
+
+	:see: https://binance-docs.github.io/Brokerage-API/Brokerage-Operation-Endpoints/#query-sub-account-api-key
+	:param str subId: broker subaccountId
+*/
+func (e *Binance) FetchSubAccountApiKeys(subId string, params map[string]interface{}) ([]*banexg.SubAccApiKey, *errs.Error) {
+	if subId == "" {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "subId is required for FetchSubAccountApiKeys")
+	}
+	args := utils.SafeParams(params)
+	args["subAccountId"] = subId
+	tryNum := e.GetRetryNum("FetchSubAccountApiKeys", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetBrokerSubAccountApi, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = make([]*BrokerSubApiKey, 0)
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.SubAccApiKey, 0, len(data))
+	for _, it := range data {
+		res = append(res, it.ToStdKey())
+	}
+	return res, nil
+}
+
+func (k *BrokerSubApiKey) ToStdKey() *banexg.SubAccApiKey {
+	var perms = make([]string, 0, 3)
+	if k.CanTrade {
+		perms = append(perms, banexg.MarketSpot)
+	}
+	if k.MarginTrade {
+		perms = append(perms, banexg.MarketMargin)
+	}
+	if k.FuturesTrade {
+		perms = append(perms, banexg.MarketLinear, banexg.MarketInverse)
+	}
+	return &banexg.SubAccApiKey{
+		SubID:  k.SubaccountId,
+		ApiKey: k.ApiKey,
+		Secret: k.SecretKey,
+		Perms:  perms,
+		Info:   k,
+	}
+}
@@ -0,0 +1,109 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func TestFetchSubAccounts(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://api.binance.com").Get("/sapi/v1/sub-account/list").
+		Reply(200).BodyString(`{"subAccounts":[{"email":"testsub@gmail.com","isFreeze":false,"createTime":1544433328000,
+"isManagedSubAccount":false,"isAssetManagementSubAccount":false},{"email":"virtual@test.com","isFreeze":true,"createTime":1544433329000}]}`)
+	res, err := exg.FetchSubAccounts(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].ID != "testsub@gmail.com" || !res[1].Frozen || res[1].Timestamp != 1544433329000 {
+		t.Errorf("bad sub accounts: %+v", res)
+	}
+	gock.New("https://api.binance.com").Get("/sapi/v1/broker/subAccount").
+		Reply(200).BodyString(`[{"subaccountId":"1","email":"vai_42038996_47411276_brokersubuser@lac.info","tag":"bob123d","createTime":1571903453000}]`)
+	res, err = exg.FetchSubAccounts(map[string]interface{}{banexg.ParamBroker: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].ID != "1" || res[0].Email == "" {
+		t.Errorf("bad broker sub accounts: %+v", res)
+	}
+}
+
+func TestFetchSubAccountBalance(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://api.binance.com").Get("/sapi/v3/sub-account/assets").MatchParam("email", "sub@test.com").
+		Reply(200).BodyString(`{"balances":[{"asset":"USDT","free":100.5,"locked":20},{"asset":"BTC","free":0.1,"locked":0}]}`)
+	res, err := exg.FetchSubAccountBalance("sub@test.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total["USDT"] != 120.5 || res.Free["BTC"] != 0.1 {
+		t.Errorf("bad spot balances: %+v", res)
+	}
+	gock.New("https://api.binance.com").Get("/sapi/v2/sub-account/futures/account").MatchParam("futuresType", "1").
+		Reply(200).BodyString(`{"futureAccountResp":{"email":"sub@test.com","assets":[{"asset":"USDT","initialMargin":"10",
+"maintenanceMargin":"1","marginBalance":"110","maxWithdrawAmount":"100","unrealizedProfit":"10","walletBalance":"100"}]}}`)
+	res, err = exg.FetchSubAccountBalance("sub@test.com", map[string]interface{}{banexg.ParamMarket: banexg.MarketLinear})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ast := res.Assets["USDT"]; ast == nil || ast.Free != 100 || ast.Used != 10 || ast.UPol != 10 {
+		t.Errorf("bad futures balances: %+v", res.Assets)
+	}
+}
+
+func TestSubAccountTransfer(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	if _, err := exg.SubAccountTransfer("USDT", 10, "", "", nil); err == nil {
+		t.Error("master to master should fail")
+	}
+	gock.New("https://api.binance.com").Post("/sapi/v1/sub-account/universalTransfer").
+		Reply(200).JSON(map[string]interface{}{"tranId": 11945860693, "clientTranId": ""})
+	res, err := exg.SubAccountTransfer("USDT", 10, "", "sub@test.com", map[string]interface{}{
+		banexg.ParamToMarket: banexg.MarketLinear,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != "11945860693" || res.From != banexg.MarketSpot || res.To != banexg.MarketLinear {
+		t.Errorf("bad transfer: %+v", res)
+	}
+}
+
+func TestSubAccountApiKey(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://api.binance.com").Post("/sapi/v1/broker/subAccountApi").
+		Reply(200).BodyString(`{"subaccountId":"1","apiKey":"vmPUZE6mv9SD5V","secretKey":"NhqPtmdSJYdKjV","canTrade":true,"marginTrade":false,"futuresTrade":true}`)
+	key, err := exg.CreateSubAccountApiKey("1", []string{banexg.MarketLinear}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Secret != "NhqPtmdSJYdKjV" || len(key.Perms) != 3 {
+		t.Errorf("bad api key: %+v", key)
+	}
+	exg.AddAccount("sub1", key.ApiKey, key.Secret)
+	if _, creds, err := exg.GetAccountCreds("sub1"); err != nil || creds.ApiKey != "vmPUZE6mv9SD5V" {
+		t.Errorf("sub account not registered: %v", err)
+	}
+	gock.New("https://api.binance.com").Get("/sapi/v1/broker/subAccountApi").MatchParam("subAccountId", "1").
+		Reply(200).BodyString(`[{"subaccountId":"1","apiKey":"vmPUZE6mv9SD5V","canTrade":true,"marginTrade":true,"futuresTrade":false}]`)
+	keys, err := exg.FetchSubAccountApiKeys("1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Secret != "" || len(keys[0].Perms) != 2 {
+		t.Errorf("bad api keys: %+v", keys)
+	}
+}
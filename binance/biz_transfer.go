@@ -0,0 +1,234 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+	"strings"
+)
+
+// transferWallets market type to wallet name of universal transfer 市场类型对应的万向划转钱包名
+var transferWallets = map[string]string{
+	banexg.MarketSpot:    "MAIN",
+	banexg.MarketMargin:  "MARGIN",
+	banexg.MarketLinear:  "UMFUTURE",
+	banexg.MarketInverse: "CMFUTURE",
+	banexg.MarketOption:  "OPTION",
+	banexg.MarketFunding: "FUNDING",
+}
+
+const walletIsolated = "ISOLATEDMARGIN"
+
+/*
+loadTransferArgs
+set universal transfer type like MAIN_UMFUTURE, margin wallet is isolated when params.symbol is given.
+设置万向划转类型，传入params.symbol时杠杆钱包为逐仓
+*/
+func (e *Binance) loadTransferArgs(params map[string]interface{}, fromMarket, toMarket string) (map[string]interface{}, *errs.Error) {
+	fromWallet, ok1 := transferWallets[fromMarket]
+	toWallet, ok2 := transferWallets[toMarket]
+	if !ok1 || !ok2 || fromMarket == toMarket {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "unsupported transfer: %s -> %s", fromMarket, toMarket)
+	}
+	args, symbol, err := e.loadMarginArgs(params, "isolatedSymbol")
+	if err != nil {
+		return nil, err
+	}
+	if symbol != "" {
+		marId := args["isolatedSymbol"]
+		delete(args, "isolatedSymbol")
+		if fromMarket == banexg.MarketMargin {
+			fromWallet = walletIsolated
+			args["fromSymbol"] = marId
+		} else if toMarket == banexg.MarketMargin {
+			toWallet = walletIsolated
+			args["toSymbol"] = marId
+		} else {
+			return nil, errs.NewMsg(errs.CodeParamInvalid, "symbol is only for isolated margin transfer")
+		}
+	}
+	args["type"] = fromWallet + "_" + toWallet
+	return args, nil
+}
+
+/*
+Transfer
+transfer asset between wallets of market types with universal transfer
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#user-universal-transfer-user_data
+	:param str code: unified currency code
+	:param float amount: amount to transfer
+	:param str fromMarket: MarketSpot/MarketMargin/MarketLinear/MarketInverse/MarketOption/MarketFunding
+	:param str toMarket: same as fromMarket
+	:param str [params.symbol]: unified symbol for isolated margin wallet
+*/
+func (e *Binance) Transfer(code string, amount float64, fromMarket, toMarket string, params map[string]interface{}) (*banexg.Transfer, *errs.Error) {
+	if code == "" || amount <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "code and positive amount are required for Transfer")
+	}
+	args, err := e.loadTransferArgs(params, fromMarket, toMarket)
+	if err != nil {
+		return nil, err
+	}
+	args["asset"] = code
+	args["amount"] = strconv.FormatFloat(amount, 'f', -1, 64)
+	tryNum := e.GetRetryNum("Transfer", 0)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiPostAssetTransfer, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = MarginTranRsp{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	return &banexg.Transfer{
+		ID:        strconv.FormatInt(data.TranId, 10),
+		Code:      code,
+		Amount:    amount,
+		From:      fromMarket,
+		To:        toMarket,
+		Status:    banexg.TxStatusOk,
+		Timestamp: e.MilliSeconds(),
+		Info:      data,
+	}, nil
+}
+
+/*
+FetchTransfers
+fetch history of universal transfers, params.fromMarket and params.toMarket are required
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#query-user-universal-transfer-history-user_data
+	:param str code: unified currency code, all if empty. The api has no currency filter, so rows are filtered
+	    locally, and pages are requested until limit matching rows are found or no more rows
+	:param int since: start time in ms
+	:param int limit: max 100 without code
+	:param str params.fromMarket: market type of source wallet
+	:param str params.toMarket: market type of target wallet
+	:param str [params.symbol]: unified symbol for isolated margin wallet
+	:param int [params.until]: end time in ms
+*/
+func (e *Binance) FetchTransfers(code string, since int64, limit int, params map[string]interface{}) ([]*banexg.Transfer, *errs.Error) {
+	args, err := e.loadTransferQuery(since, params)
+	if err != nil {
+		return nil, err
+	}
+	if code == "" {
+		if limit > 0 {
+			args["size"] = min(limit, 100)
+		}
+		return e.fetchTransferPage(args)
+	}
+	// 接口不支持按币种过滤，逐页请求直到找到limit条
+	return e.fetchTransferPages(args, code, limit)
+}
+
+func (e *Binance) loadTransferQuery(since int64, params map[string]interface{}) (map[string]interface{}, *errs.Error) {
+	args := utils.SafeParams(params)
+	fromMarket := utils.PopMapVal(args, banexg.ParamFromMarket, "")
+	toMarket := utils.PopMapVal(args, banexg.ParamToMarket, "")
+	args, err := e.loadTransferArgs(args, fromMarket, toMarket)
+	if err != nil {
+		return nil, err
+	}
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until > 0 {
+		args["endTime"] = until
+	}
+	if since > 0 {
+		args["startTime"] = since
+	}
+	return args, nil
+}
+
+/*
+fetchTransferPages
+request pages of 100 rows until no more rows, or limit rows of code are found. All codes match if code is empty,
+limit 0 means no limit
+按每页100条请求直到没有更多，或找到limit条code的记录。code为空时匹配所有币种，limit为0时不限制
+*/
+func (e *Binance) fetchTransferPages(args map[string]interface{}, code string, limit int) ([]*banexg.Transfer, *errs.Error) {
+	pageSize := 100
+	args["size"] = pageSize
+	var result = make([]*banexg.Transfer, 0)
+	for page := 1; ; page++ {
+		args["current"] = page
+		res, err := e.fetchTransferPage(args)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range res {
+			if code == "" || it.Code == code {
+				result = append(result, it)
+			}
+		}
+		if len(res) < pageSize || limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (e *Binance) fetchTransferPage(args map[string]interface{}) ([]*banexg.Transfer, *errs.Error) {
+	tryNum := e.GetRetryNum("FetchTransfers", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetAssetTransfer, utils.SafeParams(args), tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parseAssetTransfers(e, rsp.Content)
+}
+
+func parseAssetTransfers(e *Binance, content string) ([]*banexg.Transfer, *errs.Error) {
+	var data = AssetTransferRsp{}
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.Transfer, 0, len(data.Rows))
+	for _, it := range data.Rows {
+		amount, _ := strconv.ParseFloat(it.Amount, 64)
+		from, to := parseTransferType(it.Type)
+		status := strings.ToLower(it.Status)
+		if status == "confirmed" {
+			status = banexg.TxStatusOk
+		}
+		res = append(res, &banexg.Transfer{
+			ID:        strconv.FormatInt(it.TranId, 10),
+			Code:      e.SafeCurrencyCode(it.Asset),
+			Amount:    amount,
+			From:      from,
+			To:        to,
+			Status:    status,
+			Timestamp: it.Timestamp,
+			Info:      it,
+		})
+	}
+	return res, nil
+}
+
+/*
+parseTransferType
+split universal transfer type like MAIN_UMFUTURE into market types, unknown wallets are returned in lower case
+将万向划转类型拆分为市场类型，未知钱包返回小写名称
+*/
+func parseTransferType(tranType string) (string, string) {
+	var walletMarkets = map[string]string{walletIsolated: banexg.MarketMargin}
+	for market, wallet := range transferWallets {
+		walletMarkets[wallet] = market
+	}
+	for wallet, market := range walletMarkets {
+		if rest, ok := strings.CutPrefix(tranType, wallet+"_"); ok {
+			if toMarket, ok := walletMarkets[rest]; ok {
+				return market, toMarket
+			}
+			return market, strings.ToLower(rest)
+		}
+	}
+	from, to, _ := strings.Cut(tranType, "_")
+	return strings.ToLower(from), strings.ToLower(to)
+}
@@ -0,0 +1,98 @@
+package binance
+
+import (
+	"fmt"
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"strings"
+	"testing"
+)
+
+func TestTransfer(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://api.binance.com").Post("/sapi/v1/asset/transfer").
+		Reply(200).JSON(map[string]interface{}{"tranId": 13526853623})
+	res, err := exg.Transfer("USDT", 100, banexg.MarketSpot, banexg.MarketLinear, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != "13526853623" || res.From != banexg.MarketSpot || res.To != banexg.MarketLinear || res.Amount != 100 {
+		t.Errorf("bad transfer: %+v", res)
+	}
+	// small amount should not be sent in scientific notation
+	gock.New("https://api.binance.com").Post("/sapi/v1/asset/transfer").BodyString(`amount=0.00001(&|$)`).
+		Reply(200).JSON(map[string]interface{}{"tranId": 13526853624})
+	if _, err = exg.Transfer("USDT", 0.00001, banexg.MarketSpot, banexg.MarketLinear, nil); err != nil {
+		t.Errorf("small amount transfer fail: %v", err)
+	}
+	if _, err = exg.Transfer("USDT", 100, banexg.MarketSpot, banexg.MarketSpot, nil); err == nil {
+		t.Error("transfer to same wallet should fail")
+	}
+	if _, err = exg.Transfer("USDT", 100, banexg.MarketSpot, banexg.MarketLinear,
+		map[string]interface{}{banexg.ParamSymbol: "BTC/USDT:USDT"}); err == nil {
+		t.Error("isolated transfer with contract symbol should fail")
+	}
+}
+
+func TestFetchTransfers(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	gock.New("https://api.binance.com").Get("/sapi/v1/asset/transfer").MatchParam("type", "UMFUTURE_MAIN").
+		Reply(200).BodyString(`{"total":2,"rows":[
+{"asset":"USDT","amount":"1","type":"UMFUTURE_MAIN","status":"CONFIRMED","tranId":11415955596,"timestamp":1544433328000},
+{"asset":"BNB","amount":"2","type":"UMFUTURE_MAIN","status":"CONFIRMED","tranId":11366865406,"timestamp":1544433328000}]}`)
+	res, err := exg.FetchTransfers("USDT", 0, 10, map[string]interface{}{
+		banexg.ParamFromMarket: banexg.MarketLinear,
+		banexg.ParamToMarket:   banexg.MarketSpot,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].ID != "11415955596" || res[0].Status != "ok" || res[0].From != banexg.MarketLinear || res[0].To != banexg.MarketSpot {
+		t.Errorf("bad transfers: %+v", res)
+	}
+	// rows are filtered by code locally, request next page until limit matched
+	var rows []string
+	for i := 0; i < 100; i++ {
+		asset := "BNB"
+		if i == 50 {
+			asset = "USDT"
+		}
+		rows = append(rows, fmt.Sprintf(`{"asset":"%s","amount":"1","type":"UMFUTURE_MAIN","status":"CONFIRMED","tranId":%d,"timestamp":1544433328000}`, asset, i+1))
+	}
+	gock.New("https://api.binance.com").Get("/sapi/v1/asset/transfer").MatchParam("current", "^1$").
+		MatchParam("size", "^100$").Reply(200).BodyString(`{"total":101,"rows":[` + strings.Join(rows, ",") + `]}`)
+	gock.New("https://api.binance.com").Get("/sapi/v1/asset/transfer").MatchParam("current", "^2$").
+		Reply(200).BodyString(`{"total":101,"rows":[
+{"asset":"USDT","amount":"3","type":"UMFUTURE_MAIN","status":"CONFIRMED","tranId":200,"timestamp":1544433328000}]}`)
+	res, err = exg.FetchTransfers("USDT", 0, 2, map[string]interface{}{
+		banexg.ParamFromMarket: banexg.MarketLinear,
+		banexg.ParamToMarket:   banexg.MarketSpot,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].ID != "51" || res[1].ID != "200" || !gock.IsDone() {
+		t.Errorf("bad paged transfers: %+v", res)
+	}
+}
+
+func TestParseTransferType(t *testing.T) {
+	cases := [][3]string{
+		{"MAIN_UMFUTURE", banexg.MarketSpot, banexg.MarketLinear},
+		{"ISOLATEDMARGIN_MARGIN", banexg.MarketMargin, banexg.MarketMargin},
+		{"FUNDING_CMFUTURE", banexg.MarketFunding, banexg.MarketInverse},
+		{"MAIN_PORTFOLIO_MARGIN", banexg.MarketSpot, "portfolio_margin"},
+	}
+	for _, c := range cases {
+		from, to := parseTransferType(c[0])
+		if from != c[1] || to != c[2] {
+			t.Errorf("parse %s got %s -> %s", c[0], from, to)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package binance
+
+import (
+	"context"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+	"time"
+)
+
+/*
+FetchDepositAddress
+fetch deposit address of currency
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#deposit-address-supporting-network-user_data
+	:param str code: unified currency code
+	:param str network: network of currency, default network if empty
+*/
+func (e *Binance) FetchDepositAddress(code, network string, params map[string]interface{}) (*banexg.DepositAddress, *errs.Error) {
+	if code == "" {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "code is required for FetchDepositAddress")
+	}
+	args := utils.SafeParams(params)
+	args["coin"] = code
+	if network != "" {
+		args["network"] = network
+	}
+	tryNum := e.GetRetryNum("FetchDepositAddress", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetCapitalDepositAddress, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = DepositAddress{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	return &banexg.DepositAddress{
+		Code:    e.SafeCurrencyCode(data.Coin),
+		Network: network,
+		Address: data.Address,
+		Tag:     data.Tag,
+		Info:    data,
+	}, nil
+}
+
+/*
+FetchDeposits
+fetch deposit history
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#deposit-history-supporting-network-user_data
+	:param str code: unified currency code, all if empty
+	:param int since: start time in ms
+	:param int limit: max 1000
+	:param int [params.until]: end time in ms
+*/
+func (e *Binance) FetchDeposits(code string, since int64, limit int, params map[string]interface{}) ([]*banexg.Transaction, *errs.Error) {
+	args := loadTransactionArgs(code, since, limit, params)
+	tryNum := e.GetRetryNum("FetchDeposits", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetCapitalDepositHisrec, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parseDeposits(e, rsp.Content)
+}
+
+/*
+FetchWithdrawals
+fetch withdrawal history
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#withdraw-history-supporting-network-user_data
+	:param str code: unified currency code, all if empty
+	:param int since: start time in ms
+	:param int limit: max 1000
+	:param int [params.until]: end time in ms
+*/
+func (e *Binance) FetchWithdrawals(code string, since int64, limit int, params map[string]interface{}) ([]*banexg.Transaction, *errs.Error) {
+	args := loadTransactionArgs(code, since, limit, params)
+	tryNum := e.GetRetryNum("FetchWithdrawals", 1)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiGetCapitalWithdrawHistory, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	return parseWithdrawals(e, rsp.Content)
+}
+
+func loadTransactionArgs(code string, since int64, limit int, params map[string]interface{}) map[string]interface{} {
+	args := utils.SafeParams(params)
+	if code != "" {
+		args["coin"] = code
+	}
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until > 0 {
+		args["endTime"] = until
+	}
+	if since > 0 {
+		args["startTime"] = since
+	}
+	if limit > 0 {
+		args["limit"] = min(limit, 1000)
+	}
+	return args
+}
+
+/*
+Withdraw
+withdraw currency to address, amount is truncated to network precision and checked against network limits.
+Not retried by default to avoid duplicated withdrawal
+
+	:see: https://binance-docs.github.io/apidocs/spot/en/#withdraw-user_data
+	:param str code: unified currency code
+	:param float amount: amount to withdraw
+	:param str address: target address
+	:param str tag: memo or tag of address, empty if not required
+	:param str network: network of currency, default network if empty
+	:param str [params.clientOrderId]: client id for withdraw
+*/
+func (e *Binance) Withdraw(code string, amount float64, address, tag, network string, params map[string]interface{}) (*banexg.Transaction, *errs.Error) {
+	if code == "" || address == "" || amount <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "code, address and positive amount are required for Withdraw")
+	}
+	chain, amount, err := e.checkWithdraw(code, amount, network)
+	if err != nil {
+		return nil, err
+	}
+	args := utils.SafeParams(params)
+	clientId := utils.PopMapVal(args, banexg.ParamClientOrderId, "")
+	if clientId != "" {
+		args["withdrawOrderId"] = clientId
+	}
+	args["coin"] = code
+	args["address"] = address
+	args["amount"] = strconv.FormatFloat(amount, 'f', -1, 64)
+	if tag != "" {
+		args["addressTag"] = tag
+	}
+	if network != "" {
+		args["network"] = network
+	}
+	// not retried by default, a timeout request may have succeeded
+	tryNum := e.GetRetryNum("Withdraw", 0)
+	rsp := e.RequestApiRetry(context.Background(), MethodSapiPostCapitalWithdrawApply, args, tryNum)
+	if rsp.Error != nil {
+		return nil, rsp.Error
+	}
+	var data = WithdrawRsp{}
+	err_ := utils.UnmarshalString(rsp.Content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	return &banexg.Transaction{
+		ID:        data.Id,
+		Type:      banexg.TxTypeWithdrawal,
+		Code:      code,
+		Amount:    amount,
+		Network:   chain.Network,
+		Address:   address,
+		Tag:       tag,
+		Status:    banexg.TxStatusPending,
+		Fee:       chain.Fee,
+		Timestamp: e.MilliSeconds(),
+		Info:      data,
+	}, nil
+}
+
+/*
+checkWithdraw
+validate withdraw amount against ChainNetwork of currency, return the chain and amount truncated to chain precision.
+Currencies are fetched when networks are not loaded.
+根据币种的网络检查提现数量，返回网络和按网络精度截断后的数量。网络未加载时请求币种信息。
+*/
+func (e *Binance) checkWithdraw(code string, amount float64, network string) (*banexg.ChainNetwork, float64, *errs.Error) {
+	curr, ok := e.CurrenciesByCode[code]
+	if !ok || len(curr.Networks) == 0 {
+		currs, err := e.FetchCurrencies(nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		curr, ok = currs[code]
+	}
+	if !ok {
+		return nil, 0, errs.NewMsg(errs.CodeParamInvalid, "currency %s not found", code)
+	}
+	if len(curr.Networks) == 0 {
+		return nil, 0, errs.NewMsg(errs.CodeInvalidData, "no network info for %s", code)
+	}
+	var chain *banexg.ChainNetwork
+	for _, net := range curr.Networks {
+		if network == "" {
+			if info, ok := net.Info.(*BnbNetwork); ok && info.IsDefault {
+				chain = net
+				break
+			}
+		} else if net.Network == network {
+			chain = net
+			break
+		}
+	}
+	if chain == nil {
+		if network == "" {
+			return nil, 0, errs.NewMsg(errs.CodeParamRequired, "no default network for %s, network is required", code)
+		}
+		return nil, 0, errs.NewMsg(errs.CodeParamInvalid, "network %s not found for %s", network, code)
+	}
+	if !chain.Withdraw {
+		return nil, 0, errs.NewMsg(errs.CodeParamInvalid, "withdraw of %s is disabled on %s", code, chain.Network)
+	}
+	if chain.Precision > 0 {
+		res, err_ := utils.PrecFloat64(amount, chain.Precision, false, utils.PrecModeDecimalPlace)
+		if err_ != nil {
+			return nil, 0, errs.New(errs.CodePrecDecFail, err_)
+		}
+		amount = res
+	}
+	if amount <= 0 {
+		return nil, 0, errs.NewMsg(errs.CodeParamInvalid, "withdraw amount of %s is 0 after truncated to %v decimals",
+			code, chain.Precision)
+	}
+	if chain.Limits != nil && chain.Limits.Withdraw != nil {
+		lim := chain.Limits.Withdraw
+		if lim.Min > 0 && amount < lim.Min || lim.Max > 0 && amount > lim.Max {
+			return nil, 0, errs.NewMsg(errs.CodeParamInvalid, "withdraw amount %v of %s should in [%v, %v] on %s",
+				amount, code, lim.Min, lim.Max, chain.Network)
+		}
+	}
+	return chain, amount, nil
+}
+
+func parseDeposits(e *Binance, content string) ([]*banexg.Transaction, *errs.Error) {
+	var data = make([]*DepositRecord, 0)
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.Transaction, 0, len(data))
+	for _, it := range data {
+		amount, _ := strconv.ParseFloat(it.Amount, 64)
+		// 0 pending, 6 credited but cannot withdraw, 7 wrong deposit, 8 waiting user confirm, 1 success, 2 rejected
+		status := banexg.TxStatusPending
+		if it.Status == 1 || it.Status == 6 {
+			status = banexg.TxStatusOk
+		} else if it.Status == 2 || it.Status == 7 {
+			status = banexg.TxStatusFailed
+		}
+		res = append(res, &banexg.Transaction{
+			ID:        it.Id,
+			TxID:      it.TxId,
+			Type:      banexg.TxTypeDeposit,
+			Code:      e.SafeCurrencyCode(it.Coin),
+			Amount:    amount,
+			Network:   it.Network,
+			Address:   it.Address,
+			Tag:       it.AddressTag,
+			Status:    status,
+			Timestamp: it.InsertTime,
+			Info:      it,
+		})
+	}
+	return res, nil
+}
+
+func parseWithdrawals(e *Binance, content string) ([]*banexg.Transaction, *errs.Error) {
+	var data = make([]*WithdrawRecord, 0)
+	err_ := utils.UnmarshalString(content, &data, utils.JsonNumDefault)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	var res = make([]*banexg.Transaction, 0, len(data))
+	for _, it := range data {
+		amount, _ := strconv.ParseFloat(it.Amount, 64)
+		fee, _ := strconv.ParseFloat(it.TransactionFee, 64)
+		var stamp int64
+		if t, err := time.Parse(time.DateTime, it.ApplyTime); err == nil {
+			stamp = t.UnixMilli()
+		}
+		// 0 email sent, 1 cancelled, 2 awaiting approval, 3 rejected, 4 processing, 5 failure, 6 completed
+		status := banexg.TxStatusPending
+		switch it.Status {
+		case 1:
+			status = banexg.TxStatusCanceled
+		case 3, 5:
+			status = banexg.TxStatusFailed
+		case 6:
+			status = banexg.TxStatusOk
+		}
+		res = append(res, &banexg.Transaction{
+			ID:        it.Id,
+			TxID:      it.TxId,
+			Type:      banexg.TxTypeWithdrawal,
+			Code:      e.SafeCurrencyCode(it.Coin),
+			Amount:    amount,
+			Network:   it.Network,
+			Address:   it.Address,
+			Tag:       it.AddressTag,
+			Status:    status,
+			Fee:       fee,
+			Timestamp: stamp,
+			Info:      it,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,78 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/h2non/gock"
+	"testing"
+)
+
+func TestWithdraw(t *testing.T) {
+	defer gock.Off()
+	gock.DisableNetworking()
+	exg := getOfflineLinear(t)
+	gock.InterceptClient(exg.HttpClient)
+	exg.CurrenciesByCode = banexg.CurrencyMap{
+		"USDT": &banexg.Currency{ID: "USDT", Code: "USDT", Networks: []*banexg.ChainNetwork{
+			{ID: "ETH", Network: "ETH", Withdraw: true, Fee: 5, Precision: 2, Info: &BnbNetwork{IsDefault: true},
+				Limits: &banexg.CodeLimits{Withdraw: &banexg.LimitRange{Min: 10, Max: 1000}}},
+			{ID: "TRX", Network: "TRX", Withdraw: false, Fee: 1, Info: &BnbNetwork{}},
+		}},
+		"BTC": &banexg.Currency{ID: "BTC", Code: "BTC", Networks: []*banexg.ChainNetwork{
+			{ID: "BTC", Network: "BTC", Withdraw: true, Info: &BnbNetwork{}},
+		}},
+	}
+	if _, err := exg.Withdraw("BTC", 1, "bc1abc", "", "", nil); err == nil {
+		t.Error("withdraw without default network should fail")
+	}
+	// currency without networks is fetched
+	gock.New("https://api.binance.com").Get("/sapi/v1/capital/config/getall").Reply(200).BodyString("[]")
+	if _, err := exg.Withdraw("DOGE", 100, "Dabc", "", "", nil); err == nil || !gock.IsDone() {
+		t.Errorf("withdraw of unknown currency should fail after fetched: %v", err)
+	}
+	if _, err := exg.Withdraw("USDT", 5, "0xabc", "", "", nil); err == nil {
+		t.Error("amount below min should fail")
+	}
+	if _, err := exg.Withdraw("USDT", 50, "Txyz", "", "TRX", nil); err == nil {
+		t.Error("disabled network should fail")
+	}
+	if _, err := exg.Withdraw("USDT", 50, "0xabc", "", "BSC", nil); err == nil {
+		t.Error("unknown network should fail")
+	}
+	gock.New("https://api.binance.com").Post("/sapi/v1/capital/withdraw/apply").BodyString(`amount=50.12(&|$)`).
+		Reply(200).JSON(map[string]interface{}{"id": "7213fea8e94b4a5593d507237e5a555b"})
+	res, err := exg.Withdraw("USDT", 50.129, "0xabc", "", "ETH", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != "7213fea8e94b4a5593d507237e5a555b" || res.Fee != 5 || res.Amount != 50.12 || res.Status != banexg.TxStatusPending || res.Type != banexg.TxTypeWithdrawal {
+		t.Errorf("bad withdraw: %+v", res)
+	}
+}
+
+func TestParseDepositsWithdrawals(t *testing.T) {
+	exg := getOfflineLinear(t)
+	deposits := `[{"id":"769800519366885376","amount":"0.001","coin":"BNB","network":"BNB","status":1,"address":"bnb136ns6lfw4zs5hg4n85vdthaad7hq5m4gtkgf23",
+"addressTag":"101764890","txId":"98A3EA560C6B3336D348B6C83F0F95ECE4F1F5919E94BD006E5BF3BF264FACFC","insertTime":1661493146000,"transferType":0,"confirmTimes":"1/1","unlockConfirm":0,"walletType":0},
+{"id":"769754833590042625","amount":"0.5","coin":"IOTA","network":"IOTA","status":0,"address":"x","addressTag":"","txId":"y","insertTime":1661482253000}]`
+	res, err := parseDeposits(exg, deposits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Status != banexg.TxStatusOk || res[0].Tag != "101764890" || res[0].Amount != 0.001 || res[1].Status != banexg.TxStatusPending {
+		t.Errorf("bad deposits: %+v", res)
+	}
+	withdraws := `[{"id":"b6ae22b3aa844210a7041aee7589627c","amount":"8.91000000","transactionFee":"0.004","coin":"USDT","status":6,
+"address":"0x94df8b352de7f46f64b01d3666bf6e936e44ce60","txId":"0xb5ef8c13b968a406cc62a93a8bd80f9e9a906ef1b3fcf20a2e48573c17659268",
+"applyTime":"2019-10-12 11:12:02","network":"ETH","transferType":0},
+{"id":"156ec387f49b41df8724fa744fa82719","amount":"0.00150000","transactionFee":"0.00050000","coin":"BTC","status":1,"address":"x","txId":"","applyTime":"2019-09-24 12:43:45","network":"BTC"}]`
+	res, err = parseWithdrawals(exg, withdraws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Status != banexg.TxStatusOk || res[0].Fee != 0.004 || res[0].Timestamp != 1570878722000 {
+		t.Errorf("bad withdrawal: %+v", res[0])
+	}
+	if res[1].Status != banexg.TxStatusCanceled || res[1].Code != "BTC" {
+		t.Errorf("bad withdrawal: %+v", res[1])
+	}
+}
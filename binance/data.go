@@ -23,6 +23,9 @@ const (
 	HostFApiData      = "fapiData"
 	HostPApi          = "papi"
 	WssApi            = "ws"
+	WssFApi           = "wsf"
+	WssDApi           = "wsd"
+	WssPApi           = "wsp"
 )
 
 const (
@@ -292,6 +295,7 @@ const (
 	MethodSapiPostMarginTransfer                                      = "sapiPostMarginTransfer"
 	MethodSapiPostMarginLoan                                          = "sapiPostMarginLoan"
 	MethodSapiPostMarginRepay                                         = "sapiPostMarginRepay"
+	MethodSapiPostMarginBorrowRepay                                   = "sapiPostMarginBorrowRepay"
 	MethodSapiPostMarginOrder                                         = "sapiPostMarginOrder"
 	MethodSapiPostMarginOrderOco                                      = "sapiPostMarginOrderOco"
 	MethodSapiPostMarginDust                                          = "sapiPostMarginDust"
@@ -700,6 +704,8 @@ const (
 	MethodPapiPostListenKey                                           = "papiPostListenKey"
 	MethodPapiPostAssetCollection                                     = "papiPostAssetCollection"
 	MethodPapiPutListenKey                                            = "papiPutListenKey"
+	MethodPapiPutUmOrder                                              = "papiPutUmOrder"
+	MethodPapiPutCmOrder                                              = "papiPutCmOrder"
 	MethodPapiDeleteUmOrder                                           = "papiDeleteUmOrder"
 	MethodPapiDeleteUmConditionalOrder                                = "papiDeleteUmConditionalOrder"
 	MethodPapiDeleteUmAllOpenOrders                                   = "papiDeleteUmAllOpenOrders"
@@ -33,6 +33,8 @@ func New(Options map[string]interface{}) (*Binance, *errs.Error) {
 					banexg.MarketInverse: "wss://dstream.binancefuture.com/ws",
 					banexg.MarketOption:  "wss://nbstream.binancefuture.com/eoptions",
 					WssApi:               "wss://testnet.binance.vision/ws-api/v3",
+					WssFApi:              "wss://testnet.binancefuture.com/ws-fapi/v1",
+					WssDApi:              "wss://testnet.binancefuture.com/ws-dapi/v1",
 				},
 				Prod: map[string]string{
 					HostSApi:             "https://api.binance.com/sapi/v1",
@@ -60,6 +62,9 @@ func New(Options map[string]interface{}) (*Binance, *errs.Error) {
 					banexg.MarketInverse: "wss://dstream.binance.com/ws",
 					banexg.MarketOption:  "wss://nbstream.binance.com/eoptions",
 					WssApi:               "wss://ws-api.binance.com:443/ws-api/v3",
+					WssFApi:              "wss://ws-fapi.binance.com/ws-fapi/v1",
+					WssDApi:              "wss://ws-dapi.binance.com/ws-dapi/v1",
+					WssPApi:              "wss://fstream.binance.com/pm/ws",
 				},
 				Www: "https://www.binance.com",
 				Doc: []string{
@@ -386,6 +391,7 @@ func New(Options map[string]interface{}) (*Binance, *errs.Error) {
 				MethodSapiPostMarginTransfer:                                      {Path: "margin/transfer", Host: HostSApi, Method: "POST", Cost: 4.0002},
 				MethodSapiPostMarginLoan:                                          {Path: "margin/loan", Host: HostSApi, Method: "POST", Cost: 20.001},
 				MethodSapiPostMarginRepay:                                         {Path: "margin/repay", Host: HostSApi, Method: "POST", Cost: 20.001},
+				MethodSapiPostMarginBorrowRepay:                                   {Path: "margin/borrow-repay", Host: HostSApi, Method: "POST", Cost: 20.001},
 				MethodSapiPostMarginOrder:                                         {Path: "margin/order", Host: HostSApi, Method: "POST", Cost: 0.040002},
 				MethodSapiPostMarginOrderOco:                                      {Path: "margin/order/oco", Host: HostSApi, Method: "POST", Cost: 0.040002},
 				MethodSapiPostMarginDust:                                          {Path: "margin/dust", Host: HostSApi, Method: "POST", Cost: 20.001},
@@ -794,6 +800,8 @@ func New(Options map[string]interface{}) (*Binance, *errs.Error) {
 				MethodPapiPostListenKey:                                           {Path: "listenKey", Host: HostPApi, Method: "POST", Cost: 1},
 				MethodPapiPostAssetCollection:                                     {Path: "asset-collection", Host: HostPApi, Method: "POST", Cost: 3},
 				MethodPapiPutListenKey:                                            {Path: "listenKey", Host: HostPApi, Method: "PUT", Cost: 1},
+				MethodPapiPutUmOrder:                                              {Path: "um/order", Host: HostPApi, Method: "PUT", Cost: 1},
+				MethodPapiPutCmOrder:                                              {Path: "cm/order", Host: HostPApi, Method: "PUT", Cost: 1},
 				MethodPapiDeleteUmOrder:                                           {Path: "um/order", Host: HostPApi, Method: "DELETE", Cost: 1},
 				MethodPapiDeleteUmConditionalOrder:                                {Path: "um/conditional/order", Host: HostPApi, Method: "DELETE", Cost: 1},
 				MethodPapiDeleteUmAllOpenOrders:                                   {Path: "um/allOpenOrders", Host: HostPApi, Method: "DELETE", Cost: 1},
@@ -809,36 +817,104 @@ func New(Options map[string]interface{}) (*Binance, *errs.Error) {
 			},
 			Has: map[string]map[string]int{
 				"": {
-					banexg.ApiFetchTicker:           banexg.HasOk,
-					banexg.ApiFetchTickers:          banexg.HasOk,
-					banexg.ApiFetchTickerPrice:      banexg.HasOk,
-					banexg.ApiLoadLeverageBrackets:  banexg.HasOk,
-					banexg.ApiGetLeverage:           banexg.HasOk,
-					banexg.ApiFetchOHLCV:            banexg.HasOk,
-					banexg.ApiFetchOrderBook:        banexg.HasOk,
-					banexg.ApiFetchOrder:            banexg.HasOk,
-					banexg.ApiFetchOrders:           banexg.HasOk,
-					banexg.ApiFetchBalance:          banexg.HasOk,
-					banexg.ApiFetchAccountPositions: banexg.HasOk,
-					banexg.ApiFetchPositions:        banexg.HasOk,
-					banexg.ApiFetchOpenOrders:       banexg.HasOk,
-					banexg.ApiCreateOrder:           banexg.HasOk,
-					banexg.ApiEditOrder:             banexg.HasOk,
-					banexg.ApiCancelOrder:           banexg.HasOk,
-					banexg.ApiSetLeverage:           banexg.HasOk,
-					banexg.ApiCalcMaintMargin:       banexg.HasOk,
-					banexg.ApiWatchOrderBooks:       banexg.HasOk,
-					banexg.ApiUnWatchOrderBooks:     banexg.HasOk,
-					banexg.ApiWatchOHLCVs:           banexg.HasOk,
-					banexg.ApiUnWatchOHLCVs:         banexg.HasOk,
-					banexg.ApiWatchMarkPrices:       banexg.HasOk,
-					banexg.ApiUnWatchMarkPrices:     banexg.HasOk,
-					banexg.ApiWatchTrades:           banexg.HasOk,
-					banexg.ApiUnWatchTrades:         banexg.HasOk,
-					banexg.ApiWatchMyTrades:         banexg.HasOk,
-					banexg.ApiWatchBalance:          banexg.HasOk,
-					banexg.ApiWatchPositions:        banexg.HasOk,
-					banexg.ApiWatchAccountConfig:    banexg.HasOk,
+					banexg.ApiFetchTicker:                banexg.HasOk,
+					banexg.ApiFetchTickers:               banexg.HasOk,
+					banexg.ApiFetchTickerPrice:           banexg.HasOk,
+					banexg.ApiLoadLeverageBrackets:       banexg.HasOk,
+					banexg.ApiGetLeverage:                banexg.HasOk,
+					banexg.ApiFetchOHLCV:                 banexg.HasOk,
+					banexg.ApiFetchOrderBook:             banexg.HasOk,
+					banexg.ApiFetchOrder:                 banexg.HasOk,
+					banexg.ApiFetchOrders:                banexg.HasOk,
+					banexg.ApiFetchBalance:               banexg.HasOk,
+					banexg.ApiFetchAccountPositions:      banexg.HasOk,
+					banexg.ApiFetchPositions:             banexg.HasOk,
+					banexg.ApiFetchOpenOrders:            banexg.HasOk,
+					banexg.ApiFetchMyTrades:              banexg.HasOk,
+					banexg.ApiCreateOrder:                banexg.HasOk,
+					banexg.ApiEditOrder:                  banexg.HasOk,
+					banexg.ApiCancelOrder:                banexg.HasOk,
+					banexg.ApiCreateOrders:               banexg.HasOk,
+					banexg.ApiEditOrders:                 banexg.HasOk,
+					banexg.ApiCancelOrders:               banexg.HasOk,
+					banexg.ApiCancelAllOrders:            banexg.HasOk,
+					banexg.ApiSetCancelAllCountdown:      banexg.HasOk,
+					banexg.ApiCreateOCO:                  banexg.HasOk,
+					banexg.ApiValidateOrder:              banexg.HasOk,
+					banexg.ApiCreateBracketOrder:         banexg.HasEmulated,
+					banexg.ApiSetLeverage:                banexg.HasOk,
+					banexg.ApiGetPositionMode:            banexg.HasOk,
+					banexg.ApiSetPositionMode:            banexg.HasOk,
+					banexg.ApiGetMarginMode:              banexg.HasOk,
+					banexg.ApiSetMarginMode:              banexg.HasOk,
+					banexg.ApiGetMultiAssetsMode:         banexg.HasOk,
+					banexg.ApiSetMultiAssetsMode:         banexg.HasOk,
+					banexg.ApiModifyPositionMargin:       banexg.HasOk,
+					banexg.ApiFetchPositionMarginHistory: banexg.HasOk,
+					banexg.ApiBorrow:                     banexg.HasOk,
+					banexg.ApiRepay:                      banexg.HasOk,
+					banexg.ApiFetchBorrowInterest:        banexg.HasOk,
+					banexg.ApiFetchMaxBorrowable:         banexg.HasOk,
+					banexg.ApiFetchBorrowRates:           banexg.HasOk,
+					banexg.ApiTransfer:                   banexg.HasOk,
+					banexg.ApiFetchTransfers:             banexg.HasOk,
+					banexg.ApiFetchDepositAddress:        banexg.HasOk,
+					banexg.ApiFetchDeposits:              banexg.HasOk,
+					banexg.ApiFetchWithdrawals:           banexg.HasOk,
+					banexg.ApiWithdraw:                   banexg.HasOk,
+					banexg.ApiFetchSubAccounts:           banexg.HasOk,
+					banexg.ApiFetchSubAccountBalance:     banexg.HasOk,
+					banexg.ApiSubAccountTransfer:         banexg.HasOk,
+					banexg.ApiCreateSubAccountApiKey:     banexg.HasOk,
+					banexg.ApiFetchConvertQuote:          banexg.HasOk,
+					banexg.ApiAcceptConvertQuote:         banexg.HasOk,
+					banexg.ApiFetchConvertHistory:        banexg.HasOk,
+					banexg.ApiFetchLedger:                banexg.HasOk,
+					banexg.ApiFetchLiquidations:          banexg.HasOk,
+					banexg.ApiFetchADLQuantile:           banexg.HasOk,
+					banexg.ApiConvertDust:                banexg.HasOk,
+					banexg.ApiFetchTradingFees:           banexg.HasOk,
+					banexg.ApiFetchSubAccountApiKeys:     banexg.HasOk,
+					banexg.ApiCalcMaintMargin:            banexg.HasOk,
+					banexg.ApiWatchOrderBooks:            banexg.HasOk,
+					banexg.ApiUnWatchOrderBooks:          banexg.HasOk,
+					banexg.ApiWatchOHLCVs:                banexg.HasOk,
+					banexg.ApiUnWatchOHLCVs:              banexg.HasOk,
+					banexg.ApiWatchMarkPrices:            banexg.HasOk,
+					banexg.ApiUnWatchMarkPrices:          banexg.HasOk,
+					banexg.ApiWatchTickers:               banexg.HasOk,
+					banexg.ApiUnWatchTickers:             banexg.HasOk,
+					banexg.ApiWatchBookTickers:           banexg.HasOk,
+					banexg.ApiUnWatchBookTickers:         banexg.HasOk,
+					banexg.ApiWatchTrades:                banexg.HasOk,
+					banexg.ApiUnWatchTrades:              banexg.HasOk,
+					banexg.ApiWatchMyTrades:              banexg.HasOk,
+					banexg.ApiWatchBalance:               banexg.HasOk,
+					banexg.ApiWatchPositions:             banexg.HasOk,
+					banexg.ApiWatchAccountConfig:         banexg.HasOk,
+					banexg.ApiWatchMarginCalls:           banexg.HasOk,
+					banexg.ApiWatchLiquidations:          banexg.HasOk,
+					banexg.ApiUnWatchLiquidations:        banexg.HasOk,
+				},
+			},
+			Pages: map[string]map[string]*banexg.PageConf{
+				"": {
+					banexg.ApiFetchOHLCV:              {DefLimit: 500, MaxLimit: 1000},
+					banexg.ApiFetchOrders:             {DefLimit: 500, MaxLimit: 1000, MaxRange: 24 * 3600 * 1000},
+					banexg.ApiFetchMyTrades:           {DefLimit: 500, MaxLimit: 1000, MaxRange: 24 * 3600 * 1000},
+					banexg.ApiFetchFundingRateHistory: {DefLimit: 100, MaxLimit: 1000},
+				},
+				banexg.MarketLinear: {
+					banexg.ApiFetchOHLCV:         {DefLimit: 500, MaxLimit: 1500},
+					banexg.ApiFetchOrders:        {DefLimit: 500, MaxLimit: 1000, MaxRange: 7 * 24 * 3600 * 1000},
+					banexg.ApiFetchMyTrades:      {DefLimit: 500, MaxLimit: 1000, MaxRange: 7 * 24 * 3600 * 1000},
+					banexg.ApiFetchIncomeHistory: {DefLimit: 100, MaxLimit: 1000},
+				},
+				banexg.MarketInverse: {
+					banexg.ApiFetchOHLCV:         {DefLimit: 500, MaxLimit: 1500},
+					banexg.ApiFetchOrders:        {DefLimit: 50, MaxLimit: 100, MaxRange: 7 * 24 * 3600 * 1000},
+					banexg.ApiFetchMyTrades:      {DefLimit: 50, MaxLimit: 1000, MaxRange: 7 * 24 * 3600 * 1000},
+					banexg.ApiFetchIncomeHistory: {DefLimit: 100, MaxLimit: 1000},
 				},
 			},
 			CredKeys: map[string]bool{"ApiKey": true, "Secret": true},
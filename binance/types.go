@@ -203,6 +203,27 @@ type MarginCrossBalances struct {
 	UserAssets                 []*SpotAsset `json:"userAssets"`
 }
 
+/*
+PmBalance
+balance of asset in portfolio margin account
+统一账户的币种余额
+*/
+type PmBalance struct {
+	Asset               string `json:"asset"`
+	TotalWalletBalance  string `json:"totalWalletBalance"`
+	CrossMarginAsset    string `json:"crossMarginAsset"`
+	CrossMarginBorrowed string `json:"crossMarginBorrowed"`
+	CrossMarginFree     string `json:"crossMarginFree"`
+	CrossMarginInterest string `json:"crossMarginInterest"`
+	CrossMarginLocked   string `json:"crossMarginLocked"`
+	UmWalletBalance     string `json:"umWalletBalance"`
+	UmUnrealizedPNL     string `json:"umUnrealizedPNL"`
+	CmWalletBalance     string `json:"cmWalletBalance"`
+	CmUnrealizedPNL     string `json:"cmUnrealizedPNL"`
+	UpdateTime          int64  `json:"updateTime"`
+	NegativeBalance     string `json:"negativeBalance"`
+}
+
 /*
 IsolatedBalances
 Binance Margin Isolated Balance
@@ -524,6 +545,59 @@ type InverseOrder struct {
 	CumBase string `json:"cumBase"` // 成交金额(标的数量)
 }
 
+/*
+ForceLiquidationRec 杠杆账户强平记录
+*/
+type ForceLiquidationRec struct {
+	AvgPrice    string `json:"avgPrice"`
+	ExecutedQty string `json:"executedQty"`
+	OrderId     int64  `json:"orderId"`
+	Price       string `json:"price"`
+	Qty         string `json:"qty"`
+	Side        string `json:"side"`
+	Symbol      string `json:"symbol"`
+	TimeInForce string `json:"timeInForce"`
+	IsIsolated  bool   `json:"isIsolated"`
+	UpdatedTime int64  `json:"updatedTime"`
+}
+
+type ForceLiquidationRsp struct {
+	Rows  []*ForceLiquidationRec `json:"rows"`
+	Total int                    `json:"total"`
+}
+
+/*
+AdlQuantile 合约自动减仓队列位置
+*/
+type AdlQuantile struct {
+	Symbol      string         `json:"symbol"`
+	AdlQuantile map[string]int `json:"adlQuantile"` // LONG/SHORT/BOTH/HEDGE
+}
+
+/*
+PmConditionalOrder 统一账户条件单
+*/
+type PmConditionalOrder struct {
+	NewClientStrategyId string `json:"newClientStrategyId"`
+	StrategyId          int64  `json:"strategyId"`
+	StrategyStatus      string `json:"strategyStatus"`
+	StrategyType        string `json:"strategyType"`
+	OrigQty             string `json:"origQty"`
+	Price               string `json:"price"`
+	ReduceOnly          bool   `json:"reduceOnly"`
+	Side                string `json:"side"`
+	PositionSide        string `json:"positionSide"`
+	StopPrice           string `json:"stopPrice"`
+	Symbol              string `json:"symbol"`
+	TimeInForce         string `json:"timeInForce"`
+	ActivatePrice       string `json:"activatePrice"`
+	PriceRate           string `json:"priceRate"`
+	BookTime            int64  `json:"bookTime"`
+	UpdateTime          int64  `json:"updateTime"`
+	WorkingType         string `json:"workingType"`
+	PriceProtect        bool   `json:"priceProtect"`
+}
+
 /*
 OptionOrder 期权订单
 */
@@ -786,3 +860,308 @@ type LastPrice struct {
 	Time   int64  `json:"time,omitempty"` // linear/inverse
 	PS     string `json:"ps,omitempty"`   //inverse
 }
+
+type PositionMarginRsp struct {
+	Amount float64 `json:"amount"`
+	Code   int     `json:"code"`
+	Msg    string  `json:"msg"`
+	Type   int     `json:"type"`
+}
+
+type PositionMarginItem struct {
+	Symbol       string `json:"symbol"`
+	Type         int    `json:"type"` // 1: add, 2: reduce
+	DeltaType    string `json:"deltaType"`
+	Amount       string `json:"amount"`
+	Asset        string `json:"asset"`
+	Time         int64  `json:"time"`
+	PositionSide string `json:"positionSide"`
+}
+
+type MarginTranRsp struct {
+	TranId int64 `json:"tranId"`
+}
+
+type AssetTransfer struct {
+	Asset      string `json:"asset"`
+	Amount     string `json:"amount"`
+	Type       string `json:"type"`
+	Status     string `json:"status"`
+	TranId     int64  `json:"tranId"`
+	Timestamp  int64  `json:"timestamp"`
+	FromSymbol string `json:"fromSymbol"`
+	ToSymbol   string `json:"toSymbol"`
+}
+
+type AssetTransferRsp struct {
+	Rows  []*AssetTransfer `json:"rows"`
+	Total int              `json:"total"`
+}
+
+type DepositAddress struct {
+	Address string `json:"address"`
+	Coin    string `json:"coin"`
+	Tag     string `json:"tag"`
+	Url     string `json:"url"`
+}
+
+type DepositRecord struct {
+	Id            string `json:"id"`
+	Amount        string `json:"amount"`
+	Coin          string `json:"coin"`
+	Network       string `json:"network"`
+	Status        int    `json:"status"`
+	Address       string `json:"address"`
+	AddressTag    string `json:"addressTag"`
+	TxId          string `json:"txId"`
+	InsertTime    int64  `json:"insertTime"`
+	TransferType  int    `json:"transferType"`
+	ConfirmTimes  string `json:"confirmTimes"`
+	UnlockConfirm int    `json:"unlockConfirm"`
+	WalletType    int    `json:"walletType"`
+}
+
+type WithdrawRecord struct {
+	Id              string `json:"id"`
+	Amount          string `json:"amount"`
+	TransactionFee  string `json:"transactionFee"`
+	Coin            string `json:"coin"`
+	Status          int    `json:"status"`
+	Address         string `json:"address"`
+	AddressTag      string `json:"addressTag"`
+	TxId            string `json:"txId"`
+	ApplyTime       string `json:"applyTime"` // 2019-10-12 11:12:02 in UTC
+	Network         string `json:"network"`
+	TransferType    int    `json:"transferType"`
+	WithdrawOrderId string `json:"withdrawOrderId"`
+	Info            string `json:"info"`
+	ConfirmNo       int    `json:"confirmNo"`
+	WalletType      int    `json:"walletType"`
+	CompleteTime    string `json:"completeTime"`
+}
+
+type WithdrawRsp struct {
+	Id string `json:"id"`
+}
+
+type SubAccountInfo struct {
+	Email                       string `json:"email"`
+	IsFreeze                    bool   `json:"isFreeze"`
+	CreateTime                  int64  `json:"createTime"`
+	IsManagedSubAccount         bool   `json:"isManagedSubAccount"`
+	IsAssetManagementSubAccount bool   `json:"isAssetManagementSubAccount"`
+}
+
+type SubAccountListRsp struct {
+	SubAccounts []*SubAccountInfo `json:"subAccounts"`
+}
+
+type BrokerSubAccount struct {
+	SubaccountId    string `json:"subaccountId"`
+	Email           string `json:"email"`
+	Tag             string `json:"tag"`
+	MakerCommission string `json:"makerCommission"`
+	TakerCommission string `json:"takerCommission"`
+	CreateTime      int64  `json:"createTime"`
+}
+
+type SubAccAsset struct {
+	Asset  string  `json:"asset"`
+	Free   float64 `json:"free"`
+	Locked float64 `json:"locked"`
+}
+
+type SubAccAssetsRsp struct {
+	Balances []*SubAccAsset `json:"balances"`
+}
+
+type SubFuturesAsset struct {
+	Asset                  string `json:"asset"`
+	InitialMargin          string `json:"initialMargin"`
+	MaintenanceMargin      string `json:"maintenanceMargin"`
+	MarginBalance          string `json:"marginBalance"`
+	MaxWithdrawAmount      string `json:"maxWithdrawAmount"`
+	OpenOrderInitialMargin string `json:"openOrderInitialMargin"`
+	PositionInitialMargin  string `json:"positionInitialMargin"`
+	UnrealizedProfit       string `json:"unrealizedProfit"`
+	WalletBalance          string `json:"walletBalance"`
+}
+
+type SubFuturesAccount struct {
+	Email  string             `json:"email"`
+	Assets []*SubFuturesAsset `json:"assets"`
+}
+
+type SubFuturesAccountRsp struct {
+	FutureAccountResp   *SubFuturesAccount `json:"futureAccountResp"`
+	DeliveryAccountResp *SubFuturesAccount `json:"deliveryAccountResp"`
+}
+
+type SubTransferRsp struct {
+	TranId       int64  `json:"tranId"` // normal sub-account
+	TxnId        string `json:"txnId"`  // broker sub-account
+	ClientTranId string `json:"clientTranId"`
+}
+
+type BrokerSubApiKey struct {
+	SubaccountId string `json:"subaccountId"`
+	ApiKey       string `json:"apiKey"`
+	SecretKey    string `json:"secretKey"`
+	CanTrade     bool   `json:"canTrade"`
+	MarginTrade  bool   `json:"marginTrade"`
+	FuturesTrade bool   `json:"futuresTrade"`
+}
+
+type SpotTradeFee struct {
+	Symbol          string `json:"symbol"`
+	MakerCommission string `json:"makerCommission"`
+	TakerCommission string `json:"takerCommission"`
+}
+
+type CommissionRate struct {
+	Symbol              string `json:"symbol"`
+	MakerCommissionRate string `json:"makerCommissionRate"`
+	TakerCommissionRate string `json:"takerCommissionRate"`
+}
+
+type ConvertQuoteRsp struct {
+	QuoteId        string `json:"quoteId"`
+	Ratio          string `json:"ratio"`
+	InverseRatio   string `json:"inverseRatio"`
+	ValidTimestamp int64  `json:"validTimestamp"`
+	ToAmount       string `json:"toAmount"`
+	FromAmount     string `json:"fromAmount"`
+}
+
+type ConvertAcceptRsp struct {
+	OrderId     string `json:"orderId"`
+	CreateTime  int64  `json:"createTime"`
+	OrderStatus string `json:"orderStatus"`
+}
+
+type ConvertFlowItem struct {
+	QuoteId      string `json:"quoteId"`
+	OrderId      int64  `json:"orderId"`
+	OrderStatus  string `json:"orderStatus"`
+	FromAsset    string `json:"fromAsset"`
+	FromAmount   string `json:"fromAmount"`
+	ToAsset      string `json:"toAsset"`
+	ToAmount     string `json:"toAmount"`
+	Ratio        string `json:"ratio"`
+	InverseRatio string `json:"inverseRatio"`
+	CreateTime   int64  `json:"createTime"`
+}
+
+type ConvertFlowRsp struct {
+	List      []*ConvertFlowItem `json:"list"`
+	StartTime int64              `json:"startTime"`
+	EndTime   int64              `json:"endTime"`
+	Limit     int                `json:"limit"`
+	MoreData  bool               `json:"moreData"`
+}
+
+type DustTransfer struct {
+	Amount              string `json:"amount"`
+	FromAsset           string `json:"fromAsset"`
+	OperateTime         int64  `json:"operateTime"`
+	ServiceChargeAmount string `json:"serviceChargeAmount"`
+	TranId              int64  `json:"tranId"`
+	TransferedAmount    string `json:"transferedAmount"`
+}
+
+type DustRsp struct {
+	TotalServiceCharge string          `json:"totalServiceCharge"`
+	TotalTransfered    string          `json:"totalTransfered"`
+	TransferResult     []*DustTransfer `json:"transferResult"`
+}
+
+type MaxBorrowable struct {
+	Amount      string `json:"amount"`
+	BorrowLimit string `json:"borrowLimit"`
+}
+
+type MarginInterest struct {
+	TxId                int64  `json:"txId"`
+	InterestAccuredTime int64  `json:"interestAccuredTime"`
+	Asset               string `json:"asset"`
+	RawAsset            string `json:"rawAsset"`
+	Principal           string `json:"principal"`
+	Interest            string `json:"interest"`
+	InterestRate        string `json:"interestRate"`
+	Type                string `json:"type"`
+	IsolatedSymbol      string `json:"isolatedSymbol"`
+}
+
+type MarginInterestRsp struct {
+	Rows  []*MarginInterest `json:"rows"`
+	Total int               `json:"total"`
+}
+
+type NextHourlyRate struct {
+	Asset                  string `json:"asset"`
+	NextHourlyInterestRate string `json:"nextHourlyInterestRate"`
+}
+
+type IBnbMyTrade interface {
+	ToStdTrade(mapSymbol func(string) string) *banexg.MyTrade
+}
+
+type SpotMyTrade struct {
+	Symbol          string `json:"symbol"`
+	Id              int64  `json:"id"`
+	OrderId         int64  `json:"orderId"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"`
+	IsBuyer         bool   `json:"isBuyer"`
+	IsMaker         bool   `json:"isMaker"`
+	IsIsolated      bool   `json:"isIsolated"` // margin
+}
+
+type FutureMyTrade struct {
+	Symbol          string `json:"symbol"`
+	Id              int64  `json:"id"`
+	OrderId         int64  `json:"orderId"`
+	Side            string `json:"side"`
+	PositionSide    string `json:"positionSide"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"` // linear
+	BaseQty         string `json:"baseQty"`  // inverse
+	RealizedPnl     string `json:"realizedPnl"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"`
+	Buyer           bool   `json:"buyer"`
+	Maker           bool   `json:"maker"`
+}
+
+type OptionMyTrade struct {
+	Id             int64  `json:"id"`
+	TradeId        int64  `json:"tradeId"`
+	OrderId        int64  `json:"orderId"`
+	Symbol         string `json:"symbol"`
+	Price          string `json:"price"`
+	Quantity       string `json:"quantity"`
+	Fee            string `json:"fee"`
+	RealizedProfit string `json:"realizedProfit"`
+	Side           string `json:"side"`
+	Type           string `json:"type"`
+	Liquidity      string `json:"liquidity"` // TAKER/MAKER
+	QuoteAsset     string `json:"quoteAsset"`
+	Time           int64  `json:"time"`
+}
+
+type OcoOrderList struct {
+	OrderListId       int64                    `json:"orderListId"`
+	ContingencyType   string                   `json:"contingencyType"`
+	ListStatusType    string                   `json:"listStatusType"`
+	ListOrderStatus   string                   `json:"listOrderStatus"`
+	ListClientOrderId string                   `json:"listClientOrderId"`
+	TransactionTime   int64                    `json:"transactionTime"`
+	Symbol            string                   `json:"symbol"`
+	OrderReports      []map[string]interface{} `json:"orderReports"`
+}
@@ -0,0 +1,174 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
+	"github.com/banbox/banexg/utils"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"time"
+)
+
+type wsApiMethod struct {
+	Host   string
+	Market string
+	Method string
+}
+
+// wsApiMethods rest methods which can be sent over websocket api 可通过websocket api发送的rest接口
+var wsApiMethods = map[string]*wsApiMethod{
+	MethodPrivatePostOrder:       {WssApi, banexg.MarketSpot, "order.place"},
+	MethodPrivateDeleteOrder:     {WssApi, banexg.MarketSpot, "order.cancel"},
+	MethodFapiPrivatePostOrder:   {WssFApi, banexg.MarketLinear, "order.place"},
+	MethodFapiPrivateDeleteOrder: {WssFApi, banexg.MarketLinear, "order.cancel"},
+	MethodFapiPrivatePutOrder:    {WssFApi, banexg.MarketLinear, "order.modify"},
+	MethodDapiPrivatePostOrder:   {WssDApi, banexg.MarketInverse, "order.place"},
+	MethodDapiPrivateDeleteOrder: {WssDApi, banexg.MarketInverse, "order.cancel"},
+	MethodDapiPrivatePutOrder:    {WssDApi, banexg.MarketInverse, "order.modify"},
+}
+
+var wsApiTimeout = 10 * time.Second
+
+/*
+requestOrderApi
+send order request over websocket api when params.wsApi (default OptWsApi) is true,
+fallback to rest when the method is not supported by ws api or the session is down.
+使用websocket api发送订单请求，接口不支持或连接不可用时回退到rest
+*/
+func (e *Binance) requestOrderApi(method string, args map[string]interface{}, tryNum int) *banexg.HttpRes {
+	useWs := utils.PopMapVal(args, banexg.ParamWsApi, utils.GetMapVal(e.Options, banexg.OptWsApi, false))
+	if useWs {
+		if rsp := e.requestWsApi(method, args); rsp != nil {
+			return rsp
+		}
+	}
+	return e.RequestApiRetry(context.Background(), method, args, tryNum)
+}
+
+/*
+requestWsApi
+send signed request over websocket api and wait for the response with the same id.
+return nil if the request is not sent, then caller should fallback to rest.
+通过websocket api发送签名请求并等待相同id的响应。未发送时返回nil，调用方应回退到rest
+*/
+func (e *Binance) requestWsApi(method string, args map[string]interface{}) *banexg.HttpRes {
+	api, ok := wsApiMethods[method]
+	if !ok || e.WsDecoder != nil {
+		return nil
+	}
+	host := e.GetHost(api.Host)
+	if host == "" {
+		return nil
+	}
+	params := utils.SafeParams(args)
+	accName, creds, err := e.GetAccountCreds(e.PopAccName(params))
+	if err != nil {
+		return &banexg.HttpRes{AccName: accName, Error: err}
+	}
+	req, err := e.makeWsApiReq(api.Method, creds, params)
+	if err != nil {
+		return &banexg.HttpRes{AccName: accName, Error: err}
+	}
+	client, err := e.GetClient(host, api.Market, accName)
+	if err != nil {
+		log.Warn("ws api unavailable, use rest", zap.String("url", host), zap.Error(err))
+		return nil
+	}
+	var conn *banexg.AsyncConn
+	for _, c := range client.Conns {
+		if c.IsOK() {
+			conn = c
+			break
+		}
+	}
+	if conn == nil {
+		log.Warn("ws api conn down, use rest", zap.String("url", host))
+		return nil
+	}
+	id := req["id"].(string)
+	out := make(chan map[string]string, 1)
+	info := &banexg.WsJobInfo{
+		ID:   id,
+		Name: api.Method,
+		Method: func(client *banexg.WsClient, msg map[string]string, info *banexg.WsJobInfo) {
+			out <- msg
+		},
+	}
+	if err = client.Write(conn, req, info); err != nil {
+		client.DelJobInfo(id)
+		log.Warn("write ws api fail, use rest", zap.String("url", host), zap.Error(err))
+		return nil
+	}
+	timer := time.NewTimer(wsApiTimeout)
+	defer timer.Stop()
+	select {
+	case msg := <-out:
+		return parseWsApiRsp(accName, api.Method, msg)
+	case <-timer.C:
+		client.DelJobInfo(id)
+		return &banexg.HttpRes{AccName: accName, Error: errs.NewMsg(errs.CodeNetFail,
+			"%s: ws api %s timeout, id: %s", accName, api.Method, id)}
+	}
+}
+
+/*
+makeWsApiReq
+build ws api request, params are signed with apiKey and timestamp in alphabetical order
+构建ws api请求，参数加上apiKey和timestamp按字母顺序签名
+*/
+func (e *Binance) makeWsApiReq(method string, creds *banexg.Credential, args map[string]interface{}) (map[string]interface{}, *errs.Error) {
+	params := make(map[string]interface{}, len(args)+4)
+	for k, v := range args {
+		params[k] = fmt.Sprintf("%v", v)
+	}
+	params["apiKey"] = creds.ApiKey
+	params["timestamp"] = e.Nonce()
+	if e.RecvWindow > 0 {
+		params["recvWindow"] = e.RecvWindow
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, params[k]))
+	}
+	sign, err := signText(strings.Join(parts, "&"), creds.Secret)
+	if err != nil {
+		return nil, err
+	}
+	params["signature"] = sign
+	return map[string]interface{}{
+		"id":     "wa" + utils.UUID(16),
+		"method": method,
+		"params": params,
+	}, nil
+}
+
+/*
+parseWsApiRsp
+convert ws api response to HttpRes, so it can be parsed the same as rest
+将ws api响应转为HttpRes，以便和rest一样解析
+*/
+func parseWsApiRsp(accName, method string, msg map[string]string) *banexg.HttpRes {
+	res := &banexg.HttpRes{AccName: accName, Content: msg["result"]}
+	status, _ := utils.SafeMapVal(msg, "status", 200)
+	res.Status = status
+	errText, hasErr := msg["error"]
+	if status >= 400 || hasErr {
+		text := fmt.Sprintf("%s: ws api %s  %v", accName, method, errText)
+		res.Error = errs.NewMsg(max(status, 400), text)
+		var errData = make(map[string]interface{})
+		if utils.UnmarshalString(errText, &errData, utils.JsonNumAuto) == nil {
+			res.Error.BizCode = int(utils.GetMapVal(errData, "code", int64(0)))
+		}
+		res.Content = errText
+	}
+	return res
+}
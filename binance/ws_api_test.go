@@ -0,0 +1,107 @@
+package binance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/banbox/banexg"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMakeWsApiReq(t *testing.T) {
+	exg, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := &banexg.Credential{ApiKey: "key", Secret: "secret"}
+	args := map[string]interface{}{"symbol": "BTCUSDT", "side": "BUY", "quantity": 0.01, "price": float64(52000)}
+	req, err := exg.makeWsApiReq("order.place", creds, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req["method"] != "order.place" || !strings.HasPrefix(req["id"].(string), "wa") {
+		t.Fatalf("bad req: %v", req)
+	}
+	params := req["params"].(map[string]interface{})
+	if params["apiKey"] != "key" || params["quantity"] != "0.01" || params["price"] != "52000" {
+		t.Fatalf("bad params: %v", params)
+	}
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k != "signature" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, params[k]))
+	}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(strings.Join(parts, "&")))
+	if sign := hex.EncodeToString(mac.Sum(nil)); params["signature"] != sign {
+		t.Errorf("bad signature: %v, expect %s", params["signature"], sign)
+	}
+}
+
+func TestParseWsApiRsp(t *testing.T) {
+	res := parseWsApiRsp("acc", "order.place", map[string]string{"id": "wa1", "status": "200",
+		"result": `{"symbol":"BTCUSDT","orderId":12,"status":"NEW"}`})
+	if res.Error != nil || res.Status != 200 || !strings.Contains(res.Content, `"orderId":12`) {
+		t.Fatalf("bad ok rsp: %+v", res)
+	}
+	res = parseWsApiRsp("acc", "order.cancel", map[string]string{"id": "wa2", "status": "400",
+		"error": `{"code":-2011,"msg":"Unknown order sent."}`})
+	if res.Error == nil || res.Error.Code != 400 || res.Error.BizCode != -2011 {
+		t.Fatalf("bad err rsp: %+v", res)
+	}
+}
+
+func TestWsApiJobInfo(t *testing.T) {
+	exg, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var others int
+	client := &banexg.WsClient{
+		Exg:      exg.Exchange,
+		JobInfos: map[string]*banexg.WsJobInfo{},
+		OnMessage: func(client *banexg.WsClient, msg *banexg.WsMsg) {
+			others += 1
+		},
+	}
+	out := make(chan map[string]string, 1)
+	client.JobInfos["wa1"] = &banexg.WsJobInfo{ID: "wa1", Method: func(client *banexg.WsClient, msg map[string]string, info *banexg.WsJobInfo) {
+		out <- msg
+	}}
+	client.HandleRawMsg([]byte(`{"id":"wa1","status":200,"result":{"orderId":12,"status":"NEW"}}`))
+	select {
+	case msg := <-out:
+		res := parseWsApiRsp("", "order.place", msg)
+		if res.Error != nil || !strings.Contains(res.Content, `"orderId":12`) {
+			t.Errorf("bad rsp: %+v", res)
+		}
+	default:
+		t.Fatal("job method not called")
+	}
+	if len(client.JobInfos) != 0 {
+		t.Errorf("job info should be removed")
+	}
+	client.HandleRawMsg([]byte(`{"id":"wa1","status":200,"result":{}}`))
+	if others != 1 {
+		t.Errorf("unmatched reply should go to OnMessage")
+	}
+}
+
+func TestRequestWsApiUnsupported(t *testing.T) {
+	exg, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := exg.requestWsApi(MethodSapiDeleteMarginOrder, map[string]interface{}{}); res != nil {
+		t.Errorf("margin should fallback to rest, got %+v", res)
+	}
+}
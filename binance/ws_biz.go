@@ -25,6 +25,9 @@ func makeHandleWsMsg(e *Binance) banexg.FuncOnWsMsg {
 				} else {
 					log.Debug("ws job ok", zap.String("job", item.ID))
 				}
+			} else if _, ok := item.Object["u"]; ok && !item.IsArray {
+				// 现货bookTicker没有事件类型
+				e.handleTickers(client, []map[string]string{item.Object})
 			} else {
 				log.Warn("no event ws msg", zap.String("msg", item.Text))
 			}
@@ -77,6 +80,10 @@ func makeHandleWsMsg(e *Binance) banexg.FuncOnWsMsg {
 			e.handleOrderUpdate(client, msg)
 		case "ACCOUNT_CONFIG_UPDATE":
 			e.handleAccountConfigUpdate(client, msg)
+		case "forceOrder":
+			e.handleForceOrder(client, msg)
+		case "MARGIN_CALL":
+			e.handleMarginCall(client, msg)
 		default:
 			log.Warn("unhandle ws msg", zap.String("msg", item.Text))
 		}
@@ -109,8 +116,10 @@ func (e *Binance) postListenKey(acc *banexg.Account, params map[string]interface
 	zeroVal := int64(0)
 	args := utils.SafeParams(params)
 	marketType, _ := e.GetArgsMarketType(args, "")
-	lastTimeKey := marketType + "lastAuthTime"
-	authField := marketType + banexg.MidListenKey
+	portfolio := e.isPortfolioMargin(args)
+	streamType := userStreamType(marketType, portfolio)
+	lastTimeKey := streamType + "lastAuthTime"
+	authField := streamType + banexg.MidListenKey
 	lastAuthTime := utils.GetMapVal(acc.Data, lastTimeKey, zeroVal)
 	authRefreshSecs := utils.GetMapVal(e.Options, banexg.OptAuthRefreshSecs, 1200)
 	refreshDuration := int64(authRefreshSecs * 1000)
@@ -120,7 +129,9 @@ func (e *Binance) postListenKey(acc *banexg.Account, params map[string]interface
 	}
 	marginMode := utils.PopMapVal(args, banexg.ParamMarginMode, "")
 	method := MethodPublicPostUserDataStream
-	if marketType == banexg.MarketLinear {
+	if portfolio {
+		method = MethodPapiPostListenKey
+	} else if marketType == banexg.MarketLinear {
 		method = MethodFapiPrivatePostListenKey
 	} else if marketType == banexg.MarketInverse {
 		method = MethodDapiPrivatePostListenKey
@@ -157,8 +168,10 @@ func (e *Binance) postListenKey(acc *banexg.Account, params map[string]interface
 func (e *Binance) keepAliveListenKey(acc *banexg.Account, params map[string]interface{}) {
 	args := utils.SafeParams(params)
 	marketType, _ := e.GetArgsMarketType(args, "")
-	lastTimeKey := marketType + "lastAuthTime"
-	authField := marketType + banexg.MidListenKey
+	portfolio := e.isPortfolioMargin(args)
+	streamType := userStreamType(marketType, portfolio)
+	lastTimeKey := streamType + "lastAuthTime"
+	authField := streamType + banexg.MidListenKey
 	acc.LockData.Lock()
 	listenKey := utils.GetMapVal(acc.Data, authField, "")
 	acc.LockData.Unlock()
@@ -174,7 +187,7 @@ func (e *Binance) keepAliveListenKey(acc *banexg.Account, params map[string]inte
 		delete(acc.Data, authField)
 		delete(acc.Data, lastTimeKey)
 		acc.LockData.Unlock()
-		clientKey := acc.Name + "@" + e.GetHost(marketType) + "/" + listenKey
+		clientKey := acc.Name + "@" + e.userStreamHost(marketType, portfolio) + "/" + listenKey
 		if client, ok := e.WSClients[clientKey]; ok {
 			for _, conn := range client.Conns {
 				_ = conn.WriteClose()
@@ -183,7 +196,9 @@ func (e *Binance) keepAliveListenKey(acc *banexg.Account, params map[string]inte
 		}
 	}()
 	method := MethodPublicPutUserDataStream
-	if marketType == banexg.MarketLinear {
+	if portfolio {
+		method = MethodPapiPutListenKey
+	} else if marketType == banexg.MarketLinear {
 		method = MethodFapiPrivatePutListenKey
 	} else if marketType == banexg.MarketInverse {
 		method = MethodDapiPrivatePutListenKey
@@ -241,14 +256,60 @@ func (e *Binance) getAuthClient(params map[string]interface{}) (string, *banexg.
 	}
 	args := utils.SafeParams(params)
 	marketType, _ := e.GetArgsMarketType(args, "")
+	portfolio := e.isPortfolioMargin(args)
+	streamType := userStreamType(marketType, portfolio)
 	acc.LockData.Lock()
-	listenKey := utils.GetMapVal(acc.Data, marketType+banexg.MidListenKey, "")
+	listenKey := utils.GetMapVal(acc.Data, streamType+banexg.MidListenKey, "")
 	acc.LockData.Unlock()
-	wsUrl := e.GetHost(marketType) + "/" + listenKey
-	client, err := e.GetClient(wsUrl, marketType, acc.Name)
+	wsUrl := e.userStreamHost(marketType, portfolio) + "/" + listenKey
+	client, err := e.GetClient(wsUrl, streamType, acc.Name)
 	return listenKey, client, err
 }
 
+/*
+userStreamType
+key of user stream in account data, also market type of the ws client. Portfolio margin account has only one
+stream for UM/CM/margin, so it's keyed by account with pmStreamType
+用户数据流在账户数据中的键，也是ws客户端的市场类型。统一账户的UM/CM/杠杆共用一个数据流，按账户使用pmStreamType
+*/
+func userStreamType(marketType string, portfolio bool) string {
+	if portfolio {
+		return pmStreamType
+	}
+	return marketType
+}
+
+/*
+wsMarketType
+market type of user stream event, events of portfolio margin stream are told by `fs`: UM/CM, margin if absent
+用户数据流事件的市场类型，统一账户数据流按`fs`区分：UM/CM，无此字段时为杠杆
+*/
+func wsMarketType(client *banexg.WsClient, msg map[string]string) string {
+	if client.MarketType != pmStreamType {
+		return client.MarketType
+	}
+	switch msg["fs"] {
+	case "UM":
+		return banexg.MarketLinear
+	case "CM":
+		return banexg.MarketInverse
+	default:
+		return banexg.MarketMargin
+	}
+}
+
+/*
+userStreamHost
+ws host of user data stream, portfolio margin account use the papi stream for all markets
+用户数据流的ws地址，统一账户所有市场使用papi数据流
+*/
+func (e *Binance) userStreamHost(marketType string, portfolio bool) string {
+	if portfolio {
+		return e.GetHost(WssPApi)
+	}
+	return e.GetHost(marketType)
+}
+
 func (e *Binance) WatchBalance(params map[string]interface{}) (chan *banexg.Balances, *errs.Error) {
 	_, client, err := e.getAuthClient(params)
 	if err != nil {
@@ -287,10 +348,14 @@ func (e *Binance) WatchPositions(params map[string]interface{}) (chan []*banexg.
 	if err != nil {
 		return nil, err
 	}
+	args := utils.SafeParams(params)
+	posType := client.MarketType
+	if posType == pmStreamType {
+		posType, _ = e.GetArgsMarketType(args, "")
+	}
 	acc.LockPos.Lock()
-	acc.MarPositions[client.MarketType] = positions
+	acc.MarPositions[posType] = positions
 	acc.LockPos.Unlock()
-	args := utils.SafeParams(params)
 	chanKey := client.Prefix("positions")
 	create := func(cap int) chan []*banexg.Position { return make(chan []*banexg.Position, cap) }
 	out := banexg.GetWsOutChan(e.Exchange, chanKey, create, args)
@@ -545,8 +610,181 @@ func (e *Binance) prepareOHLCVSub(isSub bool, jobs [][2]string, params map[strin
 	return chanKey, symbols, args, nil
 }
 
+func (e *Binance) WatchTickers(symbols []string, params map[string]interface{}) (chan *banexg.Ticker, *errs.Error) {
+	chanKey, refs, args, err := e.prepareTickers(true, "ticker", symbols, params)
+	if err != nil {
+		return nil, err
+	}
+	create := func(cap int) chan *banexg.Ticker { return make(chan *banexg.Ticker, cap) }
+	out := banexg.GetWsOutChan(e.Exchange, chanKey, create, args)
+	e.AddWsChanRefs(chanKey, refs...)
+	e.DumpWS("WatchTickers", symbols)
+	return out, nil
+}
+
+func (e *Binance) UnWatchTickers(symbols []string, params map[string]interface{}) *errs.Error {
+	chanKey, refs, _, err := e.prepareTickers(false, "ticker", symbols, params)
+	if err != nil {
+		return err
+	}
+	e.DelWsChanRefs(chanKey, refs...)
+	return nil
+}
+
+func (e *Binance) WatchBookTickers(symbols []string, params map[string]interface{}) (chan *banexg.Ticker, *errs.Error) {
+	chanKey, refs, args, err := e.prepareTickers(true, "bookTicker", symbols, params)
+	if err != nil {
+		return nil, err
+	}
+	create := func(cap int) chan *banexg.Ticker { return make(chan *banexg.Ticker, cap) }
+	out := banexg.GetWsOutChan(e.Exchange, chanKey, create, args)
+	e.AddWsChanRefs(chanKey, refs...)
+	e.DumpWS("WatchBookTickers", symbols)
+	return out, nil
+}
+
+func (e *Binance) UnWatchBookTickers(symbols []string, params map[string]interface{}) *errs.Error {
+	chanKey, refs, _, err := e.prepareTickers(false, "bookTicker", symbols, params)
+	if err != nil {
+		return err
+	}
+	e.DelWsChanRefs(chanKey, refs...)
+	return nil
+}
+
+/*
+prepareTickers
+name: ticker/bookTicker. subscribe all symbols of market type when symbols is empty
+name: ticker/bookTicker。symbols为空时订阅市场下所有标的
+*/
+func (e *Binance) prepareTickers(isSub bool, name string, symbols []string, params map[string]interface{}) (string, []string, map[string]interface{}, *errs.Error) {
+	args := utils.SafeParams(params)
+	marketType, _, err := e.LoadArgsMarketType(args, symbols...)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if name == "bookTicker" && marketType == banexg.MarketOption {
+		return "", nil, nil, errs.NewMsg(errs.CodeUnsupportMarket, "WatchBookTickers not support option")
+	}
+	msgHash := marketType + "@" + name
+	client, err := e.GetWsClient(marketType, msgHash)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	refs := symbols
+	cvt := func(m *banexg.Market, _ int) string {
+		return m.LowercaseID + "@" + name
+	}
+	if len(symbols) == 0 {
+		if marketType == banexg.MarketOption {
+			return "", nil, nil, errs.NewMsg(errs.CodeParamRequired, "symbols is required for option tickers")
+		}
+		if name == "ticker" {
+			symbols = []string{"!ticker@arr"}
+		} else {
+			symbols = []string{"!bookTicker"}
+		}
+		refs = []string{name}
+		cvt = nil
+	}
+	err = e.WriteWSMsg(client, 0, isSub, symbols, cvt, nil)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	chanKey := client.Prefix(msgHash)
+	return chanKey, refs, args, nil
+}
+
+/*
+handleTickers
+处理24hrTicker/24hrMiniTicker/bookTicker消息
+*/
 func (e *Binance) handleTickers(client *banexg.WsClient, msgList []map[string]string) {
+	if len(msgList) == 0 {
+		return
+	}
+	event, _ := utils.SafeMapVal(msgList[0], "e", "bookTicker")
+	name := "ticker"
+	if event == "bookTicker" {
+		name = "bookTicker"
+	}
+	chanKey := client.Prefix(client.MarketType + "@" + name)
+	for _, msg := range msgList {
+		marketId, _ := utils.SafeMapVal(msg, "s", "")
+		symbol := e.SafeSymbol(marketId, "", client.MarketType)
+		if symbol == "" {
+			continue
+		}
+		var ticker *banexg.Ticker
+		if name == "bookTicker" {
+			ticker = parseWsBookTicker(msg)
+		} else {
+			ticker = parseWsTicker(msg, client.MarketType == banexg.MarketOption)
+		}
+		ticker.Symbol = symbol
+		banexg.WriteOutChan(e.Exchange, chanKey, ticker, true)
+	}
+}
 
+func parseWsBookTicker(msg map[string]string) *banexg.Ticker {
+	stamp, _ := utils.SafeMapVal(msg, "E", int64(0))
+	if stamp == 0 {
+		// 现货bookTicker无事件时间
+		stamp = time.Now().UnixMilli()
+	}
+	bid, _ := utils.SafeMapVal(msg, "b", float64(0))
+	bidVol, _ := utils.SafeMapVal(msg, "B", float64(0))
+	ask, _ := utils.SafeMapVal(msg, "a", float64(0))
+	askVol, _ := utils.SafeMapVal(msg, "A", float64(0))
+	return &banexg.Ticker{
+		TimeStamp: stamp,
+		Bid:       bid,
+		BidVolume: bidVol,
+		Ask:       ask,
+		AskVolume: askVol,
+		Info:      msg,
+	}
+}
+
+func parseWsTicker(msg map[string]string, isOption bool) *banexg.Ticker {
+	stamp, _ := utils.SafeMapVal(msg, "E", int64(0))
+	open, _ := utils.SafeMapVal(msg, "o", float64(0))
+	high, _ := utils.SafeMapVal(msg, "h", float64(0))
+	low, _ := utils.SafeMapVal(msg, "l", float64(0))
+	last, _ := utils.SafeMapVal(msg, "c", float64(0))
+	change, _ := utils.SafeMapVal(msg, "p", float64(0))
+	percent, _ := utils.SafeMapVal(msg, "P", float64(0))
+	res := &banexg.Ticker{
+		TimeStamp:  stamp,
+		Open:       open,
+		High:       high,
+		Low:        low,
+		Close:      last,
+		Last:       last,
+		Change:     change,
+		Percentage: percent,
+		Info:       msg,
+	}
+	if isOption {
+		// 期权的b/a是隐含波动率，盘口使用bo/ao
+		res.Bid, _ = utils.SafeMapVal(msg, "bo", float64(0))
+		res.Ask, _ = utils.SafeMapVal(msg, "ao", float64(0))
+		res.BidVolume, _ = utils.SafeMapVal(msg, "bq", float64(0))
+		res.AskVolume, _ = utils.SafeMapVal(msg, "aq", float64(0))
+		res.BaseVolume, _ = utils.SafeMapVal(msg, "V", float64(0))
+		res.QuoteVolume, _ = utils.SafeMapVal(msg, "A", float64(0))
+		res.MarkPrice, _ = utils.SafeMapVal(msg, "mp", float64(0))
+		return res
+	}
+	res.Bid, _ = utils.SafeMapVal(msg, "b", float64(0))
+	res.Ask, _ = utils.SafeMapVal(msg, "a", float64(0))
+	res.BidVolume, _ = utils.SafeMapVal(msg, "B", float64(0))
+	res.AskVolume, _ = utils.SafeMapVal(msg, "A", float64(0))
+	res.BaseVolume, _ = utils.SafeMapVal(msg, "v", float64(0))
+	res.QuoteVolume, _ = utils.SafeMapVal(msg, "q", float64(0))
+	res.Vwap, _ = utils.SafeMapVal(msg, "w", float64(0))
+	res.PreviousClose, _ = utils.SafeMapVal(msg, "x", float64(0))
+	return res
 }
 
 /*
@@ -639,6 +877,7 @@ type WSContractPosition struct {
 func (e *Binance) handleAccountUpdate(client *banexg.WsClient, msg map[string]string) {
 	updBalance := false
 	updPosition := false
+	marketType := wsMarketType(client, msg)
 	acc, err := e.GetAccount(client.AccName)
 	if err != nil {
 		log.Error("account for ws client not found", zap.String("name", client.AccName))
@@ -654,10 +893,10 @@ func (e *Binance) handleAccountUpdate(client *banexg.WsClient, msg map[string]st
 	}
 	acc.LockBalance.Unlock()
 	acc.LockPos.Lock()
-	positions, ok := acc.MarPositions[client.MarketType]
+	positions, ok := acc.MarPositions[marketType]
 	if !ok {
 		positions = make([]*banexg.Position, 0)
-		acc.MarPositions[client.MarketType] = positions
+		acc.MarPositions[marketType] = positions
 	}
 	acc.LockPos.Unlock()
 	posMap := make(map[string]*banexg.Position)
@@ -666,7 +905,7 @@ func (e *Binance) handleAccountUpdate(client *banexg.WsClient, msg map[string]st
 	}
 	evtTime, _ := utils.SafeMapVal(msg, "E", int64(0))
 	balances.TimeStamp = evtTime
-	if client.MarketType != banexg.MarketOption {
+	if marketType != banexg.MarketOption {
 		// linear/inverse
 		text, _ := msg["a"]
 		var Data = struct {
@@ -695,7 +934,7 @@ func (e *Binance) handleAccountUpdate(client *banexg.WsClient, msg map[string]st
 			}
 		}
 		for _, pos := range Data.Positions {
-			symbol := e.SafeSymbol(pos.Symbol, "", client.MarketType)
+			symbol := e.SafeSymbol(pos.Symbol, "", marketType)
 			if symbol == "" {
 				continue
 			}
@@ -725,7 +964,7 @@ func (e *Binance) handleAccountUpdate(client *banexg.WsClient, msg map[string]st
 			positions = append(positions, p)
 		}
 		acc.LockPos.Lock()
-		acc.MarPositions[client.MarketType] = positions
+		acc.MarPositions[marketType] = positions
 		acc.LockPos.Unlock()
 		updBalance = len(Data.Balances) > 0
 		updPosition = len(Data.Positions) > 0
@@ -738,13 +977,14 @@ func (e *Binance) handleAccountUpdate(client *banexg.WsClient, msg map[string]st
 	}
 	if updPosition {
 		acc.LockPos.Lock()
-		positions = acc.MarPositions[client.MarketType]
+		positions = acc.MarPositions[marketType]
 		acc.LockPos.Unlock()
 		banexg.WriteOutChan(e.Exchange, client.Prefix("positions"), positions, true)
 	}
 }
 func (e *Binance) handleOrderUpdate(client *banexg.WsClient, msg map[string]string) {
 	event, _ := utils.SafeMapVal(msg, "e", "")
+	marketType := wsMarketType(client, msg)
 	if event == "ORDER_TRADE_UPDATE" {
 		objText, _ := utils.SafeMapVal(msg, "o", "")
 		var obj = map[string]interface{}{}
@@ -756,7 +996,7 @@ func (e *Binance) handleOrderUpdate(client *banexg.WsClient, msg map[string]stri
 		msg = utils.MapValStr(obj)
 	}
 	trade := parseMyTrade(msg)
-	market := e.GetMarketById(trade.Symbol, client.MarketType)
+	market := e.GetMarketById(trade.Symbol, marketType)
 	if market == nil {
 		log.Error("no market found for my trade", zap.String("symbol", trade.Symbol))
 		return
@@ -770,6 +1010,11 @@ func (e *Binance) handleOrderUpdate(client *banexg.WsClient, msg map[string]stri
 }
 
 func (e *Binance) handleAccountConfigUpdate(client *banexg.WsClient, msg map[string]string) {
+	marketType := wsMarketType(client, msg)
+	if aiText, ok := msg["ai"]; ok && aiText != "" {
+		e.handleMultiAssetsUpdate(client, marketType, aiText)
+		return
+	}
 	acText, ok := msg["ac"]
 	if !ok || acText == "" {
 		return
@@ -782,16 +1027,38 @@ func (e *Binance) handleAccountConfigUpdate(client *banexg.WsClient, msg map[str
 	}
 	marketId := utils.GetMapVal(data, "s", "")
 	leverage := int(utils.GetMapVal(data, "l", int64(0)))
-	market := e.GetMarketById(marketId, client.MarketType)
+	market := e.GetMarketById(marketId, marketType)
 	if market == nil {
 		log.Error("no market found for AccountConfigUpdate", zap.String("symbol", marketId))
 		return
 	}
-	if acc, ok := e.Accounts[client.AccName]; ok {
+	if acc, err := e.GetAccount(client.AccName); err == nil {
 		acc.LockLeverage.Lock()
 		acc.Leverages[market.Symbol] = leverage
 		acc.LockLeverage.Unlock()
 	}
-	item := &banexg.AccountConfig{Symbol: market.Symbol, Leverage: leverage}
+	item := &banexg.AccountConfig{Symbol: market.Symbol, Leverage: leverage, MarketType: marketType}
+	banexg.WriteOutChan(e.Exchange, client.Prefix("accConfig"), item, false)
+}
+
+/*
+handleMultiAssetsUpdate
+multi-assets mode changed: {"e":"ACCOUNT_CONFIG_UPDATE","ai":{"j":true}}
+联合保证金模式变化
+*/
+func (e *Binance) handleMultiAssetsUpdate(client *banexg.WsClient, marketType, aiText string) {
+	var data = make(map[string]interface{})
+	err_ := utils.UnmarshalString(aiText, &data, utils.JsonNumAuto)
+	if err_ != nil {
+		log.Error("unmarshal AccountConfigUpdate fail", zap.String("ai", aiText), zap.Error(err_))
+		return
+	}
+	enabled := utils.GetMapVal(data, "j", false)
+	if acc, err := e.GetAccount(client.AccName); err == nil {
+		acc.LockLeverage.Lock()
+		acc.MultiAssets[marketType] = enabled
+		acc.LockLeverage.Unlock()
+	}
+	item := &banexg.AccountConfig{MarketType: marketType, MultiAssets: enabled}
 	banexg.WriteOutChan(e.Exchange, client.Prefix("accConfig"), item, false)
 }
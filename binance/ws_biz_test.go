@@ -161,6 +161,57 @@ mainFor:
 	}
 }
 
+func TestWatchTickers(t *testing.T) {
+	exg := getBinance(nil)
+	exg.MarketType = banexg.MarketLinear
+	symbols := []string{"BTC/USDT:USDT", "ETH/USDT:USDT"}
+	out, err := exg.WatchTickers(symbols, nil)
+	if err != nil {
+		panic(err)
+	}
+	books, err := exg.WatchBookTickers(symbols, nil)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("start watching tickers")
+	for {
+		select {
+		case tk, ok := <-out:
+			if !ok {
+				log.Info("read tickers chan fail, break")
+				return
+			}
+			fmt.Printf("ticker %s: last %v, bid %v, ask %v\n", tk.Symbol, tk.Last, tk.Bid, tk.Ask)
+		case tk, ok := <-books:
+			if !ok {
+				log.Info("read book tickers chan fail, break")
+				return
+			}
+			fmt.Printf("book %s: bid %v x %v, ask %v x %v\n", tk.Symbol, tk.Bid, tk.BidVolume, tk.Ask, tk.AskVolume)
+		}
+	}
+}
+
+func TestParseWsTicker(t *testing.T) {
+	msg := map[string]string{"e": "24hrTicker", "E": "1700000000000", "s": "BTCUSDT", "c": "100.5", "o": "99",
+		"h": "101", "l": "98", "v": "10", "q": "1000", "b": "100.4", "B": "2", "a": "100.6", "A": "3"}
+	tk := parseWsTicker(msg, false)
+	if tk.TimeStamp != 1700000000000 || tk.Last != 100.5 || tk.Bid != 100.4 || tk.AskVolume != 3 || tk.QuoteVolume != 1000 {
+		t.Errorf("bad ticker: %+v", tk)
+	}
+	// 期权的b/a为隐含波动率
+	msg = map[string]string{"e": "24hrTicker", "E": "1700000000000", "c": "50", "b": "0.6", "a": "0.7",
+		"bo": "49", "ao": "51", "bq": "1", "aq": "2", "mp": "50.2"}
+	tk = parseWsTicker(msg, true)
+	if tk.Bid != 49 || tk.Ask != 51 || tk.AskVolume != 2 || tk.MarkPrice != 50.2 {
+		t.Errorf("bad option ticker: %+v", tk)
+	}
+	book := parseWsBookTicker(map[string]string{"u": "1", "s": "BTCUSDT", "b": "10", "B": "1", "a": "11", "A": "2"})
+	if book.Bid != 10 || book.Ask != 11 || book.BidVolume != 1 || book.TimeStamp == 0 {
+		t.Errorf("bad book ticker: %+v", book)
+	}
+}
+
 func TestWsDump(t *testing.T) {
 	exg := getBinance(map[string]interface{}{
 		banexg.OptDumpPath: getWsDumpPath(),
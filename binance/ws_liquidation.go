@@ -0,0 +1,210 @@
+package binance
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
+	"github.com/banbox/banexg/utils"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+)
+
+/*
+WsForceOrder
+order of forceOrder stream
+*/
+type WsForceOrder struct {
+	Symbol      string `json:"s"`
+	Side        string `json:"S"`
+	Type        string `json:"o"`
+	TimeInForce string `json:"f"`
+	Qty         string `json:"q"`
+	Price       string `json:"p"`
+	AvgPrice    string `json:"ap"`
+	Status      string `json:"X"`
+	LastQty     string `json:"l"`
+	FilledQty   string `json:"z"`
+	Time        int64  `json:"T"`
+}
+
+/*
+WsMarginCallPos
+position of MARGIN_CALL event
+*/
+type WsMarginCallPos struct {
+	Symbol       string `json:"s"`
+	PositionSide string `json:"ps"`
+	PositionAmt  string `json:"pa"`
+	MarginType   string `json:"mt"`
+	IsolatedWall string `json:"iw"`
+	MarkPrice    string `json:"mp"`
+	UnrealizedPL string `json:"up"`
+	MaintMargin  string `json:"mm"`
+}
+
+/*
+WatchLiquidations
+watch public liquidation orders of linear/inverse, all symbols if symbols is empty.
+all-market stream uses a separate chan from symbol streams
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#liquidation-order-streams
+	:see: https://binance-docs.github.io/apidocs/futures/en/#all-market-liquidation-order-streams
+*/
+func (e *Binance) WatchLiquidations(symbols []string, params map[string]interface{}) (chan *banexg.Liquidation, *errs.Error) {
+	chanKey, refs, args, err := e.prepareLiquidations(true, symbols, params)
+	if err != nil {
+		return nil, err
+	}
+	create := func(cap int) chan *banexg.Liquidation { return make(chan *banexg.Liquidation, cap) }
+	out := banexg.GetWsOutChan(e.Exchange, chanKey, create, args)
+	e.AddWsChanRefs(chanKey, refs...)
+	e.DumpWS("WatchLiquidations", symbols)
+	return out, nil
+}
+
+func (e *Binance) UnWatchLiquidations(symbols []string, params map[string]interface{}) *errs.Error {
+	chanKey, refs, _, err := e.prepareLiquidations(false, symbols, params)
+	if err != nil {
+		return err
+	}
+	e.DelWsChanRefs(chanKey, refs...)
+	return nil
+}
+
+func (e *Binance) prepareLiquidations(isSub bool, symbols []string, params map[string]interface{}) (string, []string, map[string]interface{}, *errs.Error) {
+	args := utils.SafeParams(params)
+	marketType, _, err := e.LoadArgsMarketType(args, symbols...)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if marketType != banexg.MarketLinear && marketType != banexg.MarketInverse {
+		return "", nil, nil, errs.NewMsg(errs.CodeUnsupportMarket, "WatchLiquidations support linear/inverse only")
+	}
+	// 全市场和指定标的使用不同的流和通道，避免重复推送
+	msgHash := marketType + "@forceOrder"
+	refs := symbols
+	cvt := func(m *banexg.Market, _ int) string {
+		return m.LowercaseID + "@forceOrder"
+	}
+	if len(symbols) == 0 {
+		msgHash += "@arr"
+		symbols = []string{"!forceOrder@arr"}
+		refs = []string{"forceOrder"}
+		cvt = nil
+	}
+	client, err := e.GetWsClient(marketType, msgHash)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	err = e.WriteWSMsg(client, 0, isSub, symbols, cvt, nil)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	chanKey := client.Prefix(msgHash)
+	return chanKey, refs, args, nil
+}
+
+/*
+handleForceOrder
+{"e":"forceOrder","E":1568014460893,"o":{"s":"BTCUSDT","S":"SELL","o":"LIMIT","f":"IOC","q":"0.014",
+"p":"9910","ap":"9910","X":"FILLED","l":"0.014","z":"0.014","T":1568014460893}}
+*/
+func (e *Binance) handleForceOrder(client *banexg.WsClient, msg map[string]string) {
+	text, ok := msg["o"]
+	if !ok || text == "" {
+		return
+	}
+	var it = WsForceOrder{}
+	err := utils.UnmarshalString(text, &it, utils.JsonNumDefault)
+	if err != nil {
+		log.Error("unmarshal forceOrder fail", zap.String("o", text), zap.Error(err))
+		return
+	}
+	symbol := e.SafeSymbol(it.Symbol, "", client.MarketType)
+	if symbol == "" {
+		return
+	}
+	price, _ := strconv.ParseFloat(it.Price, 64)
+	average, _ := strconv.ParseFloat(it.AvgPrice, 64)
+	amount, _ := strconv.ParseFloat(it.Qty, 64)
+	filled, _ := strconv.ParseFloat(it.FilledQty, 64)
+	item := &banexg.Liquidation{
+		Symbol:    symbol,
+		Side:      strings.ToLower(it.Side),
+		Price:     price,
+		Average:   average,
+		Amount:    amount,
+		Filled:    filled,
+		Status:    mapOrderStatus(it.Status),
+		Timestamp: it.Time,
+		Info:      &it,
+	}
+	// only the chan of subscribed stream exists on this client 此客户端上只存在已订阅流的通道
+	msgHash := client.MarketType + "@forceOrder"
+	banexg.WriteOutChan(e.Exchange, client.Prefix(msgHash), item, true)
+	banexg.WriteOutChan(e.Exchange, client.Prefix(msgHash+"@arr"), item, true)
+}
+
+/*
+WatchMarginCalls
+watch positions close to liquidation from user data stream of linear/inverse
+
+	:see: https://binance-docs.github.io/apidocs/futures/en/#event-margin-call
+*/
+func (e *Binance) WatchMarginCalls(params map[string]interface{}) (chan *banexg.MarginCall, *errs.Error) {
+	_, client, err := e.getAuthClient(params)
+	if err != nil {
+		return nil, err
+	}
+	args := utils.SafeParams(params)
+	chanKey := client.Prefix("marginCall")
+	create := func(cap int) chan *banexg.MarginCall { return make(chan *banexg.MarginCall, cap) }
+	out := banexg.GetWsOutChan(e.Exchange, chanKey, create, args)
+	e.AddWsChanRefs(chanKey, "account")
+	return out, nil
+}
+
+/*
+handleMarginCall
+{"e":"MARGIN_CALL","E":1587727187525,"cw":"3.16812045","p":[{"s":"ETHUSDT","ps":"LONG","pa":"1.327",
+"mt":"CROSSED","iw":"0","mp":"187.17127","up":"-1.166074","mm":"1.614445"}]}
+*/
+func (e *Binance) handleMarginCall(client *banexg.WsClient, msg map[string]string) {
+	text, ok := msg["p"]
+	if !ok || text == "" {
+		return
+	}
+	var data = make([]*WsMarginCallPos, 0)
+	err := utils.UnmarshalString(text, &data, utils.JsonNumDefault)
+	if err != nil {
+		log.Error("unmarshal MARGIN_CALL fail", zap.String("p", text), zap.Error(err))
+		return
+	}
+	stamp, _ := utils.SafeMapVal(msg, "E", int64(0))
+	crossWallet, _ := utils.SafeMapVal(msg, "cw", float64(0))
+	chanKey := client.Prefix("marginCall")
+	for _, it := range data {
+		amount, _ := strconv.ParseFloat(it.PositionAmt, 64)
+		markPrice, _ := strconv.ParseFloat(it.MarkPrice, 64)
+		upol, _ := strconv.ParseFloat(it.UnrealizedPL, 64)
+		maintMargin, _ := strconv.ParseFloat(it.MaintMargin, 64)
+		marginMode := banexg.MarginCross
+		if it.MarginType == "ISOLATED" {
+			marginMode = banexg.MarginIsolated
+		}
+		item := &banexg.MarginCall{
+			Symbol:        e.SafeSymbol(it.Symbol, "", wsMarketType(client, msg)),
+			PosSide:       strings.ToLower(it.PositionSide),
+			MarginMode:    marginMode,
+			Amount:        amount,
+			MarkPrice:     markPrice,
+			UnrealizedPnl: upol,
+			MaintMargin:   maintMargin,
+			CrossWallet:   crossWallet,
+			Timestamp:     stamp,
+			Info:          it,
+		}
+		banexg.WriteOutChan(e.Exchange, chanKey, item, false)
+	}
+}
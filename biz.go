@@ -87,6 +87,7 @@ func (e *Exchange) Init() *errs.Error {
 	utils.SetFieldBy(&e.CareMarkets, e.Options, OptCareMarkets, nil)
 	utils.SetFieldBy(&e.MarketType, e.Options, OptMarketType, MarketSpot)
 	utils.SetFieldBy(&e.ContractType, e.Options, OptContractType, "")
+	utils.SetFieldBy(&e.MarginMode, e.Options, OptMarginMode, "")
 	utils.SetFieldBy(&e.TimeInForce, e.Options, OptTimeInForce, DefTimeInForce)
 	utils.SetFieldBy(&e.DebugWS, e.Options, OptDebugWs, false)
 	utils.SetFieldBy(&e.DebugAPI, e.Options, OptDebugApi, false)
@@ -133,23 +134,32 @@ func (e *Exchange) SetFees(fees map[string]map[string]float64) {
 	if e.Fees == nil {
 		e.Fees = &ExgFee{}
 	}
+	e.Fees.Update(fees)
+}
+
+/*
+Update
+set maker/taker rates by market type: linear/inverse, others for Main
+按市场类型设置挂单/吃单费率，非linear/inverse的设置到Main
+*/
+func (f *ExgFee) Update(fees map[string]map[string]float64) {
 	for market, feeMap := range fees {
 		var target *TradeFee
-		if market == "linear" {
-			if e.Fees.Linear == nil {
-				e.Fees.Linear = &TradeFee{}
+		if market == MarketLinear {
+			if f.Linear == nil {
+				f.Linear = &TradeFee{}
 			}
-			target = e.Fees.Linear
-		} else if market == "inverse" {
-			if e.Fees.Inverse == nil {
-				e.Fees.Inverse = &TradeFee{}
+			target = f.Linear
+		} else if market == MarketInverse {
+			if f.Inverse == nil {
+				f.Inverse = &TradeFee{}
 			}
-			target = e.Fees.Inverse
+			target = f.Inverse
 		} else {
-			if e.Fees.Main == nil {
-				e.Fees.Main = &TradeFee{}
+			if f.Main == nil {
+				f.Main = &TradeFee{}
 			}
-			target = e.Fees.Main
+			target = f.Main
 		}
 		for field, rate := range feeMap {
 			field = strings.ToLower(field)
@@ -634,6 +644,10 @@ func (e *Exchange) FetchOpenOrders(symbol string, since int64, limit int, params
 	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
 
+func (e *Exchange) FetchMyTrades(symbol string, since int64, limit int, params map[string]interface{}) ([]*MyTrade, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
 func (e *Exchange) FetchIncomeHistory(inType string, symbol string, since int64, limit int, params map[string]interface{}) ([]*Income, *errs.Error) {
 	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
@@ -670,10 +684,261 @@ func (e *Exchange) CancelOrder(id string, symbol string, params map[string]inter
 	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
 
+func (e *Exchange) CreateOrders(reqs []*OrderReq, params map[string]interface{}) ([]*OrderRes, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) EditOrders(reqs []*OrderReq, params map[string]interface{}) ([]*OrderRes, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) CancelOrders(reqs []*OrderReq, params map[string]interface{}) ([]*OrderRes, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) CancelAllOrders(symbol string, params map[string]interface{}) ([]*Order, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+/*
+ValidateOrder
+check precision and limits of market; exchanges override it to call CheckOrder with themselves,
+so that leverage brackets and balance are also checked
+检查市场精度和限制；交易所需重写此方法，以自身调用CheckOrder，从而检查杠杆档位和余额
+*/
+func (e *Exchange) ValidateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*OrderCheck, *errs.Error) {
+	return CheckOrder(e, symbol, odType, side, amount, price, params)
+}
+
+func (e *Exchange) CreateOCO(symbol, side string, amount, price, stopPrice float64, params map[string]interface{}) (*OrderGroup, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) CreateBracketOrder(req *BracketReq, params map[string]interface{}) (*OrderGroup, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) SetCancelAllCountdown(symbol string, countdown int64, params map[string]interface{}) *errs.Error {
+	return errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
 func (e *Exchange) SetLeverage(leverage float64, symbol string, params map[string]interface{}) (map[string]interface{}, *errs.Error) {
 	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
 
+func (e *Exchange) GetPositionMode(params map[string]interface{}) (bool, *errs.Error) {
+	return false, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) SetPositionMode(hedged bool, params map[string]interface{}) *errs.Error {
+	return errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) GetMarginMode(symbol string, params map[string]interface{}) (string, *errs.Error) {
+	return "", errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) SetMarginMode(symbol, mode string, params map[string]interface{}) *errs.Error {
+	return errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) GetMultiAssetsMode(params map[string]interface{}) (bool, *errs.Error) {
+	return false, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) SetMultiAssetsMode(enable bool, params map[string]interface{}) *errs.Error {
+	return errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) ModifyPositionMargin(symbol string, amount float64, changeType, posSide string, params map[string]interface{}) (*MarginChange, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchPositionMarginHistory(symbol string, since int64, limit int, params map[string]interface{}) ([]*MarginChange, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) Borrow(code string, amount float64, params map[string]interface{}) (*MarginLoan, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) Repay(code string, amount float64, params map[string]interface{}) (*MarginLoan, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchBorrowInterest(code string, since int64, limit int, params map[string]interface{}) ([]*BorrowInterest, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchMaxBorrowable(code string, params map[string]interface{}) (float64, *errs.Error) {
+	return 0, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchBorrowRates(codes []string, params map[string]interface{}) ([]*BorrowRate, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) Transfer(code string, amount float64, fromMarket, toMarket string, params map[string]interface{}) (*Transfer, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchTransfers(code string, since int64, limit int, params map[string]interface{}) ([]*Transfer, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchDepositAddress(code, network string, params map[string]interface{}) (*DepositAddress, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchDeposits(code string, since int64, limit int, params map[string]interface{}) ([]*Transaction, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchWithdrawals(code string, since int64, limit int, params map[string]interface{}) ([]*Transaction, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) Withdraw(code string, amount float64, address, tag, network string, params map[string]interface{}) (*Transaction, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchSubAccounts(params map[string]interface{}) ([]*SubAccount, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchSubAccountBalance(subId string, params map[string]interface{}) (*Balances, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) SubAccountTransfer(code string, amount float64, fromId, toId string, params map[string]interface{}) (*Transfer, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) CreateSubAccountApiKey(subId string, perms []string, params map[string]interface{}) (*SubAccApiKey, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchSubAccountApiKeys(subId string, params map[string]interface{}) ([]*SubAccApiKey, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchTradingFees(symbols []string, params map[string]interface{}) ([]*TradingFee, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchConvertQuote(fromCode, toCode string, amount float64, params map[string]interface{}) (*ConvertQuote, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) AcceptConvertQuote(quoteId string, params map[string]interface{}) (*ConvertTrade, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchConvertHistory(code string, since int64, limit int, params map[string]interface{}) ([]*ConvertTrade, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) ConvertDust(codes []string, params map[string]interface{}) ([]*ConvertTrade, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchLedger(code string, since int64, limit int, params map[string]interface{}) ([]*LedgerEntry, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchLiquidations(symbol string, since int64, limit int, params map[string]interface{}) ([]*Liquidation, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) FetchADLQuantile(symbols []string, params map[string]interface{}) ([]*ADLQuantile, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+/*
+SetTradingFees
+save fee rates of account, which will be used by CalculateFee instead of market fees
+保存账户的手续费率，CalculateFee将优先使用
+*/
+func (e *Exchange) SetTradingFees(accName string, fees []*TradingFee) *errs.Error {
+	acc, err := e.GetAccount(accName)
+	if err != nil {
+		return err
+	}
+	acc.LockLeverage.Lock()
+	for _, fee := range fees {
+		acc.TradingFees[fee.Symbol] = fee
+	}
+	acc.LockLeverage.Unlock()
+	return nil
+}
+
+/*
+GetAccFeeRates
+return maker/taker rates of market for account in ParamAccount: fetched TradingFees first, then
+Account.Fees of market type, finally Market.Maker/Taker
+返回账户在品种上的挂单/吃单费率：优先TradingFees，其次账户的Fees，最后是Market的费率
+*/
+func (e *Exchange) GetAccFeeRates(market *Market, params map[string]interface{}) (float64, float64) {
+	acc, err := e.GetAccount(e.GetAccName(params))
+	if err != nil {
+		return market.Maker, market.Taker
+	}
+	acc.LockLeverage.Lock()
+	defer acc.LockLeverage.Unlock()
+	if fee, ok := acc.TradingFees[market.Symbol]; ok {
+		return fee.Maker, fee.Taker
+	}
+	if acc.Fees != nil {
+		fee := acc.Fees.Main
+		if market.Linear {
+			fee = acc.Fees.Linear
+		} else if market.Inverse {
+			fee = acc.Fees.Inverse
+		}
+		if fee != nil && (fee.Maker != 0 || fee.Taker != 0) {
+			return fee.Maker, fee.Taker
+		}
+	}
+	return market.Maker, market.Taker
+}
+
+/*
+GetAccMarginMode
+return margin mode of symbol for account: MarginModes of symbol, then Account.MarginMode, finally ExgInfo.MarginMode
+返回账户在品种上的保证金模式
+*/
+func (e *Exchange) GetAccMarginMode(symbol, accName string) string {
+	acc, err := e.GetAccount(accName)
+	if err != nil {
+		return e.MarginMode
+	}
+	acc.LockLeverage.Lock()
+	defer acc.LockLeverage.Unlock()
+	if mode, ok := acc.MarginModes[symbol]; ok && mode != "" {
+		return mode
+	}
+	if acc.MarginMode != "" {
+		return acc.MarginMode
+	}
+	return e.MarginMode
+}
+
+/*
+IsHedgeMode
+whether account is in hedge mode(dual position side) for marketType, false if unknown
+账户在该市场是否为双向持仓，未知时返回false
+*/
+func (e *Exchange) IsHedgeMode(accName, marketType string) bool {
+	acc, err := e.GetAccount(accName)
+	if err != nil {
+		return false
+	}
+	acc.LockLeverage.Lock()
+	hedged := acc.PosModes[marketType]
+	acc.LockLeverage.Unlock()
+	return hedged
+}
+
 func (e *Exchange) LoadLeverageBrackets(reload bool, params map[string]interface{}) *errs.Error {
 	return errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
@@ -723,6 +988,22 @@ func (e *Exchange) UnWatchMarkPrices(symbols []string, params map[string]interfa
 	return errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
 
+func (e *Exchange) WatchTickers(symbols []string, params map[string]interface{}) (chan *Ticker, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) UnWatchTickers(symbols []string, params map[string]interface{}) *errs.Error {
+	return errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) WatchBookTickers(symbols []string, params map[string]interface{}) (chan *Ticker, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) UnWatchBookTickers(symbols []string, params map[string]interface{}) *errs.Error {
+	return errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
 func (e *Exchange) WatchTrades(symbols []string, params map[string]interface{}) (chan *Trade, *errs.Error) {
 	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
@@ -747,6 +1028,18 @@ func (e *Exchange) WatchAccountConfig(params map[string]interface{}) (chan *Acco
 	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
 
+func (e *Exchange) WatchMarginCalls(params map[string]interface{}) (chan *MarginCall, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) WatchLiquidations(symbols []string, params map[string]interface{}) (chan *Liquidation, *errs.Error) {
+	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
+func (e *Exchange) UnWatchLiquidations(symbols []string, params map[string]interface{}) *errs.Error {
+	return errs.NewMsg(errs.CodeNotImplement, "method not implement")
+}
+
 func (e *Exchange) CloseWsFile() {
 	if e.WsFile != nil {
 		err_ := e.WsFile.Close()
@@ -972,11 +1265,12 @@ func (e *Exchange) CalculateFee(symbol, odType, side string, amount float64, pri
 	if e.CalcFee != nil {
 		return e.CalcFee(market, currency, isMaker, amountDc, priceDc, params)
 	}
+	maker, taker := e.GetAccFeeRates(market, params)
 	feeRate := 0.0
 	if isMaker {
-		feeRate = market.Maker
+		feeRate = maker
 	} else {
-		feeRate = market.Taker
+		feeRate = taker
 	}
 	cost = cost.Mul(decimal.NewFromFloat(feeRate))
 	costVal, _ := cost.Float64()
@@ -1241,6 +1535,23 @@ func (e *Exchange) HasApi(key, market string) bool {
 	return false
 }
 
+/*
+GetPageConf
+get pagination rules of history api, fallback to default market, nil if not defined
+获取历史数据接口的分页规则，未定义时使用默认市场，都没有返回nil
+*/
+func (e *Exchange) GetPageConf(key, market string) *PageConf {
+	if items, ok := e.Pages[market]; ok {
+		if conf, ok := items[key]; ok {
+			return conf
+		}
+	}
+	if market != "" {
+		return e.GetPageConf(key, "")
+	}
+	return nil
+}
+
 func (e *Exchange) SetOnHost(cb func(n string) string) {
 	e.onHost = cb
 }
@@ -1400,6 +1711,8 @@ func (e *Exchange) GetAccName(params map[string]interface{}) string {
 }
 
 func (e *Exchange) GetAccount(id string) (*Account, *errs.Error) {
+	e.accLock.Lock()
+	defer e.accLock.Unlock()
 	isCmd := strings.HasPrefix(id, ":")
 	if id == "" || isCmd {
 		if e.DefAccName != "" {
@@ -1458,6 +1771,10 @@ func (e *Exchange) parseOptCreds() {
 				MarBalances:  map[string]*Balances{},
 				MarPositions: map[string][]*Position{},
 				Leverages:    map[string]int{},
+				PosModes:     map[string]bool{},
+				MarginModes:  map[string]string{},
+				MultiAssets:  map[string]bool{},
+				TradingFees:  map[string]*TradingFee{},
 				Data:         map[string]interface{}{},
 				LockBalance:  &sync.Mutex{},
 				LockPos:      &sync.Mutex{},
@@ -1468,9 +1785,31 @@ func (e *Exchange) parseOptCreds() {
 	}
 }
 
+/*
+AddAccount
+register account with api key at runtime, like key of sub-account, then use it by params[ParamAccount]
+运行时注册账户，如子账户的api key，之后通过params[ParamAccount]使用
+*/
+func (e *Exchange) AddAccount(name, apiKey, secret string) *Account {
+	acc := newAccount(name, map[string]interface{}{OptApiKey: apiKey, OptApiSecret: secret})
+	e.accLock.Lock()
+	if e.Accounts == nil {
+		e.Accounts = make(map[string]*Account)
+	}
+	e.Accounts[name] = acc
+	e.accLock.Unlock()
+	return acc
+}
+
 func newAccount(name string, cred map[string]interface{}) *Account {
 	var current = map[string]interface{}{}
 	maps.Copy(current, cred)
+	var accFees *ExgFee
+	fees := utils.PopMapVal(current, OptFees, map[string]map[string]float64{})
+	if len(fees) > 0 {
+		accFees = &ExgFee{}
+		accFees.Update(fees)
+	}
 	return &Account{
 		Name: name,
 		Creds: &Credential{
@@ -1480,6 +1819,12 @@ func newAccount(name string, cred map[string]interface{}) *Account {
 		MarPositions: map[string][]*Position{},
 		MarBalances:  map[string]*Balances{},
 		Leverages:    map[string]int{},
+		PosModes:     map[string]bool{},
+		MarginModes:  map[string]string{},
+		MultiAssets:  map[string]bool{},
+		TradingFees:  map[string]*TradingFee{},
+		Fees:         accFees,
+		MarginMode:   utils.PopMapVal(current, OptMarginMode, ""),
 		Data:         current,
 		LockBalance:  &sync.Mutex{},
 		LockPos:      &sync.Mutex{},
@@ -1533,6 +1878,9 @@ func (e *Exchange) Close() *errs.Error {
 		return err
 	}
 	e.WsCache = nil
+	e.odGroupLock.Lock()
+	e.OdGroupMgrs = nil
+	e.odGroupLock.Unlock()
 	err = e.SetReplay("")
 	if err != nil {
 		return err
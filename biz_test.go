@@ -1,6 +1,8 @@
 package banexg
 
 import (
+	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -69,3 +71,26 @@ func TestCalcFee(t *testing.T) {
 		t.Errorf("maker fee: %v", fee)
 	}
 }
+
+func TestAddAccountConcurrent(t *testing.T) {
+	e := &Exchange{Accounts: map[string]*Account{}, DefAccName: "main"}
+	e.AddAccount("main", "key", "secret")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			e.AddAccount("sub"+strconv.Itoa(i), "key", "secret")
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := e.GetAccount(""); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if acc, err := e.GetAccount("sub9"); err != nil || acc.Name != "sub9" {
+		t.Errorf("added account not found: %v", err)
+	}
+}
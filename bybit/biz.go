@@ -24,6 +24,7 @@ func (e *Bybit) Init() *errs.Error {
 	}
 	e.ExgInfo.NoHoliday = true
 	e.ExgInfo.FullDay = true
+	e.regReplayHandles()
 	return nil
 }
 
@@ -618,6 +619,8 @@ func (e *Bybit) FetchFundingRateHistory(symbol string, since int64, limit int, p
 	if symbol == "" {
 		return nil, errs.NewMsg(errs.CodeParamRequired, "symbol is required for bybit FetchFundingRateHistory")
 	}
+	// limit is the cap of total rows, the latest rows in [since, until] are returned like the api
+	total := limit
 	if limit <= 0 {
 		limit = maxFundRateBatch
 	}
@@ -639,28 +642,25 @@ func (e *Bybit) FetchFundingRateHistory(symbol string, since int64, limit int, p
 			until = since + int64(limit)*interval
 		}
 	}
-	if until > 0 {
-		args["endTime"] = until
-	}
-	items := make([]*banexg.FundingRate, 0)
-	for {
-		list, hasMore, err := e.getFundRateHis(market.Type, until, args)
-		if err != nil {
-			return nil, err
-		}
-		items = append(items, list...)
-		if !hasMore {
-			break
-		}
-		// 有未完成的数据，需要继续请求
-		intv := list[1].Timestamp - list[0].Timestamp
-		since = list[len(list)-1].Timestamp + intv
-		args["startTime"] = since
-	}
-	return items, nil
+	if until <= 0 {
+		return e.getFundRateHis(market.Type, args)
+	}
+	fetch := func(req *banexg.PageReq) ([]*banexg.FundingRate, string, *errs.Error) {
+		args["endTime"] = req.Until
+		list, err := e.getFundRateHis(market.Type, args)
+		return list, "", err
+	}
+	it := banexg.NewPageIter(context.Background(), since, until, args["limit"].(int), fetch, func(f *banexg.FundingRate) int64 {
+		return f.Timestamp
+	}, func(f *banexg.FundingRate) string {
+		return f.Symbol
+	})
+	// bybit返回范围内最新的数据，需要倒序分页
+	it.Backward = true
+	return it.Take(total)
 }
 
-func (e *Bybit) getFundRateHis(marketType string, until int64, args map[string]interface{}) ([]*banexg.FundingRate, bool, *errs.Error) {
+func (e *Bybit) getFundRateHis(marketType string, args map[string]interface{}) ([]*banexg.FundingRate, *errs.Error) {
 	method := MethodPublicGetV5MarketFundingHistory
 	tryNum := e.GetRetryNum("FetchFundingRateHistory", 1)
 	rsp := requestRetry[struct {
@@ -668,16 +668,12 @@ func (e *Bybit) getFundRateHis(marketType string, until int64, args map[string]i
 		List     []*FundRate `json:"list"`
 	}](e, method, args, tryNum)
 	if rsp.Error != nil {
-		return nil, false, rsp.Error
+		return nil, rsp.Error
 	}
-	var lastMS int64
 	var list = make([]*banexg.FundingRate, 0, len(rsp.Result.List))
 	for _, it := range rsp.Result.List {
 		code := e.SafeSymbol(it.Symbol, "", marketType)
 		stamp, _ := strconv.ParseInt(it.FundingRateTimestamp, 10, 64)
-		if stamp > lastMS {
-			lastMS = stamp
-		}
 		if code == "" {
 			continue
 		}
@@ -689,7 +685,5 @@ func (e *Bybit) getFundRateHis(marketType string, until int64, args map[string]i
 			Info:        it,
 		})
 	}
-	interval := int64(60 * 60 * 8 * 1000)
-	hasMore := until > 0 && len(rsp.Result.List) == maxFundRateBatch && lastMS+interval < until
-	return list, hasMore, nil
+	return list, nil
 }
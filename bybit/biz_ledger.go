@@ -0,0 +1,123 @@
+package bybit
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+)
+
+const maxLedgerBatch = 50 // 一次最多返回50个
+
+// logLedgerTypes type of transaction log to ledger type 流水类型对应的账本类型
+var logLedgerTypes = map[string]string{
+	"TRADE":        banexg.LedgerTrade,
+	"SETTLEMENT":   banexg.LedgerFunding,
+	"TRANSFER_IN":  banexg.LedgerTransfer,
+	"TRANSFER_OUT": banexg.LedgerTransfer,
+	"INTEREST":     banexg.LedgerInterest,
+	"FEE_REFUND":   banexg.LedgerRebate,
+	"BONUS":        banexg.LedgerRebate,
+}
+
+/*
+FetchLedger
+fetch cash flows of unified account, all categories if params.market is empty.
+查询统一账户的资金流水，未指定params.market时返回所有品类
+
+	:see: https://bybit-exchange.github.io/docs/v5/account/transaction-log
+	:param str code: unified currency code, all if empty
+	:param int since: start time in ms
+	:param int limit: max number of entries
+	:param str [params.market]: market type to filter
+	:param int [params.until]: end time in ms
+*/
+func (e *Bybit) FetchLedger(code string, since int64, limit int, params map[string]interface{}) ([]*banexg.LedgerEntry, *errs.Error) {
+	args := utils.SafeParams(params)
+	marketType := utils.PopMapVal(args, banexg.ParamMarket, "")
+	if marketType == banexg.MarketMargin {
+		marketType = banexg.MarketSpot
+	}
+	if marketType != "" {
+		args["category"] = marketType
+	}
+	if _, ok := args["accountType"]; !ok {
+		args["accountType"] = "UNIFIED"
+	}
+	if code != "" {
+		args["currency"] = code
+	}
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until > 0 {
+		args["endTime"] = until
+	}
+	if since > 0 {
+		args["startTime"] = since
+	}
+	if limit <= 0 {
+		limit = maxLedgerBatch
+	}
+	var res = make([]*banexg.LedgerEntry, 0)
+	tryNum := e.GetRetryNum("FetchLedger", 1)
+	for {
+		if since > 0 {
+			// records are newest first, read all in window to get the earliest 按时间倒序返回，需读完窗口内所有记录
+			args["limit"] = maxLedgerBatch
+		} else {
+			args["limit"] = min(limit-len(res), maxLedgerBatch)
+		}
+		rsp := requestRetry[struct {
+			List           []*TransactionLog `json:"list"`
+			NextPageCursor string            `json:"nextPageCursor"`
+		}](e, MethodPrivateGetV5AccountTransactionLog, args, tryNum)
+		if rsp.Error != nil {
+			return nil, rsp.Error
+		}
+		res = append(res, parseTransactionLogs(e, rsp.Result.List)...)
+		cursor := rsp.Result.NextPageCursor
+		if cursor == "" || len(rsp.Result.List) == 0 || since == 0 && len(res) >= limit {
+			break
+		}
+		args["cursor"] = cursor
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func parseTransactionLogs(e *Bybit, items []*TransactionLog) []*banexg.LedgerEntry {
+	var res = make([]*banexg.LedgerEntry, 0, len(items))
+	for _, it := range items {
+		ledgerType, ok := logLedgerTypes[it.Type]
+		if !ok {
+			ledgerType = banexg.LedgerOther
+		}
+		symbol := ""
+		if it.Symbol != "" {
+			symbol = e.SafeSymbol(it.Symbol, "", it.Category)
+		}
+		refId := it.TradeId
+		if refId == "" {
+			refId = it.OrderId
+		}
+		amount, _ := strconv.ParseFloat(it.Change, 64)
+		balance, _ := strconv.ParseFloat(it.CashBalance, 64)
+		stamp, _ := strconv.ParseInt(it.TransactionTime, 10, 64)
+		res = append(res, &banexg.LedgerEntry{
+			ID:        it.Id,
+			RefID:     refId,
+			Type:      ledgerType,
+			Code:      e.SafeCurrencyCode(it.Currency),
+			Symbol:    symbol,
+			Amount:    amount,
+			Balance:   balance,
+			Timestamp: stamp,
+			Info:      it,
+		})
+	}
+	return res
+}
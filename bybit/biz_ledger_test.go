@@ -0,0 +1,34 @@
+package bybit
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/utils"
+	"testing"
+)
+
+func TestParseTransactionLogs(t *testing.T) {
+	exg, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := `[{"id":"592324_XRPUSDT_161440249321","symbol":"XRPUSDT","category":"linear","side":"Buy",
+"transactionTime":"1700000000000","type":"TRADE","qty":"100","currency":"USDT","tradePrice":"0.61",
+"funding":"","fee":"0.0366","cashFlow":"0","change":"-0.0366","cashBalance":"1000.5","tradeId":"t1","orderId":"o1"},
+{"id":"2","symbol":"","category":"","transactionTime":"1700000001000","type":"TRANSFER_IN","currency":"USDT",
+"change":"100","cashBalance":"1100.5","tradeId":"","orderId":""}]`
+	var items []*TransactionLog
+	if err_ := utils.UnmarshalString(content, &items, utils.JsonNumDefault); err_ != nil {
+		t.Fatal(err_)
+	}
+	res := parseTransactionLogs(exg, items)
+	if len(res) != 2 {
+		t.Fatalf("expect 2 entries, got %d", len(res))
+	}
+	it := res[0]
+	if it.Type != banexg.LedgerTrade || it.Amount != -0.0366 || it.Balance != 1000.5 || it.RefID != "t1" || it.Timestamp != 1700000000000 {
+		t.Errorf("bad trade entry: %+v", it)
+	}
+	if res[1].Type != banexg.LedgerTransfer || res[1].Amount != 100 || res[1].Code != "USDT" {
+		t.Errorf("bad transfer entry: %+v", res[1])
+	}
+}
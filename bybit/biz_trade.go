@@ -0,0 +1,118 @@
+package bybit
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"strconv"
+	"strings"
+)
+
+const maxExecBatch = 100 // 一次最多返回100个
+
+/*
+FetchMyTrades
+fetch fills of account, execType other than Trade/BustTrade/AdlTrade (funding, settle) are skipped
+
+https://bybit-exchange.github.io/docs/v5/order/execution
+
+	:param int [params.until]: the latest time in ms to fetch trades for
+*/
+func (e *Bybit) FetchMyTrades(symbol string, since int64, limit int, params map[string]interface{}) ([]*banexg.MyTrade, *errs.Error) {
+	var args map[string]interface{}
+	var marketType, settle string
+	if symbol != "" {
+		argsIn, market, err := e.LoadArgsMarket(symbol, params)
+		if err != nil {
+			return nil, err
+		}
+		args = argsIn
+		args["symbol"] = market.ID
+		marketType, settle = market.Type, market.Settle
+	} else {
+		args = utils.SafeParams(params)
+		var err *errs.Error
+		marketType, _, err = e.LoadArgsMarketType(args)
+		if err != nil {
+			return nil, err
+		}
+	}
+	category := marketType
+	if category == banexg.MarketMargin {
+		category = banexg.MarketSpot
+	}
+	args["category"] = category
+	until := utils.PopMapVal(args, banexg.ParamUntil, int64(0))
+	if until > 0 {
+		args["endTime"] = until
+	}
+	if since > 0 {
+		args["startTime"] = since
+	}
+	if limit <= 0 {
+		limit = maxExecBatch
+	}
+	var res = make([]*banexg.MyTrade, 0)
+	tryNum := e.GetRetryNum("FetchMyTrades", 1)
+	for {
+		args["limit"] = min(limit-len(res), maxExecBatch)
+		rsp := requestRetry[struct {
+			Category       string       `json:"category"`
+			List           []*Execution `json:"list"`
+			NextPageCursor string       `json:"nextPageCursor"`
+		}](e, MethodPrivateGetV5ExecutionList, args, tryNum)
+		if rsp.Error != nil {
+			return nil, rsp.Error
+		}
+		res = append(res, parseExecutions(e, marketType, settle, rsp.Result.List)...)
+		cursor := rsp.Result.NextPageCursor
+		if cursor == "" || len(rsp.Result.List) == 0 || len(res) >= limit {
+			break
+		}
+		args["cursor"] = cursor
+	}
+	return res, nil
+}
+
+func parseExecutions(e *Bybit, marketType, settle string, items []*Execution) []*banexg.MyTrade {
+	var res = make([]*banexg.MyTrade, 0, len(items))
+	for _, it := range items {
+		if it.ExecType != "" && it.ExecType != "Trade" && it.ExecType != "BustTrade" && it.ExecType != "AdlTrade" {
+			continue
+		}
+		price, _ := strconv.ParseFloat(it.ExecPrice, 64)
+		amount, _ := strconv.ParseFloat(it.ExecQty, 64)
+		cost, _ := strconv.ParseFloat(it.ExecValue, 64)
+		fee, _ := strconv.ParseFloat(it.ExecFee, 64)
+		feeRate, _ := strconv.ParseFloat(it.FeeRate, 64)
+		stamp, _ := strconv.ParseInt(it.ExecTime, 10, 64)
+		market := e.SafeMarket(it.Symbol, "", marketType)
+		feeCurr := it.FeeCurrency
+		if feeCurr == "" {
+			feeCurr = settle
+			if feeCurr == "" {
+				feeCurr = market.Settle
+			}
+		} else {
+			feeCurr = e.SafeCurrencyCode(feeCurr)
+		}
+		res = append(res, &banexg.MyTrade{
+			Trade: banexg.Trade{
+				ID:        it.ExecId,
+				Symbol:    market.Symbol,
+				Side:      strings.ToLower(it.Side),
+				Type:      strings.ToLower(it.OrderType),
+				Amount:    amount,
+				Price:     price,
+				Cost:      cost,
+				Order:     it.OrderId,
+				Timestamp: stamp,
+				Maker:     it.IsMaker,
+				Fee:       &banexg.Fee{IsMaker: it.IsMaker, Currency: feeCurr, Cost: fee, Rate: feeRate},
+			},
+			ClientID: it.OrderLinkId,
+			Info:     it,
+		})
+	}
+	return res
+}
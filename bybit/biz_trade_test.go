@@ -0,0 +1,33 @@
+package bybit
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/utils"
+	"testing"
+)
+
+func TestParseExecutions(t *testing.T) {
+	exg, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := `[{"symbol":"BTCUSDT","orderId":"f6e324ff","orderLinkId":"cli1","side":"Sell","orderType":"Limit",
+"execId":"e1","execPrice":"30000","execQty":"0.01","execValue":"300","execFee":"0.06","execType":"Trade",
+"execTime":"1700000000000","feeRate":"0.0002","isMaker":true},
+{"symbol":"BTCUSDT","orderId":"","side":"Buy","execId":"e2","execType":"Funding","execTime":"1700000001000"}]`
+	var items []*Execution
+	if err_ := utils.UnmarshalString(content, &items, utils.JsonNumDefault); err_ != nil {
+		t.Fatal(err_)
+	}
+	res := parseExecutions(exg, banexg.MarketLinear, "USDT", items)
+	if len(res) != 1 {
+		t.Fatalf("funding should be skipped, got %d", len(res))
+	}
+	td := res[0]
+	if td.ID != "e1" || td.Side != banexg.OdSideSell || td.Type != "limit" || td.ClientID != "cli1" || td.Timestamp != 1700000000000 {
+		t.Errorf("bad trade: %+v", td)
+	}
+	if !td.Maker || td.Cost != 300 || td.Fee.Cost != 0.06 || td.Fee.Currency != "USDT" {
+		t.Errorf("bad fee: %+v", td.Fee)
+	}
+}
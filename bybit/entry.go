@@ -18,12 +18,22 @@ func New(Options map[string]interface{}) (*Bybit, *errs.Error) {
 			Options:   Options,
 			Hosts: &banexg.ExgHosts{
 				Test: map[string]string{
-					HostPublic:  "https://api-testnet." + hostName,
-					HostPrivate: "https://api-testnet." + hostName,
+					HostPublic:           "https://api-testnet." + hostName,
+					HostPrivate:          "https://api-testnet." + hostName,
+					banexg.MarketSpot:    "wss://stream-testnet." + hostName + "/v5/public/spot",
+					banexg.MarketMargin:  "wss://stream-testnet." + hostName + "/v5/public/spot",
+					banexg.MarketLinear:  "wss://stream-testnet." + hostName + "/v5/public/linear",
+					banexg.MarketInverse: "wss://stream-testnet." + hostName + "/v5/public/inverse",
+					banexg.MarketOption:  "wss://stream-testnet." + hostName + "/v5/public/option",
 				},
 				Prod: map[string]string{
-					HostPublic:  "https://api." + hostName,
-					HostPrivate: "https://api." + hostName,
+					HostPublic:           "https://api." + hostName,
+					HostPrivate:          "https://api." + hostName,
+					banexg.MarketSpot:    "wss://stream." + hostName + "/v5/public/spot",
+					banexg.MarketMargin:  "wss://stream." + hostName + "/v5/public/spot",
+					banexg.MarketLinear:  "wss://stream." + hostName + "/v5/public/linear",
+					banexg.MarketInverse: "wss://stream." + hostName + "/v5/public/inverse",
+					banexg.MarketOption:  "wss://stream." + hostName + "/v5/public/option",
 				},
 				Www: "https://www.bybit.com",
 				Doc: []string{
@@ -380,6 +390,8 @@ func New(Options map[string]interface{}) (*Bybit, *errs.Error) {
 					banexg.ApiFetchAccountPositions: banexg.HasOk,
 					banexg.ApiFetchPositions:        banexg.HasOk,
 					banexg.ApiFetchOpenOrders:       banexg.HasOk,
+					banexg.ApiFetchMyTrades:         banexg.HasOk,
+					banexg.ApiFetchLedger:           banexg.HasOk,
 					banexg.ApiCreateOrder:           banexg.HasOk,
 					banexg.ApiEditOrder:             banexg.HasOk,
 					banexg.ApiCancelOrder:           banexg.HasOk,
@@ -391,6 +403,10 @@ func New(Options map[string]interface{}) (*Bybit, *errs.Error) {
 					banexg.ApiUnWatchOHLCVs:         banexg.HasOk,
 					banexg.ApiWatchMarkPrices:       banexg.HasOk,
 					banexg.ApiUnWatchMarkPrices:     banexg.HasOk,
+					banexg.ApiWatchTickers:          banexg.HasOk,
+					banexg.ApiUnWatchTickers:        banexg.HasOk,
+					banexg.ApiWatchBookTickers:      banexg.HasOk,
+					banexg.ApiUnWatchBookTickers:    banexg.HasOk,
 					banexg.ApiWatchTrades:           banexg.HasOk,
 					banexg.ApiUnWatchTrades:         banexg.HasOk,
 					banexg.ApiWatchMyTrades:         banexg.HasOk,
@@ -399,12 +415,25 @@ func New(Options map[string]interface{}) (*Bybit, *errs.Error) {
 					banexg.ApiWatchAccountConfig:    banexg.HasFail,
 				},
 			},
+			Pages: map[string]map[string]*banexg.PageConf{
+				"": {
+					// klines, funding rates and executions in range are returned newest first 范围内的数据按时间倒序返回
+					banexg.ApiFetchOHLCV:              {DefLimit: 200, MaxLimit: 1000, Backward: true},
+					banexg.ApiFetchFundingRateHistory: {DefLimit: maxFundRateBatch, MaxLimit: maxFundRateBatch, Backward: true},
+					banexg.ApiFetchMyTrades:           {DefLimit: maxExecBatch, MaxRange: 7 * 24 * 3600 * 1000, Interval: 100, Backward: true},
+				},
+			},
 			CredKeys: map[string]bool{"ApiKey": true, "Secret": true},
 		},
+		wsRequestId: make(map[string]int),
+		wsTickers:   make(map[string]map[string]interface{}),
+		wsBooks:     make(map[string]*banexg.Ticker),
 	}
 	exg.Sign = makeSign(exg)
 	exg.FetchCurrencies = makeFetchCurr(exg)
 	exg.FetchMarkets = makeFetchMarkets(exg)
+	exg.OnWsMsg = makeHandleWsMsg(exg)
+	exg.OnWsReCon = makeHandleWsReCon(exg)
 	err := exg.Init()
 	return exg, err
 }
@@ -2,11 +2,16 @@ package bybit
 
 import (
 	"github.com/banbox/banexg"
+	"sync"
 )
 
 type Bybit struct {
 	*banexg.Exchange
-	RecvWindow int // 允许的和服务器最大毫秒时间差
+	RecvWindow  int // 允许的和服务器最大毫秒时间差
+	wsRequestId map[string]int
+	wsTickers   map[string]map[string]interface{} // chanKey+symbol: 合并增量后的ticker字段
+	wsBooks     map[string]*banexg.Ticker         // chanKey+symbol: 最优买卖盘
+	wsLock      sync.Mutex
 }
 
 /*
@@ -190,3 +195,60 @@ type FundRate struct {
 	FundingRate          string `json:"fundingRate"`
 	FundingRateTimestamp string `json:"fundingRateTimestamp"`
 }
+
+/*
+Execution
+fill of order from /v5/execution/list
+*/
+type Execution struct {
+	Symbol      string `json:"symbol"`
+	OrderId     string `json:"orderId"`
+	OrderLinkId string `json:"orderLinkId"`
+	Side        string `json:"side"`
+	OrderType   string `json:"orderType"`
+	ExecId      string `json:"execId"`
+	ExecPrice   string `json:"execPrice"`
+	ExecQty     string `json:"execQty"`
+	ExecValue   string `json:"execValue"`
+	ExecFee     string `json:"execFee"`
+	ExecType    string `json:"execType"`
+	ExecTime    string `json:"execTime"`
+	FeeRate     string `json:"feeRate"`
+	FeeCurrency string `json:"feeCurrency"` // spot only
+	IsMaker     bool   `json:"isMaker"`
+	ClosedSize  string `json:"closedSize"`
+}
+
+/*
+TransactionLog
+cash flow of unified account from /v5/account/transaction-log
+*/
+type TransactionLog struct {
+	Id              string `json:"id"`
+	Symbol          string `json:"symbol"`
+	Category        string `json:"category"`
+	Side            string `json:"side"`
+	TransactionTime string `json:"transactionTime"`
+	Type            string `json:"type"`
+	Qty             string `json:"qty"`
+	Currency        string `json:"currency"`
+	TradePrice      string `json:"tradePrice"`
+	Funding         string `json:"funding"`
+	Fee             string `json:"fee"`
+	CashFlow        string `json:"cashFlow"`
+	Change          string `json:"change"`
+	CashBalance     string `json:"cashBalance"`
+	TradeId         string `json:"tradeId"`
+	OrderId         string `json:"orderId"`
+}
+
+/*
+*****************************   Websocket   ***********************************
+ */
+
+type WsBookData struct {
+	Symbol   string      `json:"s"`
+	Bids     [][2]string `json:"b"`
+	Asks     [][2]string `json:"a"`
+	UpdateID int64       `json:"u"`
+}
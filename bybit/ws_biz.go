@@ -0,0 +1,396 @@
+package bybit
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
+	"github.com/banbox/banexg/utils"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	wsBatchNum   = 10 // 每次订阅请求最多10个topic
+	wsPingPeriod = time.Second * 20
+)
+
+func makeHandleWsMsg(e *Bybit) banexg.FuncOnWsMsg {
+	return func(client *banexg.WsClient, item *banexg.WsMsg) {
+		if item.IsArray {
+			log.Warn("unhandle ws msg", zap.String("msg", item.Text))
+			return
+		}
+		msg := item.Object
+		if op, ok := msg["op"]; ok {
+			// 订阅、取消订阅、心跳的返回
+			if success, _ := msg["success"]; success == "false" {
+				log.Error("ws op fail", zap.String("op", op), zap.String("msg", msg["ret_msg"]))
+			} else if op != "ping" && op != "pong" {
+				log.Debug("ws op ok", zap.String("op", op), zap.String("req", msg["req_id"]))
+			}
+			return
+		}
+		topic, _ := msg["topic"]
+		name, _, _ := strings.Cut(topic, ".")
+		switch name {
+		case "tickers":
+			e.handleTickers(client, msg)
+		case "orderbook":
+			e.handleBookTicker(client, msg)
+		default:
+			log.Warn("unhandle ws msg", zap.String("msg", item.Text))
+		}
+	}
+}
+
+func makeHandleWsReCon(e *Bybit) banexg.FuncOnWsReCon {
+	return func(client *banexg.WsClient, connID int) *errs.Error {
+		topics := client.GetSubKeys(connID)
+		if len(topics) == 0 {
+			return nil
+		}
+		zapFields := []zap.Field{zap.String("url", client.URL), zap.Int("id", connID),
+			zap.Int("job", len(topics))}
+		log.Info("re-subscribe ws", zapFields...)
+		err := e.writeWsTopics(client, connID, true, topics)
+		if err != nil {
+			return err
+		}
+		log.Info("re-subscribe ok", zapFields...)
+		return nil
+	}
+}
+
+// GetWsClient get WsClient for public data
+func (e *Bybit) GetWsClient(marType string) (*banexg.WsClient, *errs.Error) {
+	host := e.GetHost(marType)
+	if host == "" {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "unsupport wss host for %s: %s", e.Name, marType)
+	}
+	_, hasOld := e.WSClients["@"+host]
+	client, err := e.GetClient(host, marType, "")
+	if err != nil {
+		return nil, err
+	}
+	if !hasOld && e.WsDecoder == nil {
+		go e.keepWsAlive(client)
+	}
+	return client, nil
+}
+
+/*
+keepWsAlive
+bybit closes connections without heartbeat, send ping every 20 seconds
+bybit会关闭无心跳的连接，每20秒发送一次ping
+*/
+func (e *Bybit) keepWsAlive(client *banexg.WsClient) {
+	ping := map[string]interface{}{"op": "ping"}
+	for {
+		time.Sleep(wsPingPeriod)
+		if cur, ok := e.WSClients[client.Key]; !ok || cur != client || len(client.Conns) == 0 {
+			return
+		}
+		for _, conn := range client.Conns {
+			if !conn.IsOK() {
+				continue
+			}
+			if err := client.Write(conn, ping, nil); err != nil {
+				log.Warn("ws ping fail", zap.String("url", client.URL), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (e *Bybit) nextId(client *banexg.WsClient) int {
+	e.wsLock.Lock()
+	defer e.wsLock.Unlock()
+	requestId := e.wsRequestId[client.URL] + 1
+	e.wsRequestId[client.URL] = requestId
+	return requestId
+}
+
+/*
+writeWsTopics
+subscribe or unsubscribe topics in batches
+分批订阅或取消订阅topic
+*/
+func (e *Bybit) writeWsTopics(client *banexg.WsClient, connID int, isSub bool, topics []string) *errs.Error {
+	if !isSub {
+		// 取消订阅需发送到订阅时的连接
+		byConn := make(map[int][]string)
+		for _, topic := range topics {
+			if cid, ok := client.SubscribeKeys[topic]; ok {
+				byConn[cid] = append(byConn[cid], topic)
+			}
+		}
+		for cid, items := range byConn {
+			conn, _ := client.Conns[cid]
+			client.UpdateSubs(cid, false, items)
+			for start := 0; start < len(items); start += wsBatchNum {
+				batch := items[start:min(start+wsBatchNum, len(items))]
+				if err := e.writeWsOp(client, conn, "unsubscribe", batch); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for start := 0; start < len(topics); start += wsBatchNum {
+		batch := topics[start:min(start+wsBatchNum, len(topics))]
+		_, conn := client.UpdateSubs(connID, true, batch)
+		if err := e.writeWsOp(client, conn, "subscribe", batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Bybit) writeWsOp(client *banexg.WsClient, conn *banexg.AsyncConn, op string, topics []string) *errs.Error {
+	var request = map[string]interface{}{
+		"op":     op,
+		"args":   topics,
+		"req_id": strconv.Itoa(e.nextId(client)),
+	}
+	return client.Write(conn, request, nil)
+}
+
+func (e *Bybit) WatchTickers(symbols []string, params map[string]interface{}) (chan *banexg.Ticker, *errs.Error) {
+	chanKey, args, err := e.prepareTickers(true, "tickers", symbols, params)
+	if err != nil {
+		return nil, err
+	}
+	create := func(cap int) chan *banexg.Ticker { return make(chan *banexg.Ticker, cap) }
+	out := banexg.GetWsOutChan(e.Exchange, chanKey, create, args)
+	e.AddWsChanRefs(chanKey, symbols...)
+	e.DumpWS("WatchTickers", symbols)
+	return out, nil
+}
+
+func (e *Bybit) UnWatchTickers(symbols []string, params map[string]interface{}) *errs.Error {
+	chanKey, _, err := e.prepareTickers(false, "tickers", symbols, params)
+	if err != nil {
+		return err
+	}
+	e.DelWsChanRefs(chanKey, symbols...)
+	return nil
+}
+
+func (e *Bybit) WatchBookTickers(symbols []string, params map[string]interface{}) (chan *banexg.Ticker, *errs.Error) {
+	chanKey, args, err := e.prepareTickers(true, "orderbook.1", symbols, params)
+	if err != nil {
+		return nil, err
+	}
+	create := func(cap int) chan *banexg.Ticker { return make(chan *banexg.Ticker, cap) }
+	out := banexg.GetWsOutChan(e.Exchange, chanKey, create, args)
+	e.AddWsChanRefs(chanKey, symbols...)
+	e.DumpWS("WatchBookTickers", symbols)
+	return out, nil
+}
+
+func (e *Bybit) UnWatchBookTickers(symbols []string, params map[string]interface{}) *errs.Error {
+	chanKey, _, err := e.prepareTickers(false, "orderbook.1", symbols, params)
+	if err != nil {
+		return err
+	}
+	e.DelWsChanRefs(chanKey, symbols...)
+	return nil
+}
+
+/*
+prepareTickers
+name: tickers/orderbook.1; bybit has no all-market stream, symbols is required
+name: tickers/orderbook.1；bybit没有全市场推送，symbols必填
+*/
+func (e *Bybit) prepareTickers(isSub bool, name string, symbols []string, params map[string]interface{}) (string, map[string]interface{}, *errs.Error) {
+	if len(symbols) == 0 {
+		return "", nil, errs.NewMsg(errs.CodeParamRequired, "symbols is required for bybit %s", name)
+	}
+	args := utils.SafeParams(params)
+	marketType, _, err := e.LoadArgsMarketType(args, symbols...)
+	if err != nil {
+		return "", nil, err
+	}
+	client, err := e.GetWsClient(marketType)
+	if err != nil {
+		return "", nil, err
+	}
+	topics := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		market, err := e.GetMarket(symbol)
+		if err != nil {
+			return "", nil, err
+		}
+		topics = append(topics, name+"."+market.ID)
+	}
+	err = e.writeWsTopics(client, 0, isSub, topics)
+	if err != nil {
+		return "", nil, err
+	}
+	if !isSub {
+		e.wsLock.Lock()
+		prefix := client.Prefix(name)
+		for _, symbol := range symbols {
+			delete(e.wsTickers, prefix+symbol)
+			delete(e.wsBooks, prefix+symbol)
+		}
+		e.wsLock.Unlock()
+	}
+	return client.Prefix(name), args, nil
+}
+
+/*
+handleTickers
+linear/inverse/option push snapshot first and then deltas with changed fields only, merge before output
+linear/inverse/option先推送快照，然后仅推送变化的字段，输出前需合并
+*/
+func (e *Bybit) handleTickers(client *banexg.WsClient, msg map[string]string) {
+	var data = make(map[string]interface{})
+	err_ := utils.UnmarshalString(msg["data"], &data, utils.JsonNumStr)
+	if err_ != nil {
+		log.Error("unmarshal ws tickers fail", zap.String("data", msg["data"]), zap.Error(err_))
+		return
+	}
+	marketId, _ := data["symbol"].(string)
+	symbol := e.SafeSymbol(marketId, "", client.MarketType)
+	if symbol == "" {
+		return
+	}
+	chanKey := client.Prefix("tickers")
+	e.wsLock.Lock()
+	cacheKey := chanKey + symbol
+	state, ok := e.wsTickers[cacheKey]
+	if !ok || msg["type"] == "snapshot" {
+		state = data
+		e.wsTickers[cacheKey] = state
+	} else {
+		for k, v := range data {
+			state[k] = v
+		}
+	}
+	ticker, err := parseWsTicker(e, client.MarketType, state)
+	e.wsLock.Unlock()
+	if err != nil {
+		log.Error("parse ws ticker fail", zap.String("symbol", symbol), zap.Error(err))
+		return
+	}
+	ticker.Symbol = symbol
+	ticker.TimeStamp, _ = utils.SafeMapVal(msg, "ts", int64(0))
+	banexg.WriteOutChan(e.Exchange, chanKey, ticker, true)
+}
+
+func parseWsTicker(e *Bybit, marketType string, state map[string]interface{}) (*banexg.Ticker, *errs.Error) {
+	if marketType == banexg.MarketOption {
+		// 期权推送的盘口字段名与rest接口不同
+		state = utils.SafeParams(state)
+		for _, k := range []string{"bidPrice", "bidSize", "askPrice", "askSize"} {
+			if v, ok := state[k]; ok {
+				state[k[:3]+"1"+k[3:]] = v
+			}
+		}
+	}
+	text, err_ := utils.MarshalString(state)
+	if err_ != nil {
+		return nil, errs.New(errs.CodeMarshalFail, err_)
+	}
+	var item ITicker
+	switch marketType {
+	case banexg.MarketOption:
+		item = &OptionTicker{}
+	case banexg.MarketLinear, banexg.MarketInverse:
+		item = &FutureTicker{}
+	default:
+		item = &SpotTicker{}
+	}
+	if err_ = utils.UnmarshalString(text, item, utils.JsonNumDefault); err_ != nil {
+		return nil, errs.New(errs.CodeUnmarshalFail, err_)
+	}
+	return item.ToStdTicker(e, marketType), nil
+}
+
+/*
+handleBookTicker
+orderbook.1: the best bid/ask, size 0 in delta means the level is removed
+orderbook.1: 最优买卖盘，增量中数量为0表示该档位被移除
+*/
+func (e *Bybit) handleBookTicker(client *banexg.WsClient, msg map[string]string) {
+	var data = &WsBookData{}
+	err_ := utils.UnmarshalString(msg["data"], data, utils.JsonNumDefault)
+	if err_ != nil {
+		log.Error("unmarshal ws book ticker fail", zap.String("data", msg["data"]), zap.Error(err_))
+		return
+	}
+	symbol := e.SafeSymbol(data.Symbol, "", client.MarketType)
+	if symbol == "" {
+		return
+	}
+	chanKey := client.Prefix("orderbook.1")
+	e.wsLock.Lock()
+	cacheKey := chanKey + symbol
+	book, ok := e.wsBooks[cacheKey]
+	if !ok || msg["type"] == "snapshot" {
+		book = &banexg.Ticker{Symbol: symbol}
+		e.wsBooks[cacheKey] = book
+	}
+	applyBookLevel(&book.Bid, &book.BidVolume, data.Bids)
+	applyBookLevel(&book.Ask, &book.AskVolume, data.Asks)
+	book.TimeStamp, _ = utils.SafeMapVal(msg, "ts", int64(0))
+	res := *book
+	e.wsLock.Unlock()
+	res.Info = data
+	banexg.WriteOutChan(e.Exchange, chanKey, &res, true)
+}
+
+func applyBookLevel(price, size *float64, levels [][2]string) {
+	for _, lv := range levels {
+		p, _ := strconv.ParseFloat(lv[0], 64)
+		s, _ := strconv.ParseFloat(lv[1], 64)
+		if s == 0 {
+			if p == *price {
+				*price, *size = 0, 0
+			}
+			continue
+		}
+		*price, *size = p, s
+	}
+}
+
+func (e *Bybit) regReplayHandles() {
+	e.WsReplayFn = map[string]func(item *banexg.WsLog) *errs.Error{
+		"WatchTickers": func(item *banexg.WsLog) *errs.Error {
+			var symbols = make([]string, 0)
+			err_ := utils.UnmarshalString(item.Content, &symbols, utils.JsonNumDefault)
+			if err_ != nil {
+				return errs.New(errs.CodeUnmarshalFail, err_)
+			}
+			log.Debug("replay WatchTickers", zap.Strings("codes", symbols))
+			_, err := e.WatchTickers(symbols, nil)
+			return err
+		},
+		"WatchBookTickers": func(item *banexg.WsLog) *errs.Error {
+			var symbols = make([]string, 0)
+			err_ := utils.UnmarshalString(item.Content, &symbols, utils.JsonNumDefault)
+			if err_ != nil {
+				return errs.New(errs.CodeUnmarshalFail, err_)
+			}
+			log.Debug("replay WatchBookTickers", zap.Strings("codes", symbols))
+			_, err := e.WatchBookTickers(symbols, nil)
+			return err
+		},
+		"wsMsg": func(item *banexg.WsLog) *errs.Error {
+			var arr = make([]string, 0)
+			err_ := utils.UnmarshalString(item.Content, &arr, utils.JsonNumDefault)
+			if err_ != nil {
+				return errs.New(errs.CodeUnmarshalFail, err_)
+			}
+			client, err := e.GetClient(arr[0], arr[1], arr[2])
+			if err != nil {
+				return err
+			}
+			log.Debug("replay wsMsg", zap.String("msg", arr[3]))
+			client.HandleRawMsg([]byte(arr[3]))
+			return nil
+		},
+	}
+}
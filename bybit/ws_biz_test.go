@@ -0,0 +1,55 @@
+package bybit
+
+import (
+	"fmt"
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/log"
+	"testing"
+)
+
+func TestWatchTickers(t *testing.T) {
+	exg := getBybit(nil)
+	symbols := []string{"BTC/USDT:USDT", "ETH/USDT:USDT"}
+	out, err := exg.WatchTickers(symbols, nil)
+	if err != nil {
+		panic(err)
+	}
+	books, err := exg.WatchBookTickers(symbols, nil)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("start watching tickers")
+	for {
+		select {
+		case tk, ok := <-out:
+			if !ok {
+				log.Info("read tickers chan fail, break")
+				return
+			}
+			fmt.Printf("ticker %s: last %v, bid %v, ask %v\n", tk.Symbol, tk.Last, tk.Bid, tk.Ask)
+		case tk, ok := <-books:
+			if !ok {
+				log.Info("read book tickers chan fail, break")
+				return
+			}
+			fmt.Printf("book %s: bid %v x %v, ask %v x %v\n", tk.Symbol, tk.Bid, tk.BidVolume, tk.Ask, tk.AskVolume)
+		}
+	}
+}
+
+func TestApplyBookLevel(t *testing.T) {
+	book := &banexg.Ticker{}
+	applyBookLevel(&book.Bid, &book.BidVolume, [][2]string{{"100.5", "2"}})
+	if book.Bid != 100.5 || book.BidVolume != 2 {
+		t.Errorf("bad snapshot level: %v %v", book.Bid, book.BidVolume)
+	}
+	// 其他价格的删除不影响当前档位
+	applyBookLevel(&book.Bid, &book.BidVolume, [][2]string{{"100.4", "0"}})
+	if book.Bid != 100.5 {
+		t.Errorf("bad delete of other level: %v", book.Bid)
+	}
+	applyBookLevel(&book.Bid, &book.BidVolume, [][2]string{{"100.5", "0"}, {"100.6", "1"}})
+	if book.Bid != 100.6 || book.BidVolume != 1 {
+		t.Errorf("bad delta level: %v %v", book.Bid, book.BidVolume)
+	}
+}
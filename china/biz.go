@@ -371,6 +371,10 @@ func (e *China) FetchOpenOrders(symbol string, since int64, limit int, params ma
 	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
 
+func (e *China) ValidateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*banexg.OrderCheck, *errs.Error) {
+	return banexg.CheckOrder(e, symbol, odType, side, amount, price, params)
+}
+
 func (e *China) CreateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*banexg.Order, *errs.Error) {
 	return nil, errs.NewMsg(errs.CodeNotImplement, "method not implement")
 }
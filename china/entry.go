@@ -45,6 +45,7 @@ func New(Options map[string]interface{}) (*China, *errs.Error) {
 					banexg.ApiFetchPositions:        banexg.HasFail,
 					banexg.ApiFetchOpenOrders:       banexg.HasFail,
 					banexg.ApiCreateOrder:           banexg.HasFail,
+					banexg.ApiValidateOrder:         banexg.HasOk,
 					banexg.ApiEditOrder:             banexg.HasFail,
 					banexg.ApiCancelOrder:           banexg.HasFail,
 					banexg.ApiSetLeverage:           banexg.HasFail,
@@ -427,6 +427,21 @@ func (m *Market) GetTradeTimes() [][2]int64 {
 	return times
 }
 
+/*
+Notional
+order value in quote currency, used to check Limits.Cost. Value of inverse contract is amount*ContractSize
+订单价值，以报价币计价，用于检查Limits.Cost。反向合约价值为amount*ContractSize
+*/
+func (m *Market) Notional(amount, price float64) float64 {
+	if m.Contract && m.ContractSize > 0 {
+		if m.Inverse {
+			return amount * m.ContractSize
+		}
+		return amount * price * m.ContractSize
+	}
+	return amount * price
+}
+
 func GetHostRetryWait(host string, randAdd bool) int64 {
 	var waitMS int64
 	hostWaitLock.Lock()
@@ -38,6 +38,19 @@ const (
 	ParamLimit              = "limit"
 	ParamUntil              = "until"
 	ParamRetry              = "retry"
+	ParamStopLimitPrice     = "stopLimitPrice" // limit price of stop loss leg for OCO, market if empty
+	ParamLeverage           = "leverage"
+	ParamValidate           = "validate"        // call ValidateOrder before CreateOrder
+	ParamAutoRound          = "autoRound"       // round amount/price to precision in ValidateOrder
+	ParamCheckBalance       = "checkBalance"    // check free balance in ValidateOrder
+	ParamWsApi              = "wsApi"           // send order requests over websocket api, default OptWsApi
+	ParamPortfolioMargin    = "portfolioMargin" // use portfolio margin(papi) routes, default OptPortfolioMargin
+	ParamRefresh            = "refresh"         // fetch from exchange instead of cached account config
+	ParamFromMarket         = "fromMarket"      // market type of source wallet for FetchTransfers
+	ParamToMarket           = "toMarket"        // market type of target wallet for FetchTransfers
+	ParamBroker             = "broker"          // use broker api for sub-accounts, subId is broker subaccountId instead of email
+	ParamApplyFees          = "applyFees"       // save fetched fee rates to account for CalculateFee
+	ParamConditional        = "conditional"     // conditional(strategy) orders of contracts in portfolio margin account
 )
 
 var (
@@ -63,6 +76,7 @@ var (
 	hostFlowChans   = make(map[string]chan struct{})
 	hostFlowLock    sync.Mutex
 	HostHttpConcurr = 3 // Maximum concurrent number of HTTP requests per domain name 每个域名发起http请求最大并发数
+	BatchOdConcurr  = 5 // Maximum concurrent requests when emulating batch orders 模拟批量订单时的最大并发数
 )
 
 const (
@@ -102,9 +116,12 @@ const (
 	OptDebugApi        = "DebugApi"
 	OptApiCaches       = "ApiCaches"
 	OptFees            = "Fees"
+	OptMarginMode      = "MarginMode" // default margin mode, also supported in Creds for each account
 	OptDumpPath        = "DumpPath"
 	OptDumpBatchSize   = "DumpBatchSize"
 	OptReplayPath      = "ReplayPath"
+	OptWsApi           = "WsApi"           // send order requests over websocket api if supported, fallback to rest
+	OptPortfolioMargin = "PortfolioMargin" // route binance orders, balances, positions and user stream to papi
 )
 
 const (
@@ -118,7 +135,8 @@ const (
 	MarketMargin  = "margin" // 保证金杠杆现货交易 margin trade
 	MarketLinear  = "linear"
 	MarketInverse = "inverse"
-	MarketOption  = "option" // 期权 for option contracts
+	MarketOption  = "option"  // 期权 for option contracts
+	MarketFunding = "funding" // 资金账户 funding wallet, only used for transfer
 
 	MarketSwap   = "swap"   // 永续合约 for perpetual swap futures that don't have a delivery date
 	MarketFuture = "future" // 有交割日的期货 for expiring futures contracts that have a delivery/settlement date
@@ -139,6 +157,39 @@ const (
 	OdStatusExpired    = "expired"
 )
 
+// status of transfer, deposit and withdrawal 划转、充值、提现的状态
+const (
+	TxStatusPending  = "pending"
+	TxStatusOk       = "ok"
+	TxStatusFailed   = "failed"
+	TxStatusCanceled = "canceled"
+)
+
+const (
+	TxTypeDeposit    = "deposit"
+	TxTypeWithdrawal = "withdrawal"
+)
+
+// types of LedgerEntry 账本记录类型
+const (
+	LedgerTrade    = "trade"
+	LedgerPnl      = "pnl"
+	LedgerFee      = "fee"
+	LedgerFunding  = "funding"
+	LedgerTransfer = "transfer"
+	LedgerDeposit  = "deposit"
+	LedgerWithdraw = "withdrawal"
+	LedgerInterest = "interest"
+	LedgerRebate   = "rebate"
+	LedgerOther    = "other"
+)
+
+// types of Liquidation 强制平仓类型
+const (
+	LiqTypeLiquidation = "liquidation"
+	LiqTypeADL         = "adl"
+)
+
 // 此处订单类型全部使用币安订单类型小写
 const (
 	OdTypeMarket             = "market"
@@ -165,6 +216,11 @@ const (
 	PosSideBoth  = "both"
 )
 
+const (
+	MarginAdd    = "add"    // 增加逐仓保证金
+	MarginReduce = "reduce" // 减少逐仓保证金
+)
+
 const (
 	TimeInForceGTC = "GTC" // Good Till Cancel 一直有效，直到被成交或取消
 	TimeInForceIOC = "IOC" // Immediate or Cancel 无法立即成交的部分取消
@@ -179,37 +235,87 @@ const (
 )
 
 const (
-	ApiFetchTicker           = "FetchTicker"
-	ApiFetchTickers          = "FetchTickers"
-	ApiFetchTickerPrice      = "FetchTickerPrice"
-	ApiLoadLeverageBrackets  = "LoadLeverageBrackets"
-	ApiFetchCurrencies       = "FetchCurrencies"
-	ApiGetLeverage           = "GetLeverage"
-	ApiFetchOHLCV            = "FetchOHLCV"
-	ApiFetchOrderBook        = "FetchOrderBook"
-	ApiFetchOrder            = "FetchOrder"
-	ApiFetchOrders           = "FetchOrders"
-	ApiFetchBalance          = "FetchBalance"
-	ApiFetchAccountPositions = "FetchAccountPositions"
-	ApiFetchPositions        = "FetchPositions"
-	ApiFetchOpenOrders       = "FetchOpenOrders"
-	ApiCreateOrder           = "CreateOrder"
-	ApiEditOrder             = "EditOrder"
-	ApiCancelOrder           = "CancelOrder"
-	ApiSetLeverage           = "SetLeverage"
-	ApiCalcMaintMargin       = "CalcMaintMargin"
-	ApiWatchOrderBooks       = "WatchOrderBooks"
-	ApiUnWatchOrderBooks     = "UnWatchOrderBooks"
-	ApiWatchOHLCVs           = "WatchOHLCVs"
-	ApiUnWatchOHLCVs         = "UnWatchOHLCVs"
-	ApiWatchMarkPrices       = "WatchMarkPrices"
-	ApiUnWatchMarkPrices     = "UnWatchMarkPrices"
-	ApiWatchTrades           = "WatchTrades"
-	ApiUnWatchTrades         = "UnWatchTrades"
-	ApiWatchMyTrades         = "WatchMyTrades"
-	ApiWatchBalance          = "WatchBalance"
-	ApiWatchPositions        = "WatchPositions"
-	ApiWatchAccountConfig    = "WatchAccountConfig"
+	ApiFetchTicker                = "FetchTicker"
+	ApiFetchTickers               = "FetchTickers"
+	ApiFetchTickerPrice           = "FetchTickerPrice"
+	ApiLoadLeverageBrackets       = "LoadLeverageBrackets"
+	ApiFetchCurrencies            = "FetchCurrencies"
+	ApiGetLeverage                = "GetLeverage"
+	ApiFetchOHLCV                 = "FetchOHLCV"
+	ApiFetchOrderBook             = "FetchOrderBook"
+	ApiFetchOrder                 = "FetchOrder"
+	ApiFetchOrders                = "FetchOrders"
+	ApiFetchBalance               = "FetchBalance"
+	ApiFetchAccountPositions      = "FetchAccountPositions"
+	ApiFetchPositions             = "FetchPositions"
+	ApiFetchOpenOrders            = "FetchOpenOrders"
+	ApiFetchMyTrades              = "FetchMyTrades"
+	ApiFetchIncomeHistory         = "FetchIncomeHistory"
+	ApiFetchFundingRateHistory    = "FetchFundingRateHistory"
+	ApiCreateOrder                = "CreateOrder"
+	ApiEditOrder                  = "EditOrder"
+	ApiCancelOrder                = "CancelOrder"
+	ApiCreateOrders               = "CreateOrders"
+	ApiEditOrders                 = "EditOrders"
+	ApiCancelOrders               = "CancelOrders"
+	ApiCancelAllOrders            = "CancelAllOrders"
+	ApiSetCancelAllCountdown      = "SetCancelAllCountdown"
+	ApiCreateOCO                  = "CreateOCO"
+	ApiValidateOrder              = "ValidateOrder"
+	ApiCreateBracketOrder         = "CreateBracketOrder"
+	ApiSetLeverage                = "SetLeverage"
+	ApiGetPositionMode            = "GetPositionMode"
+	ApiSetPositionMode            = "SetPositionMode"
+	ApiGetMarginMode              = "GetMarginMode"
+	ApiSetMarginMode              = "SetMarginMode"
+	ApiGetMultiAssetsMode         = "GetMultiAssetsMode"
+	ApiSetMultiAssetsMode         = "SetMultiAssetsMode"
+	ApiModifyPositionMargin       = "ModifyPositionMargin"
+	ApiFetchPositionMarginHistory = "FetchPositionMarginHistory"
+	ApiBorrow                     = "Borrow"
+	ApiRepay                      = "Repay"
+	ApiFetchBorrowInterest        = "FetchBorrowInterest"
+	ApiFetchMaxBorrowable         = "FetchMaxBorrowable"
+	ApiFetchBorrowRates           = "FetchBorrowRates"
+	ApiTransfer                   = "Transfer"
+	ApiFetchTransfers             = "FetchTransfers"
+	ApiFetchDepositAddress        = "FetchDepositAddress"
+	ApiFetchDeposits              = "FetchDeposits"
+	ApiFetchWithdrawals           = "FetchWithdrawals"
+	ApiWithdraw                   = "Withdraw"
+	ApiFetchSubAccounts           = "FetchSubAccounts"
+	ApiFetchSubAccountBalance     = "FetchSubAccountBalance"
+	ApiSubAccountTransfer         = "SubAccountTransfer"
+	ApiCreateSubAccountApiKey     = "CreateSubAccountApiKey"
+	ApiFetchSubAccountApiKeys     = "FetchSubAccountApiKeys"
+	ApiFetchTradingFees           = "FetchTradingFees"
+	ApiFetchConvertQuote          = "FetchConvertQuote"
+	ApiAcceptConvertQuote         = "AcceptConvertQuote"
+	ApiFetchConvertHistory        = "FetchConvertHistory"
+	ApiConvertDust                = "ConvertDust"
+	ApiFetchLedger                = "FetchLedger"
+	ApiFetchLiquidations          = "FetchLiquidations"
+	ApiFetchADLQuantile           = "FetchADLQuantile"
+	ApiCalcMaintMargin            = "CalcMaintMargin"
+	ApiWatchOrderBooks            = "WatchOrderBooks"
+	ApiUnWatchOrderBooks          = "UnWatchOrderBooks"
+	ApiWatchOHLCVs                = "WatchOHLCVs"
+	ApiUnWatchOHLCVs              = "UnWatchOHLCVs"
+	ApiWatchMarkPrices            = "WatchMarkPrices"
+	ApiUnWatchMarkPrices          = "UnWatchMarkPrices"
+	ApiWatchTickers               = "WatchTickers"
+	ApiUnWatchTickers             = "UnWatchTickers"
+	ApiWatchBookTickers           = "WatchBookTickers"
+	ApiUnWatchBookTickers         = "UnWatchBookTickers"
+	ApiWatchTrades                = "WatchTrades"
+	ApiUnWatchTrades              = "UnWatchTrades"
+	ApiWatchMyTrades              = "WatchMyTrades"
+	ApiWatchBalance               = "WatchBalance"
+	ApiWatchPositions             = "WatchPositions"
+	ApiWatchAccountConfig         = "WatchAccountConfig"
+	ApiWatchMarginCalls           = "WatchMarginCalls"
+	ApiWatchLiquidations          = "WatchLiquidations"
+	ApiUnWatchLiquidations        = "UnWatchLiquidations"
 )
 
 var (
@@ -0,0 +1,195 @@
+package banexg
+
+import (
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+CancelAllOrdersEmulated
+emulate CancelAllOrders by fetching open orders and cancel them in batch
+通过获取挂单并批量撤销模拟CancelAllOrders
+*/
+func CancelAllOrdersEmulated(exg BanExchange, symbol string, params map[string]interface{}) ([]*Order, *errs.Error) {
+	ods, err := exg.FetchOpenOrders(symbol, 0, 0, params)
+	if err != nil {
+		return nil, err
+	}
+	reqs := make([]*OrderReq, 0, len(ods))
+	for _, od := range ods {
+		reqs = append(reqs, &OrderReq{Symbol: od.Symbol, ID: od.ID})
+	}
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	resList, err := exg.CancelOrders(reqs, params)
+	if err != nil && err.Code == errs.CodeNotImplement {
+		resList, err = CancelOrdersEmulated(exg, reqs, params), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*Order, 0, len(resList))
+	for _, item := range resList {
+		if item.Err != nil {
+			err = item.Err
+		} else if item.Order != nil {
+			res = append(res, item.Order)
+		}
+	}
+	return res, err
+}
+
+/*
+DeadManSwitch
+keep re-arming the countdown cancel-all of exchange in background. Call Beat periodically, when the process
+crashed or Beat is not called within Timeout, all open orders of Symbols are canceled by exchange.
+For exchanges without native countdown, it's emulated by calling CancelAllOrders when heartbeat timeout,
+which can not protect against process crash.
+在后台持续续期交易所的倒计时撤单。需定期调用Beat，当进程崩溃或Timeout内未调用Beat时，交易所撤销Symbols的所有挂单。
+交易所不支持倒计时撤单时，在心跳超时后调用CancelAllOrders模拟，此时无法应对进程崩溃。
+*/
+type DeadManSwitch struct {
+	Exg      BanExchange
+	Symbols  []string
+	Timeout  time.Duration // 倒计时时长
+	Interval time.Duration // 续期间隔，默认Timeout/3
+	Params   map[string]interface{}
+	Emulated bool                  // 交易所不支持倒计时撤单，客户端模拟
+	OnError  func(err *errs.Error) // 续期或撤单失败时回调
+
+	lastBeat atomic.Int64
+	fired    bool
+	started  bool
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+/*
+NewDeadManSwitch
+create a DeadManSwitch, Interval/OnError can be set before Start
+创建DeadManSwitch，可在Start前设置Interval/OnError
+*/
+func NewDeadManSwitch(exg BanExchange, symbols []string, timeout time.Duration, params map[string]interface{}) *DeadManSwitch {
+	return &DeadManSwitch{
+		Exg:      exg,
+		Symbols:  symbols,
+		Timeout:  timeout,
+		Interval: timeout / 3,
+		Params:   params,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+/*
+Start
+arm countdown cancel-all for symbols and keep re-arming in background
+为symbols设置倒计时撤单，并在后台持续续期
+*/
+func (d *DeadManSwitch) Start() *errs.Error {
+	if len(d.Symbols) == 0 {
+		return errs.NewMsg(errs.CodeParamRequired, "symbols is required for DeadManSwitch")
+	}
+	if d.Timeout <= 0 || d.Interval <= 0 {
+		return errs.NewMsg(errs.CodeParamInvalid, "timeout and interval must be positive for DeadManSwitch")
+	}
+	d.Beat()
+	err := d.arm(d.Timeout)
+	if err != nil {
+		if err.Code != errs.CodeNotImplement && err.Code != errs.CodeNotSupport {
+			return err
+		}
+		d.Emulated = true
+	}
+	d.started = true
+	go d.loop()
+	return nil
+}
+
+// Beat mark the caller is alive 标记调用方存活
+func (d *DeadManSwitch) Beat() {
+	d.lastBeat.Store(time.Now().UnixMilli())
+}
+
+// arm 为所有symbols设置倒计时，某个失败时继续其余的，返回最后一个错误
+func (d *DeadManSwitch) arm(countdown time.Duration) *errs.Error {
+	var res *errs.Error
+	for _, symbol := range d.Symbols {
+		err := d.Exg.SetCancelAllCountdown(symbol, countdown.Milliseconds(), d.Params)
+		if err != nil {
+			res = err
+		}
+	}
+	return res
+}
+
+func (d *DeadManSwitch) loop() {
+	defer close(d.done)
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+		stale := time.Now().UnixMilli()-d.lastBeat.Load() > d.Timeout.Milliseconds()
+		if !d.Emulated {
+			if stale {
+				// 不再续期，交易所倒计时结束后撤单
+				continue
+			}
+			if err := d.arm(d.Timeout); err != nil {
+				d.onError(err)
+			}
+			continue
+		}
+		if !stale {
+			d.fired = false
+			continue
+		}
+		if d.fired {
+			continue
+		}
+		d.fired = true
+		log.Warn("dead man switch timeout, cancel all orders", zap.Strings("symbols", d.Symbols))
+		for _, symbol := range d.Symbols {
+			if _, err := d.Exg.CancelAllOrders(symbol, d.Params); err != nil {
+				d.onError(err)
+			}
+		}
+	}
+}
+
+func (d *DeadManSwitch) onError(err *errs.Error) {
+	if d.OnError != nil {
+		d.OnError(err)
+	} else {
+		log.Error("dead man switch fail", zap.Strings("symbols", d.Symbols), zap.Error(err))
+	}
+}
+
+/*
+Stop
+stop re-arming and disable the countdown of exchange
+停止续期并关闭交易所的倒计时
+*/
+func (d *DeadManSwitch) Stop() *errs.Error {
+	if !d.started {
+		return nil
+	}
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+	<-d.done
+	if d.Emulated {
+		return nil
+	}
+	return d.arm(0)
+}
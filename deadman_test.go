@@ -0,0 +1,97 @@
+package banexg
+
+import (
+	"github.com/banbox/banexg/errs"
+	"sync"
+	"testing"
+	"time"
+)
+
+type deadManExg struct {
+	*Exchange
+	native     bool
+	badSymbol  string
+	lock       sync.Mutex
+	countdowns []int64
+	cancels    int
+}
+
+func (e *deadManExg) SetCancelAllCountdown(symbol string, countdown int64, params map[string]interface{}) *errs.Error {
+	if !e.native {
+		return errs.NewMsg(errs.CodeNotSupport, "not support")
+	}
+	if symbol == e.badSymbol {
+		return errs.NewMsg(errs.CodeRunTime, "arm fail")
+	}
+	e.lock.Lock()
+	e.countdowns = append(e.countdowns, countdown)
+	e.lock.Unlock()
+	return nil
+}
+
+func (e *deadManExg) CancelAllOrders(symbol string, params map[string]interface{}) ([]*Order, *errs.Error) {
+	e.lock.Lock()
+	e.cancels += 1
+	e.lock.Unlock()
+	return nil, nil
+}
+
+func TestDeadManSwitchNative(t *testing.T) {
+	exg := &deadManExg{Exchange: &Exchange{}, native: true}
+	d := NewDeadManSwitch(exg, []string{"BTC/USDT:USDT"}, time.Millisecond*300, nil)
+	d.Interval = time.Millisecond * 50
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		time.Sleep(time.Millisecond * 50)
+		d.Beat()
+	}
+	if err := d.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	exg.lock.Lock()
+	defer exg.lock.Unlock()
+	if d.Emulated || len(exg.countdowns) < 3 {
+		t.Fatalf("expect re-armed natively, got %v", exg.countdowns)
+	}
+	if exg.countdowns[0] != 300 || exg.countdowns[len(exg.countdowns)-1] != 0 {
+		t.Errorf("expect armed with 300 and disarmed with 0, got %v", exg.countdowns)
+	}
+	if exg.cancels != 0 {
+		t.Errorf("native switch should not cancel, got %v", exg.cancels)
+	}
+}
+
+func TestDeadManSwitchEmulated(t *testing.T) {
+	exg := &deadManExg{Exchange: &Exchange{}}
+	d := NewDeadManSwitch(exg, []string{"BTC/USDT"}, time.Millisecond*100, nil)
+	d.Interval = time.Millisecond * 20
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 250)
+	if err := d.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	exg.lock.Lock()
+	defer exg.lock.Unlock()
+	if !d.Emulated {
+		t.Fatalf("expect emulated")
+	}
+	if exg.cancels != 1 {
+		t.Errorf("expect cancel once after heartbeat timeout, got %v", exg.cancels)
+	}
+}
+
+func TestDeadManSwitchArmAll(t *testing.T) {
+	exg := &deadManExg{Exchange: &Exchange{}, native: true, badSymbol: "BTC/USDT:USDT"}
+	d := NewDeadManSwitch(exg, []string{"BTC/USDT:USDT", "ETH/USDT:USDT", "SOL/USDT:USDT"}, time.Second, nil)
+	err := d.arm(d.Timeout)
+	if err == nil || err.Code != errs.CodeRunTime {
+		t.Errorf("expect arm error, got %v", err)
+	}
+	if len(exg.countdowns) != 2 {
+		t.Errorf("symbols after the failed one should be armed, got %v", exg.countdowns)
+	}
+}
@@ -41,6 +41,8 @@ type BanExchange interface {
 	FetchPositions(symbols []string, params map[string]interface{}) ([]*Position, *errs.Error)
 	// FetchOpenOrders Get all open orders on a symbol or all symbol.
 	FetchOpenOrders(symbol string, since int64, limit int, params map[string]interface{}) ([]*Order, *errs.Error)
+	// FetchMyTrades Get trade fills of account, with fee, maker flag and order ID
+	FetchMyTrades(symbol string, since int64, limit int, params map[string]interface{}) ([]*MyTrade, *errs.Error)
 	FetchIncomeHistory(inType string, symbol string, since int64, limit int, params map[string]interface{}) ([]*Income, *errs.Error)
 	FetchLastPrices(symbols []string, params map[string]interface{}) ([]*LastPrice, *errs.Error)
 	FetchFundingRate(symbol string, params map[string]interface{}) (*FundingRateCur, *errs.Error)
@@ -48,12 +50,83 @@ type BanExchange interface {
 	FetchFundingRateHistory(symbol string, since int64, limit int, params map[string]interface{}) ([]*FundingRate, *errs.Error)
 
 	CreateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*Order, *errs.Error)
+	// ValidateOrder check order against market precision, limits, leverage brackets and balance before sending
+	ValidateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*OrderCheck, *errs.Error)
 	EditOrder(symbol, orderId, side string, amount, price float64, params map[string]interface{}) (*Order, *errs.Error)
 	CancelOrder(id string, symbol string, params map[string]interface{}) (*Order, *errs.Error)
+	// CreateOrders create orders in batch, return results of each item; error is returned only when whole batch fail
+	CreateOrders(reqs []*OrderReq, params map[string]interface{}) ([]*OrderRes, *errs.Error)
+	EditOrders(reqs []*OrderReq, params map[string]interface{}) ([]*OrderRes, *errs.Error)
+	CancelOrders(reqs []*OrderReq, params map[string]interface{}) ([]*OrderRes, *errs.Error)
+	// CancelAllOrders cancel all open orders of symbol, or all symbols of market type when symbol is empty
+	CancelAllOrders(symbol string, params map[string]interface{}) ([]*Order, *errs.Error)
+	// SetCancelAllCountdown cancel all orders of symbol after countdown milliseconds, 0 to disable
+	SetCancelAllCountdown(symbol string, countdown int64, params map[string]interface{}) *errs.Error
+	// CreateOCO place limit order at price and stop order at stopPrice on the same side, one fills then the other is canceled
+	CreateOCO(symbol, side string, amount, price, stopPrice float64, params map[string]interface{}) (*OrderGroup, *errs.Error)
+	// CreateBracketOrder place entry order, then stop loss and take profit legs as OCO after entry filled
+	CreateBracketOrder(req *BracketReq, params map[string]interface{}) (*OrderGroup, *errs.Error)
 
 	SetFees(fees map[string]map[string]float64)
 	CalculateFee(symbol, odType, side string, amount float64, price float64, isMaker bool, params map[string]interface{}) (*Fee, *errs.Error)
 	SetLeverage(leverage float64, symbol string, params map[string]interface{}) (map[string]interface{}, *errs.Error)
+	// GetPositionMode return true if hedge mode (dual position side) is enabled, cached on Account
+	GetPositionMode(params map[string]interface{}) (bool, *errs.Error)
+	// SetPositionMode switch between one-way mode and hedge mode
+	SetPositionMode(hedged bool, params map[string]interface{}) *errs.Error
+	// GetMarginMode return MarginCross or MarginIsolated of symbol, cached on Account
+	GetMarginMode(symbol string, params map[string]interface{}) (string, *errs.Error)
+	// SetMarginMode set MarginCross or MarginIsolated for symbol
+	SetMarginMode(symbol, mode string, params map[string]interface{}) *errs.Error
+	// GetMultiAssetsMode return true if multi-assets margin mode is enabled, cached on Account
+	GetMultiAssetsMode(params map[string]interface{}) (bool, *errs.Error)
+	// SetMultiAssetsMode enable or disable multi-assets margin mode
+	SetMultiAssetsMode(enable bool, params map[string]interface{}) *errs.Error
+	// ModifyPositionMargin add (MarginAdd) or reduce (MarginReduce) isolated margin of position, posSide is empty for one-way mode
+	ModifyPositionMargin(symbol string, amount float64, changeType, posSide string, params map[string]interface{}) (*MarginChange, *errs.Error)
+	// FetchPositionMarginHistory fetch isolated margin changes of symbol
+	FetchPositionMarginHistory(symbol string, since int64, limit int, params map[string]interface{}) ([]*MarginChange, *errs.Error)
+	// Borrow borrow currency in margin account, isolated margin if ParamSymbol is set
+	Borrow(code string, amount float64, params map[string]interface{}) (*MarginLoan, *errs.Error)
+	// Repay repay borrowed currency in margin account, isolated margin if ParamSymbol is set
+	Repay(code string, amount float64, params map[string]interface{}) (*MarginLoan, *errs.Error)
+	// FetchBorrowInterest fetch interest charged for borrowing, all currencies if code is empty
+	FetchBorrowInterest(code string, since int64, limit int, params map[string]interface{}) ([]*BorrowInterest, *errs.Error)
+	// FetchMaxBorrowable return max amount of currency can be borrowed
+	FetchMaxBorrowable(code string, params map[string]interface{}) (float64, *errs.Error)
+	// FetchBorrowRates fetch current borrow rates of currencies
+	FetchBorrowRates(codes []string, params map[string]interface{}) ([]*BorrowRate, *errs.Error)
+	// Transfer move asset between wallets of market types, like spot -> linear
+	Transfer(code string, amount float64, fromMarket, toMarket string, params map[string]interface{}) (*Transfer, *errs.Error)
+	// FetchTransfers fetch history of internal transfers
+	FetchTransfers(code string, since int64, limit int, params map[string]interface{}) ([]*Transfer, *errs.Error)
+	// FetchDepositAddress fetch deposit address of currency, default network if network is empty
+	FetchDepositAddress(code, network string, params map[string]interface{}) (*DepositAddress, *errs.Error)
+	FetchDeposits(code string, since int64, limit int, params map[string]interface{}) ([]*Transaction, *errs.Error)
+	FetchWithdrawals(code string, since int64, limit int, params map[string]interface{}) ([]*Transaction, *errs.Error)
+	// Withdraw send currency to address, amount is validated against limits of network
+	Withdraw(code string, amount float64, address, tag, network string, params map[string]interface{}) (*Transaction, *errs.Error)
+	FetchSubAccounts(params map[string]interface{}) ([]*SubAccount, *errs.Error)
+	// FetchSubAccountBalance fetch balances of sub-account for params.market, default spot
+	FetchSubAccountBalance(subId string, params map[string]interface{}) (*Balances, *errs.Error)
+	// SubAccountTransfer transfer between master and sub-accounts, empty id means master
+	SubAccountTransfer(code string, amount float64, fromId, toId string, params map[string]interface{}) (*Transfer, *errs.Error)
+	CreateSubAccountApiKey(subId string, perms []string, params map[string]interface{}) (*SubAccApiKey, *errs.Error)
+	FetchSubAccountApiKeys(subId string, params map[string]interface{}) ([]*SubAccApiKey, *errs.Error)
+	// FetchTradingFees fetch fee rates of account, saved for CalculateFee when params.applyFees is true
+	FetchTradingFees(symbols []string, params map[string]interface{}) ([]*TradingFee, *errs.Error)
+	// FetchConvertQuote get quote to convert amount of fromCode to toCode
+	FetchConvertQuote(fromCode, toCode string, amount float64, params map[string]interface{}) (*ConvertQuote, *errs.Error)
+	AcceptConvertQuote(quoteId string, params map[string]interface{}) (*ConvertTrade, *errs.Error)
+	FetchConvertHistory(code string, since int64, limit int, params map[string]interface{}) ([]*ConvertTrade, *errs.Error)
+	// ConvertDust convert small balances which are below min notional
+	ConvertDust(codes []string, params map[string]interface{}) ([]*ConvertTrade, *errs.Error)
+	// FetchLedger fetch cash flows of account sorted by time, use last Timestamp+1 as since for next page
+	FetchLedger(code string, since int64, limit int, params map[string]interface{}) ([]*LedgerEntry, *errs.Error)
+	// FetchLiquidations fetch forced close orders(liquidation/ADL) of account
+	FetchLiquidations(symbol string, since int64, limit int, params map[string]interface{}) ([]*Liquidation, *errs.Error)
+	// FetchADLQuantile fetch ADL queue position of positions, all symbols if empty
+	FetchADLQuantile(symbols []string, params map[string]interface{}) ([]*ADLQuantile, *errs.Error)
 	CalcMaintMargin(symbol string, cost float64) (float64, *errs.Error)
 	Call(method string, params map[string]interface{}) (*HttpRes, *errs.Error)
 
@@ -63,12 +136,23 @@ type BanExchange interface {
 	UnWatchOHLCVs(jobs [][2]string, params map[string]interface{}) *errs.Error
 	WatchMarkPrices(symbols []string, params map[string]interface{}) (chan map[string]float64, *errs.Error)
 	UnWatchMarkPrices(symbols []string, params map[string]interface{}) *errs.Error
+	// WatchTickers watch 24hr rolling window tickers, all symbols of market type if symbols is empty
+	WatchTickers(symbols []string, params map[string]interface{}) (chan *Ticker, *errs.Error)
+	UnWatchTickers(symbols []string, params map[string]interface{}) *errs.Error
+	// WatchBookTickers watch best bid/ask (only Bid/Ask fields are filled)
+	WatchBookTickers(symbols []string, params map[string]interface{}) (chan *Ticker, *errs.Error)
+	UnWatchBookTickers(symbols []string, params map[string]interface{}) *errs.Error
 	WatchTrades(symbols []string, params map[string]interface{}) (chan *Trade, *errs.Error)
 	UnWatchTrades(symbols []string, params map[string]interface{}) *errs.Error
 	WatchMyTrades(params map[string]interface{}) (chan *MyTrade, *errs.Error)
 	WatchBalance(params map[string]interface{}) (chan *Balances, *errs.Error)
 	WatchPositions(params map[string]interface{}) (chan []*Position, *errs.Error)
 	WatchAccountConfig(params map[string]interface{}) (chan *AccountConfig, *errs.Error)
+	// WatchMarginCalls watch margin call of positions in account, one item for each position
+	WatchMarginCalls(params map[string]interface{}) (chan *MarginCall, *errs.Error)
+	// WatchLiquidations watch public liquidation orders, all symbols of market type if symbols is empty
+	WatchLiquidations(symbols []string, params map[string]interface{}) (chan *Liquidation, *errs.Error)
+	UnWatchLiquidations(symbols []string, params map[string]interface{}) *errs.Error
 
 	// SetDump Record all websocket messages to the specified file 将websocket所有消息记录到指定文件
 	SetDump(path string) *errs.Error
@@ -89,6 +173,7 @@ type BanExchange interface {
 	PrecFee(m *Market, fee float64) (float64, *errs.Error)
 
 	HasApi(key, market string) bool
+	GetPageConf(key, market string) *PageConf
 	SetOnHost(cb func(n string) string)
 	PriceOnePip(symbol string) (float64, *errs.Error)
 	IsContract(marketType string) bool
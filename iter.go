@@ -0,0 +1,445 @@
+package banexg
+
+import (
+	"context"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+/*
+PageReq
+request args for one page. Cursor is empty for the first page or time-based api
+单页的请求参数。第一页或基于时间的接口Cursor为空
+*/
+type PageReq struct {
+	Since  int64
+	Until  int64
+	Limit  int
+	Cursor string
+}
+
+/*
+FuncFetchPage
+fetch one page, return items and next cursor (empty for time-based api)
+获取一页数据，返回数据和下一页游标(基于时间的接口返回空)
+*/
+type FuncFetchPage[T any] func(req *PageReq) ([]T, string, *errs.Error)
+
+/*
+PageIter
+iterate history data page by page. Supports cursor or time window pagination,
+max time range per request, deduplication at page boundaries and request interval.
+Set Backward for apis which return the latest items first in the range.
+Iter* functions apply the exchange's PageConf (see Exchange.Pages) automatically.
+分页迭代历史数据。支持游标或时间窗口分页、单次请求最大时间范围、页边界去重和请求间隔。
+对于在范围内优先返回最新数据的接口，设置Backward。Iter*函数会自动应用交易所的PageConf(见Exchange.Pages)。
+*/
+type PageIter[T any] struct {
+	Fetch    FuncFetchPage[T]
+	GetTime  func(T) int64
+	GetKey   func(T) string // 去重键；为nil时不去重
+	Since    int64
+	Until    int64 // 0表示到当前时间
+	Limit    int   // 每页数量，0使用交易所默认值
+	DefLimit int   // Limit为0时交易所默认的每页数量，用于判断最后一页
+	MaxRange int64 // 单次请求的最大时间范围(毫秒)，0表示不限制
+	Step     int64 // 相邻数据的最小时间间隔(毫秒)，如K线周期；0表示1毫秒
+	Interval time.Duration
+	Backward bool // 从Until向Since倒序分页
+
+	ctx      context.Context
+	cursor   string
+	lastReq  time.Time
+	edgeTime int64
+	edgeKeys map[string]bool
+	hasEdge  bool
+	done     bool
+	err      *errs.Error
+}
+
+func NewPageIter[T any](ctx context.Context, since, until int64, limit int, fetch FuncFetchPage[T],
+	getTime func(T) int64, getKey func(T) string) *PageIter[T] {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &PageIter[T]{
+		Fetch:    fetch,
+		GetTime:  getTime,
+		GetKey:   getKey,
+		Since:    since,
+		Until:    until,
+		Limit:    limit,
+		ctx:      ctx,
+		edgeKeys: make(map[string]bool),
+	}
+}
+
+/*
+UseConf
+apply pagination rules of exchange api, fields already set are kept
+应用交易所接口的分页规则，已设置的字段保持不变
+*/
+func (it *PageIter[T]) UseConf(conf *PageConf) {
+	if conf == nil {
+		return
+	}
+	if conf.MaxLimit > 0 && it.Limit > conf.MaxLimit {
+		it.Limit = conf.MaxLimit
+	}
+	if it.DefLimit == 0 {
+		it.DefLimit = conf.DefLimit
+	}
+	if it.MaxRange == 0 {
+		it.MaxRange = conf.MaxRange
+	}
+	if it.Interval == 0 {
+		it.Interval = time.Duration(conf.Interval) * time.Millisecond
+	}
+	if conf.Backward {
+		it.Backward = true
+	}
+}
+
+/*
+Next
+return next non-empty batch sorted by time, false when finished or failed; check Err() after finished
+返回下一批按时间排序的非空数据，结束或出错时返回false；结束后检查Err()
+*/
+func (it *PageIter[T]) Next() ([]T, bool) {
+	for !it.done {
+		if err := it.wait(); err != nil {
+			it.finish(err)
+			break
+		}
+		if it.Until <= 0 {
+			it.Until = time.Now().UnixMilli()
+		}
+		if it.cursor == "" && it.Since > it.Until {
+			it.finish(nil)
+			break
+		}
+		req := &PageReq{Since: it.Since, Until: it.Until, Limit: it.Limit, Cursor: it.cursor}
+		if it.MaxRange > 0 && it.cursor == "" {
+			if it.Backward {
+				req.Since = max(it.Since, it.Until-it.MaxRange+1)
+			} else {
+				req.Until = min(it.Until, it.Since+it.MaxRange-1)
+			}
+		}
+		items, cursor, err := it.Fetch(req)
+		it.lastReq = time.Now()
+		if err != nil {
+			it.finish(err)
+			break
+		}
+		batch := it.filter(items, req.Cursor != "" || cursor != "")
+		if cursor != "" {
+			it.cursor = cursor
+		} else if it.cursor != "" {
+			// 游标模式最后一页
+			it.done = true
+		} else {
+			it.advance(req, items)
+		}
+		if len(batch) > 0 {
+			return batch, true
+		}
+	}
+	return nil, false
+}
+
+// filter 过滤超出范围和页边界重复的数据
+func (it *PageIter[T]) filter(items []T, isCursor bool) []T {
+	sort.SliceStable(items, func(i, j int) bool {
+		return it.GetTime(items[i]) < it.GetTime(items[j])
+	})
+	res := make([]T, 0, len(items))
+	for i := range items {
+		item := items[i]
+		if it.Backward {
+			item = items[len(items)-1-i]
+		}
+		stamp := it.GetTime(item)
+		if stamp < it.Since || stamp > it.Until {
+			continue
+		}
+		if it.GetKey != nil && !isCursor {
+			key := it.GetKey(item)
+			if it.hasEdge {
+				isOld := stamp < it.edgeTime
+				if it.Backward {
+					isOld = stamp > it.edgeTime
+				}
+				if isOld || stamp == it.edgeTime && it.edgeKeys[key] {
+					continue
+				}
+			}
+			if !it.hasEdge || stamp != it.edgeTime {
+				it.hasEdge = true
+				it.edgeTime = stamp
+				clear(it.edgeKeys)
+			}
+			it.edgeKeys[key] = true
+		}
+		res = append(res, item)
+	}
+	if it.Backward {
+		slices.Reverse(res)
+	}
+	return res
+}
+
+// advance 基于时间分页时，计算下一页的时间范围
+func (it *PageIter[T]) advance(req *PageReq, items []T) {
+	pageSize := it.Limit
+	if pageSize <= 0 {
+		pageSize = it.DefLimit
+	}
+	if len(items) == 0 || pageSize > 0 && len(items) < pageSize {
+		// 当前时间窗口已无更多数据
+		if it.MaxRange > 0 && !it.Backward && req.Until < it.Until {
+			it.Since = req.Until + 1
+		} else if it.MaxRange > 0 && it.Backward && req.Since > it.Since {
+			it.Until = req.Since - 1
+		} else {
+			it.done = true
+		}
+		return
+	}
+	// 有去重键时，从边界时间重新请求，避免遗漏同一时间的数据
+	if it.Backward {
+		pageMin := it.GetTime(items[0])
+		if it.GetKey != nil && pageMin < it.Until {
+			it.Until = pageMin
+		} else {
+			it.Until = pageMin - 1
+		}
+	} else {
+		pageMax := it.GetTime(items[len(items)-1])
+		if it.GetKey != nil && pageMax > it.Since {
+			it.Since = pageMax
+		} else {
+			it.Since = pageMax + max(it.Step, 1)
+		}
+	}
+}
+
+func (it *PageIter[T]) wait() *errs.Error {
+	if it.Interval <= 0 || it.lastReq.IsZero() {
+		return it.ctxErr()
+	}
+	waitDur := it.Interval - time.Since(it.lastReq)
+	if waitDur <= 0 {
+		return it.ctxErr()
+	}
+	select {
+	case <-it.ctx.Done():
+		return it.ctxErr()
+	case <-time.After(waitDur):
+		return nil
+	}
+}
+
+func (it *PageIter[T]) ctxErr() *errs.Error {
+	if err := it.ctx.Err(); err != nil {
+		return errs.New(errs.CodeRunTime, err)
+	}
+	return nil
+}
+
+func (it *PageIter[T]) finish(err *errs.Error) {
+	it.done = true
+	it.err = err
+}
+
+/*
+Err
+error which stopped the iteration
+导致迭代停止的错误
+*/
+func (it *PageIter[T]) Err() *errs.Error {
+	return it.err
+}
+
+/*
+All
+collect all remaining items, sorted by time
+获取剩余的全部数据，按时间排序
+*/
+func (it *PageIter[T]) All() ([]T, *errs.Error) {
+	var res []T
+	for {
+		batch, ok := it.Next()
+		if !ok {
+			break
+		}
+		res = append(res, batch...)
+	}
+	if it.Backward {
+		sort.SliceStable(res, func(i, j int) bool {
+			return it.GetTime(res[i]) < it.GetTime(res[j])
+		})
+	}
+	return res, it.err
+}
+
+/*
+Take
+collect at most n remaining items sorted by time, all if n <= 0. Backward iterators keep the latest n items
+获取最多n条剩余数据，按时间排序，n<=0时获取全部。倒序迭代时保留最新的n条
+*/
+func (it *PageIter[T]) Take(n int) ([]T, *errs.Error) {
+	if n <= 0 {
+		return it.All()
+	}
+	var res []T
+	for len(res) < n {
+		batch, ok := it.Next()
+		if !ok {
+			break
+		}
+		res = append(res, batch...)
+	}
+	if it.Backward {
+		sort.SliceStable(res, func(i, j int) bool {
+			return it.GetTime(res[i]) < it.GetTime(res[j])
+		})
+		if len(res) > n {
+			res = res[len(res)-n:]
+		}
+	} else if len(res) > n {
+		res = res[:n]
+	}
+	return res, it.err
+}
+
+/*
+pageConf
+get pagination rules of api for market of symbol, or params.market, or default market type
+获取symbol所属市场的接口分页规则，无symbol时使用params.market或默认市场类型
+*/
+func pageConf(exg BanExchange, api, symbol string, params map[string]interface{}) *PageConf {
+	marketType := utils.GetMapVal(params, ParamMarket, "")
+	if symbol != "" {
+		if market, err := exg.GetMarket(symbol); err == nil {
+			marketType = market.Type
+		}
+	}
+	if marketType == "" {
+		if info := exg.Info(); info != nil {
+			marketType = info.MarketType
+		}
+	}
+	return exg.GetPageConf(api, marketType)
+}
+
+func pageArgs(params map[string]interface{}, req *PageReq) map[string]interface{} {
+	args := utils.SafeParams(params)
+	args[ParamUntil] = req.Until
+	return args
+}
+
+/*
+IterOHLCV
+iterate klines in [since, until], until=0 means now
+迭代[since, until]范围内的K线，until=0表示到当前
+*/
+func IterOHLCV(ctx context.Context, exg BanExchange, symbol, timeframe string, since, until int64, limit int,
+	params map[string]interface{}) *PageIter[*Kline] {
+	fetch := func(req *PageReq) ([]*Kline, string, *errs.Error) {
+		res, err := exg.FetchOHLCV(symbol, timeframe, req.Since, req.Limit, pageArgs(params, req))
+		return res, "", err
+	}
+	it := NewPageIter(ctx, since, until, limit, fetch, func(k *Kline) int64 {
+		return k.Time
+	}, nil)
+	if tfSecs, err := utils.ParseTimeFrame(timeframe); err == nil {
+		it.Step = int64(tfSecs) * 1000
+	}
+	it.UseConf(pageConf(exg, ApiFetchOHLCV, symbol, params))
+	return it
+}
+
+/*
+IterOrders
+iterate orders of symbol created in [since, until]
+迭代symbol在[since, until]内创建的订单
+*/
+func IterOrders(ctx context.Context, exg BanExchange, symbol string, since, until int64, limit int,
+	params map[string]interface{}) *PageIter[*Order] {
+	fetch := func(req *PageReq) ([]*Order, string, *errs.Error) {
+		res, err := exg.FetchOrders(symbol, req.Since, req.Limit, pageArgs(params, req))
+		return res, "", err
+	}
+	it := NewPageIter(ctx, since, until, limit, fetch, func(o *Order) int64 {
+		return o.Timestamp
+	}, func(o *Order) string {
+		return o.ID
+	})
+	it.UseConf(pageConf(exg, ApiFetchOrders, symbol, params))
+	return it
+}
+
+/*
+IterMyTrades
+iterate fills of account in [since, until]
+迭代[since, until]内账户的成交
+*/
+func IterMyTrades(ctx context.Context, exg BanExchange, symbol string, since, until int64, limit int,
+	params map[string]interface{}) *PageIter[*MyTrade] {
+	fetch := func(req *PageReq) ([]*MyTrade, string, *errs.Error) {
+		res, err := exg.FetchMyTrades(symbol, req.Since, req.Limit, pageArgs(params, req))
+		return res, "", err
+	}
+	it := NewPageIter(ctx, since, until, limit, fetch, func(t *MyTrade) int64 {
+		return t.Timestamp
+	}, func(t *MyTrade) string {
+		return t.Symbol + "_" + t.ID
+	})
+	it.UseConf(pageConf(exg, ApiFetchMyTrades, symbol, params))
+	return it
+}
+
+/*
+IterIncomes
+iterate income history in [since, until]
+迭代[since, until]内的资金流水
+*/
+func IterIncomes(ctx context.Context, exg BanExchange, inType, symbol string, since, until int64, limit int,
+	params map[string]interface{}) *PageIter[*Income] {
+	fetch := func(req *PageReq) ([]*Income, string, *errs.Error) {
+		res, err := exg.FetchIncomeHistory(inType, symbol, req.Since, req.Limit, pageArgs(params, req))
+		return res, "", err
+	}
+	it := NewPageIter(ctx, since, until, limit, fetch, func(i *Income) int64 {
+		return i.Time
+	}, func(i *Income) string {
+		return i.TranID + "_" + i.IncomeType + "_" + i.Asset + "_" + i.Symbol
+	})
+	it.UseConf(pageConf(exg, ApiFetchIncomeHistory, symbol, params))
+	return it
+}
+
+/*
+IterFundingRates
+iterate funding rate history in [since, until]
+迭代[since, until]内的资金费率历史
+*/
+func IterFundingRates(ctx context.Context, exg BanExchange, symbol string, since, until int64, limit int,
+	params map[string]interface{}) *PageIter[*FundingRate] {
+	fetch := func(req *PageReq) ([]*FundingRate, string, *errs.Error) {
+		res, err := exg.FetchFundingRateHistory(symbol, req.Since, req.Limit, pageArgs(params, req))
+		return res, "", err
+	}
+	it := NewPageIter(ctx, since, until, limit, fetch, func(f *FundingRate) int64 {
+		return f.Timestamp
+	}, func(f *FundingRate) string {
+		return f.Symbol + "_" + strconv.FormatInt(f.Timestamp, 10)
+	})
+	it.UseConf(pageConf(exg, ApiFetchFundingRateHistory, symbol, params))
+	return it
+}
@@ -0,0 +1,195 @@
+package banexg
+
+import (
+	"context"
+	"github.com/banbox/banexg/errs"
+	"strconv"
+	"testing"
+)
+
+type iterItem struct {
+	ID   string
+	Time int64
+}
+
+// makeIterItems 每个时间戳两条数据，用于测试页边界去重
+func makeIterItems(num int) []*iterItem {
+	res := make([]*iterItem, 0, num*2)
+	for i := 0; i < num; i++ {
+		stamp := int64(1000 + i*10)
+		res = append(res, &iterItem{ID: strconv.Itoa(i) + "a", Time: stamp}, &iterItem{ID: strconv.Itoa(i) + "b", Time: stamp})
+	}
+	return res
+}
+
+func newTestIter(items []*iterItem, backward bool) (*PageIter[*iterItem], *int) {
+	calls := 0
+	fetch := func(req *PageReq) ([]*iterItem, string, *errs.Error) {
+		calls += 1
+		var res []*iterItem
+		if backward {
+			for i := len(items) - 1; i >= 0 && len(res) < req.Limit; i-- {
+				if it := items[i]; it.Time >= req.Since && it.Time <= req.Until {
+					res = append(res, it)
+				}
+			}
+		} else {
+			for _, it := range items {
+				if it.Time >= req.Since && it.Time <= req.Until && len(res) < req.Limit {
+					res = append(res, it)
+				}
+			}
+		}
+		return res, "", nil
+	}
+	it := NewPageIter(context.Background(), 1000, 2000, 5, fetch, func(i *iterItem) int64 {
+		return i.Time
+	}, func(i *iterItem) string {
+		return i.ID
+	})
+	it.Backward = backward
+	return it, &calls
+}
+
+func checkIterItems(t *testing.T, name string, res []*iterItem, num int) {
+	if len(res) != num*2 {
+		t.Fatalf("%s: expect %d items, got %d", name, num*2, len(res))
+	}
+	seen := make(map[string]bool)
+	for i, it := range res {
+		if seen[it.ID] {
+			t.Errorf("%s: duplicate item %s", name, it.ID)
+		}
+		seen[it.ID] = true
+		if i > 0 && it.Time < res[i-1].Time {
+			t.Errorf("%s: items not sorted at %d", name, i)
+		}
+	}
+}
+
+func TestPageIter(t *testing.T) {
+	items := makeIterItems(12)
+	it, _ := newTestIter(items, false)
+	res, err := it.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIterItems(t, "forward", res, 12)
+
+	it, _ = newTestIter(items, true)
+	res, err = it.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIterItems(t, "backward", res, 12)
+
+	// 限制单次请求时间范围，中间有空窗口
+	gapItems := append(makeIterItems(3), &iterItem{ID: "late", Time: 1900})
+	it, calls := newTestIter(gapItems, false)
+	it.MaxRange = 100
+	res, err = it.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 7 || res[6].ID != "late" || *calls < 10 {
+		t.Errorf("max range fail: %d items, %d calls", len(res), *calls)
+	}
+}
+
+func TestPageIterTake(t *testing.T) {
+	items := makeIterItems(12)
+	it, _ := newTestIter(items, false)
+	res, err := it.Take(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 7 || res[0].ID != "0a" || res[6].ID != "3a" {
+		t.Errorf("forward take fail: %d items", len(res))
+	}
+	// 倒序时保留最新的数据
+	it, _ = newTestIter(items, true)
+	res, err = it.Take(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 7 || res[0].Time != 1080 || res[6].Time != 1110 {
+		t.Errorf("backward take fail: %d items", len(res))
+	}
+	for i := 1; i < len(res); i++ {
+		if res[i].Time < res[i-1].Time {
+			t.Errorf("backward take not sorted at %d", i)
+		}
+	}
+}
+
+func TestPageIterCursor(t *testing.T) {
+	pages := [][]*iterItem{
+		{{ID: "1", Time: 1001}, {ID: "2", Time: 1002}},
+		{{ID: "3", Time: 1003}},
+	}
+	fetch := func(req *PageReq) ([]*iterItem, string, *errs.Error) {
+		idx := 0
+		if req.Cursor != "" {
+			idx, _ = strconv.Atoi(req.Cursor)
+		}
+		next := ""
+		if idx+1 < len(pages) {
+			next = strconv.Itoa(idx + 1)
+		}
+		return pages[idx], next, nil
+	}
+	it := NewPageIter(context.Background(), 1000, 2000, 0, fetch, func(i *iterItem) int64 {
+		return i.Time
+	}, nil)
+	res, err := it.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 3 {
+		t.Errorf("cursor iter fail: %d", len(res))
+	}
+}
+
+func TestPageIterConf(t *testing.T) {
+	var items []*iterItem
+	for i := 0; i < 12; i++ {
+		items = append(items, &iterItem{ID: strconv.Itoa(i), Time: int64(1000 + i*10)})
+	}
+	calls := 0
+	fetch := func(req *PageReq) ([]*iterItem, string, *errs.Error) {
+		calls += 1
+		limit := req.Limit
+		if limit == 0 {
+			limit = 5
+		}
+		var res []*iterItem
+		for _, it := range items {
+			if it.Time >= req.Since && it.Time <= req.Until && len(res) < limit {
+				res = append(res, it)
+			}
+		}
+		return res, "", nil
+	}
+	it := NewPageIter(context.Background(), 1000, 2000, 0, fetch, func(i *iterItem) int64 {
+		return i.Time
+	}, nil)
+	// 不设置limit时，使用交易所默认数量判断最后一页
+	it.UseConf(&PageConf{DefLimit: 5})
+	res, err := it.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 12 || calls != 3 {
+		t.Errorf("def limit fail: %d items, %d calls", len(res), calls)
+	}
+	exg := &Exchange{ExgInfo: &ExgInfo{}, Pages: map[string]map[string]*PageConf{
+		"":           {ApiFetchOHLCV: {DefLimit: 200}},
+		MarketLinear: {ApiFetchOHLCV: {DefLimit: 500, Backward: true}},
+	}}
+	if conf := exg.GetPageConf(ApiFetchOHLCV, MarketLinear); conf == nil || !conf.Backward {
+		t.Errorf("bad linear page conf: %v", conf)
+	}
+	if conf := exg.GetPageConf(ApiFetchOHLCV, MarketSpot); conf == nil || conf.DefLimit != 200 {
+		t.Errorf("bad default page conf: %v", conf)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/banbox/banexg"
 	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
 	"github.com/longportapp/openapi-go/config"
 	"github.com/longportapp/openapi-go/quote"
 	"github.com/longportapp/openapi-go/trade"
@@ -197,8 +198,22 @@ func (e *Longp) FetchOrderBook(symbol string, limit int, params map[string]inter
 	return orderBook, nil
 }
 
+func (e *Longp) ValidateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*banexg.OrderCheck, *errs.Error) {
+	return banexg.CheckOrder(e, symbol, odType, side, amount, price, params)
+}
+
 // 交易接口
 func (e *Longp) CreateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*banexg.Order, *errs.Error) {
+	if utils.GetMapVal(params, banexg.ParamValidate, false) {
+		check, err := e.ValidateOrder(symbol, odType, side, amount, price, params)
+		if err != nil {
+			return nil, err
+		}
+		if err = check.Error(); err != nil {
+			return nil, err
+		}
+		amount, price = check.Amount, check.Price
+	}
 	// 打印请求参数
 	logx.Infof("Creating order: symbol=%s, type=%s, side=%s, amount=%.2f, price=%.2f",
 		symbol, odType, side, amount, price)
@@ -279,6 +294,26 @@ func (e *Longp) CreateOrder(symbol, odType, side string, amount, price float64,
 	return result, nil
 }
 
+// CreateOrders 无批量下单接口，并发调用CreateOrder模拟
+func (e *Longp) CreateOrders(reqs []*banexg.OrderReq, params map[string]interface{}) ([]*banexg.OrderRes, *errs.Error) {
+	return banexg.CreateOrdersEmulated(e, reqs, params), nil
+}
+
+// EditOrders 无批量修改接口，并发调用EditOrder模拟
+func (e *Longp) EditOrders(reqs []*banexg.OrderReq, params map[string]interface{}) ([]*banexg.OrderRes, *errs.Error) {
+	return banexg.EditOrdersEmulated(e, reqs, params), nil
+}
+
+// CancelOrders 无批量撤单接口，并发调用CancelOrder模拟
+func (e *Longp) CancelOrders(reqs []*banexg.OrderReq, params map[string]interface{}) ([]*banexg.OrderRes, *errs.Error) {
+	return banexg.CancelOrdersEmulated(e, reqs, params), nil
+}
+
+// CancelAllOrders 无撤销全部接口，获取挂单后逐个撤销
+func (e *Longp) CancelAllOrders(symbol string, params map[string]interface{}) ([]*banexg.Order, *errs.Error) {
+	return banexg.CancelAllOrdersEmulated(e, symbol, params)
+}
+
 func (e *Longp) CancelOrder(id string, symbol string, params map[string]interface{}) (*banexg.Order, *errs.Error) {
 	// 打印请求参数
 	logx.Infof("Cancelling order: id=%s, symbol=%s", id, symbol)
@@ -81,6 +81,11 @@ func createApiSupport() map[string]map[string]int {
 			banexg.ApiCreateOrder:           banexg.HasOk,
 			banexg.ApiEditOrder:             banexg.HasOk,
 			banexg.ApiCancelOrder:           banexg.HasOk,
+			banexg.ApiCreateOrders:          banexg.HasEmulated,
+			banexg.ApiEditOrders:            banexg.HasEmulated,
+			banexg.ApiCancelOrders:          banexg.HasEmulated,
+			banexg.ApiCancelAllOrders:       banexg.HasEmulated,
+			banexg.ApiValidateOrder:         banexg.HasOk,
 			banexg.ApiSetLeverage:           banexg.HasOk,
 			banexg.ApiCalcMaintMargin:       banexg.HasOk,
 			banexg.ApiWatchOrderBooks:       banexg.HasOk,
@@ -0,0 +1,551 @@
+package odmgr
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
+	"github.com/banbox/banexg/utils"
+	"go.uber.org/zap"
+	"maps"
+	"sync"
+)
+
+// statusRank order of status, status of lower rank can't overwrite higher 状态顺序，低等级状态不能覆盖高等级
+var statusRank = map[string]int{
+	"":                        0,
+	banexg.OdStatusOpen:       1,
+	banexg.OdStatusPartFilled: 2,
+	banexg.OdStatusCanceling:  3,
+	banexg.OdStatusFilled:     4,
+	banexg.OdStatusCanceled:   4,
+	banexg.OdStatusRejected:   4,
+	banexg.OdStatusExpired:    4,
+}
+
+// IsDone whether status is final 状态是否为最终状态
+func IsDone(status string) bool {
+	return statusRank[status] >= 4
+}
+
+/*
+Manager
+track orders placed by client ID: apply fills of WatchMyTrades and snapshots of REST api in order,
+reconcile open orders by REST after websocket reconnected, and output copies of orders on state transitions.
+按客户端ID跟踪订单：按顺序应用WatchMyTrades的成交和REST接口的快照，websocket重连后通过REST对账挂单，
+订单状态变化时输出订单副本。
+*/
+type Manager struct {
+	Exg    banexg.BanExchange
+	Params map[string]interface{} // params for WatchMyTrades/FetchOpenOrders/FetchOrder, e.g. account
+	Out    chan *banexg.Order     // copies of orders on state transitions 订单状态变化时的副本
+
+	orders   map[string]*banexg.Order   // client id: order
+	byId     map[string]string          // exchange order id: client id
+	tradeIds map[string]map[string]bool // client id: applied trade ids
+	lock     sync.Mutex
+	key      string
+	trades   chan *banexg.MyTrade // subscription of SubMyTrades, nil if not started
+	queue    []*banexg.Order      // transitions waiting for Out, never dropped 等待写入Out的状态变化，不丢弃
+	pumping  bool
+}
+
+/*
+New
+create order manager, params are used for all requests of manager, chanCap of Out is read from ParamChanCap
+创建订单管理器，params用于管理器的所有请求，Out的容量读取ParamChanCap
+*/
+func New(exg banexg.BanExchange, params map[string]interface{}) *Manager {
+	args := utils.SafeParams(params)
+	chanCap := utils.PopMapVal(args, banexg.ParamChanCap, 100)
+	return &Manager{
+		Exg:      exg,
+		Params:   args,
+		Out:      make(chan *banexg.Order, chanCap),
+		orders:   make(map[string]*banexg.Order),
+		byId:     make(map[string]string),
+		tradeIds: make(map[string]map[string]bool),
+		key:      "odmgr" + utils.UUID(8),
+	}
+}
+
+/*
+Start
+subscribe trades by banexg.SubMyTrades, and reconcile after the user stream of account reconnected.
+通过banexg.SubMyTrades订阅成交，账户的用户数据流重连后对账。
+*/
+func (m *Manager) Start() *errs.Error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.trades != nil {
+		return nil
+	}
+	out, err := banexg.SubMyTrades(m.Exg, m.Params)
+	if err != nil {
+		return err
+	}
+	m.trades = out
+	exg := m.Exg.GetExg()
+	accName := exg.GetAccName(m.Params)
+	exg.SetWsReConListener(m.key, func(client *banexg.WsClient) {
+		// only private clients of user stream carry account, public streams are ignored
+		if client.AccName == "" || accName != "" && client.AccName != accName {
+			return
+		}
+		go func() {
+			if err := m.Reconcile(); err != nil {
+				log.Error("reconcile orders fail", zap.Error(err))
+			}
+		}()
+	})
+	go func() {
+		for trade := range out {
+			m.OnTrade(trade)
+		}
+		m.lock.Lock()
+		if m.trades == out {
+			m.trades = nil
+		}
+		m.lock.Unlock()
+	}()
+	return nil
+}
+
+// Close stop listening reconnection and unsubscribe trades 停止监听重连并取消成交订阅
+func (m *Manager) Close() {
+	m.Exg.GetExg().SetWsReConListener(m.key, nil)
+	m.lock.Lock()
+	out := m.trades
+	m.trades = nil
+	m.lock.Unlock()
+	if out != nil {
+		banexg.UnSubMyTrades(m.Exg, m.Params, out)
+	}
+}
+
+/*
+CreateOrder
+create order with client order id (generated if empty) and track it. Rejected order is tracked and output too.
+When the result is unknown (network fail, timeout, server error), the order is kept with empty status,
+and resolved by client order id in Reconcile.
+使用客户端订单ID(为空时生成)创建订单并跟踪。被拒绝的订单也会跟踪并输出。
+结果未知(网络失败、超时、服务器错误)时保留空状态的订单，在Reconcile中通过客户端订单ID确定。
+*/
+func (m *Manager) CreateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*banexg.Order, *errs.Error) {
+	args := utils.SafeParams(m.Params)
+	maps.Copy(args, params)
+	clientID := utils.GetMapVal(args, banexg.ParamClientOrderId, "")
+	if clientID == "" {
+		clientID = "om" + utils.UUID(20)
+		args[banexg.ParamClientOrderId] = clientID
+	}
+	m.lock.Lock()
+	if _, ok := m.orders[clientID]; ok {
+		m.lock.Unlock()
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "duplicate client order id: %s", clientID)
+	}
+	// track before sending, so that fills arrived before response are not lost
+	m.orders[clientID] = &banexg.Order{ClientOrderID: clientID, Symbol: symbol, Type: odType, Side: side,
+		Amount: amount, Price: price, Remaining: amount, Fee: &banexg.Fee{}}
+	m.lock.Unlock()
+	res, err := m.Exg.CreateOrder(symbol, odType, side, amount, price, args)
+	if err != nil {
+		if isRejected(err) {
+			m.lock.Lock()
+			od := m.orders[clientID]
+			if od.Status == "" {
+				od.Status = banexg.OdStatusRejected
+				m.emit(od)
+			}
+			m.lock.Unlock()
+		} else {
+			log.Warn("order result unknown, wait reconcile", zap.String("cid", clientID), zap.Error(err))
+		}
+		return nil, err
+	}
+	if res.ClientOrderID == "" {
+		res.ClientOrderID = clientID
+	}
+	return m.ApplyOrder(res), nil
+}
+
+/*
+isRejected
+whether the order is surely not placed: refused by exchange (4xx), or failed before sending.
+Network fail, timeout and server errors (5xx) are unknown.
+订单是否确定未下单：被交易所拒绝(4xx)，或发送前失败。网络失败、超时、服务器错误(5xx)视为未知。
+*/
+func isRejected(err *errs.Error) bool {
+	switch err.Code {
+	case errs.CodeNetFail, errs.CodeConnectFail, errs.CodeRunTime, errs.CodeInvalidResponse, errs.CodeUnmarshalFail,
+		errs.CodeWsReadFail, errs.CodeExpired:
+		return false
+	}
+	return err.Code < 500 && err.Code != 408
+}
+
+/*
+CancelOrder
+cancel tracked order by client id
+通过客户端ID撤销跟踪的订单
+*/
+func (m *Manager) CancelOrder(clientID string) (*banexg.Order, *errs.Error) {
+	m.lock.Lock()
+	od, ok := m.orders[clientID]
+	var id, symbol string
+	if ok {
+		id, symbol = od.ID, od.Symbol
+	}
+	m.lock.Unlock()
+	if !ok || id == "" {
+		return nil, errs.NewMsg(errs.CodeParamInvalid, "order not tracked or not created: %s", clientID)
+	}
+	res, err := m.Exg.CancelOrder(id, symbol, utils.SafeParams(m.Params))
+	if err != nil {
+		return nil, err
+	}
+	res.ClientOrderID = clientID
+	return m.ApplyOrder(res), nil
+}
+
+/*
+Track
+start tracking an order not created by manager, ClientOrderID is required
+跟踪不是由管理器创建的订单，ClientOrderID必填
+*/
+func (m *Manager) Track(od *banexg.Order) *errs.Error {
+	if od == nil || od.ClientOrderID == "" {
+		return errs.NewMsg(errs.CodeParamRequired, "ClientOrderID is required to track order")
+	}
+	m.ApplyOrder(od)
+	return nil
+}
+
+// Get return a copy of tracked order, nil if not found 返回跟踪订单的副本
+func (m *Manager) Get(clientID string) *banexg.Order {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if od, ok := m.orders[clientID]; ok {
+		return copyOrder(od)
+	}
+	return nil
+}
+
+// OpenOrders return copies of tracked orders not finished 返回未结束的跟踪订单副本
+func (m *Manager) OpenOrders() []*banexg.Order {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	res := make([]*banexg.Order, 0)
+	for _, od := range m.orders {
+		if !IsDone(od.Status) {
+			res = append(res, copyOrder(od))
+		}
+	}
+	return res
+}
+
+/*
+Remove
+stop tracking finished orders, return removed count
+停止跟踪已结束的订单，返回移除数量
+*/
+func (m *Manager) Remove(clientIDs ...string) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	num := 0
+	for _, cid := range clientIDs {
+		od, ok := m.orders[cid]
+		if !ok || !IsDone(od.Status) {
+			continue
+		}
+		delete(m.orders, cid)
+		delete(m.byId, od.ID)
+		delete(m.tradeIds, cid)
+		num += 1
+	}
+	return num
+}
+
+// find require lock 需要加锁
+func (m *Manager) find(clientID, id string) *banexg.Order {
+	if clientID != "" {
+		if od, ok := m.orders[clientID]; ok {
+			return od
+		}
+	}
+	if id != "" {
+		if cid, ok := m.byId[id]; ok {
+			return m.orders[cid]
+		}
+	}
+	return nil
+}
+
+/*
+ApplyOrder
+apply order snapshot from REST api. Status only moves forward and filled never decreases.
+Untracked order with ClientOrderID is tracked. Return copy of the tracked order.
+应用REST接口的订单快照。状态只前进，成交量不减少。带ClientOrderID的未跟踪订单会被跟踪。返回跟踪订单的副本。
+*/
+func (m *Manager) ApplyOrder(snap *banexg.Order) *banexg.Order {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	od := m.find(snap.ClientOrderID, snap.ID)
+	if od == nil {
+		if snap.ClientOrderID == "" {
+			return nil
+		}
+		od = copyOrder(snap)
+		od.Status = ""
+		od.Filled = 0
+		m.orders[od.ClientOrderID] = od
+	}
+	if snap.ID != "" && od.ID == "" {
+		od.ID = snap.ID
+	}
+	if od.ID != "" {
+		m.byId[od.ID] = od.ClientOrderID
+	}
+	changed := false
+	if od.Timestamp == 0 {
+		od.Timestamp, od.Datetime = snap.Timestamp, snap.Datetime
+	}
+	if snap.Filled > od.Filled {
+		od.Filled = snap.Filled
+		if snap.Average > 0 {
+			od.Average = snap.Average
+		}
+		if snap.Cost > 0 {
+			od.Cost = snap.Cost
+		}
+		if snap.Fee != nil && (od.Fee == nil || snap.Fee.Cost > od.Fee.Cost) {
+			fee := *snap.Fee
+			od.Fee = &fee
+		}
+		changed = true
+	}
+	if snap.Amount > 0 {
+		od.Amount = snap.Amount
+	}
+	if snap.LastUpdateTimestamp > od.LastUpdateTimestamp {
+		od.LastUpdateTimestamp = snap.LastUpdateTimestamp
+	}
+	if m.setStatus(od, snap.Status) {
+		changed = true
+	}
+	od.Remaining = max(od.Amount-od.Filled, 0)
+	if changed {
+		m.emit(od)
+	}
+	return copyOrder(od)
+}
+
+/*
+OnTrade
+apply a fill or status change from WatchMyTrades, duplicated trades are ignored.
+Trades of untracked orders are ignored.
+应用WatchMyTrades的成交或状态变化，重复成交被忽略。未跟踪订单的成交被忽略。
+*/
+func (m *Manager) OnTrade(trade *banexg.MyTrade) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	od := m.find(trade.ClientID, trade.Order)
+	if od == nil {
+		return
+	}
+	if od.ID == "" && trade.Order != "" {
+		od.ID = trade.Order
+		m.byId[od.ID] = od.ClientOrderID
+	}
+	changed := false
+	if trade.Amount > 0 {
+		ids, ok := m.tradeIds[od.ClientOrderID]
+		if !ok {
+			ids = make(map[string]bool)
+			m.tradeIds[od.ClientOrderID] = ids
+		}
+		if trade.ID != "" && ids[trade.ID] {
+			return
+		}
+		ids[trade.ID] = true
+		applyFill(od, trade)
+		changed = true
+	}
+	if trade.Timestamp > od.LastUpdateTimestamp {
+		od.LastUpdateTimestamp = trade.Timestamp
+	}
+	if m.setStatus(od, trade.State) {
+		changed = true
+	}
+	if changed {
+		m.emit(od)
+	}
+}
+
+/*
+applyFill
+add a fill to order, keep cumulative filled/cost/average/fee consistent. The cumulative filled reported by
+exchange is authoritative, only the increment over current filled is added to cost and fee, so a fill already
+applied by REST snapshot is not counted again.
+将成交加到订单，保持累计成交量/成交额/均价/手续费一致。交易所返回的累计成交量优先，只将超过当前成交量的增量
+计入成交额和手续费，避免REST快照已应用的成交被重复计算。
+*/
+func applyFill(od *banexg.Order, trade *banexg.MyTrade) {
+	tradeCopy := trade.Trade
+	od.Trades = append(od.Trades, &tradeCopy)
+	od.LastTradeTimestamp = max(od.LastTradeTimestamp, trade.Timestamp)
+	filled := od.Filled + trade.Amount
+	if trade.Filled > 0 {
+		filled = max(od.Filled, trade.Filled)
+	}
+	delta := min(filled-od.Filled, trade.Amount)
+	if delta <= 0 {
+		return
+	}
+	rate := delta / trade.Amount
+	if trade.Average > 0 && trade.Filled >= filled {
+		od.Average = trade.Average
+	} else {
+		od.Average = (od.Average*od.Filled + trade.Price*delta) / filled
+	}
+	od.Filled = filled
+	od.Remaining = max(od.Amount-od.Filled, 0)
+	cost := trade.Cost
+	if cost == 0 {
+		cost = trade.Price * trade.Amount
+	}
+	od.Cost += cost * rate
+	if trade.Fee != nil {
+		if od.Fee == nil {
+			od.Fee = &banexg.Fee{}
+		}
+		if od.Fee.Currency == "" || od.Fee.Currency == trade.Fee.Currency {
+			od.Fee.Currency = trade.Fee.Currency
+			od.Fee.Cost += trade.Fee.Cost * rate
+			od.Fee.IsMaker = trade.Fee.IsMaker
+		} else {
+			log.Warn("fee currency changed, ignored", zap.String("cid", od.ClientOrderID),
+				zap.String("old", od.Fee.Currency), zap.String("new", trade.Fee.Currency))
+		}
+	}
+	if od.Status == "" || od.Status == banexg.OdStatusOpen {
+		od.Status = banexg.OdStatusPartFilled
+	}
+}
+
+// setStatus update status when it moves forward, require lock 状态前进时更新，需要加锁
+func (m *Manager) setStatus(od *banexg.Order, status string) bool {
+	if status == "" || status == od.Status {
+		return false
+	}
+	newRank, ok := statusRank[status]
+	if !ok || IsDone(od.Status) || newRank < statusRank[od.Status] {
+		return false
+	}
+	od.Status = status
+	return true
+}
+
+// emit require lock, transitions are queued when Out is full, so none is lost 需要加锁，Out已满时排队，不丢失状态变化
+func (m *Manager) emit(od *banexg.Order) {
+	res := copyOrder(od)
+	if !m.pumping {
+		select {
+		case m.Out <- res:
+			return
+		default:
+		}
+		m.pumping = true
+		go m.pump()
+	}
+	m.queue = append(m.queue, res)
+}
+
+// pump send queued transitions to Out in order, exit when queue is empty 按顺序将排队的状态变化写入Out，队列为空时退出
+func (m *Manager) pump() {
+	for {
+		m.lock.Lock()
+		if len(m.queue) == 0 {
+			m.pumping = false
+			m.lock.Unlock()
+			return
+		}
+		od := m.queue[0]
+		m.lock.Unlock()
+		m.Out <- od
+		m.lock.Lock()
+		m.queue[0] = nil
+		m.queue = m.queue[1:]
+		m.lock.Unlock()
+	}
+}
+
+func copyOrder(od *banexg.Order) *banexg.Order {
+	res := *od
+	if od.Fee != nil {
+		fee := *od.Fee
+		res.Fee = &fee
+	}
+	res.Trades = append([]*banexg.Trade{}, od.Trades...)
+	return &res
+}
+
+/*
+Reconcile
+fetch open orders of symbols with tracked open orders, apply snapshots; tracked orders missing from open orders
+are fetched by FetchOrder to get their final status. Orders with unknown create result are fetched by client order id.
+Open orders not tracked by manager are ignored.
+获取有跟踪挂单的品种的挂单并应用快照，忽略未跟踪的挂单；不在挂单中的跟踪订单通过FetchOrder获取最终状态。创建结果未知的订单通过客户端订单ID获取。
+*/
+func (m *Manager) Reconcile() *errs.Error {
+	m.lock.Lock()
+	bySymbol := make(map[string][]*banexg.Order)
+	for _, od := range m.orders {
+		if !IsDone(od.Status) {
+			bySymbol[od.Symbol] = append(bySymbol[od.Symbol], copyOrder(od))
+		}
+	}
+	m.lock.Unlock()
+	var lastErr *errs.Error
+	for symbol, ods := range bySymbol {
+		opens, err := m.Exg.FetchOpenOrders(symbol, 0, 0, utils.SafeParams(m.Params))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		openIds := make(map[string]bool, len(opens)*2)
+		for _, od := range opens {
+			openIds[od.ID] = true
+			if od.ClientOrderID != "" {
+				openIds[od.ClientOrderID] = true
+			}
+			// orders placed by other processes or web are not tracked
+			if m.isTracked(od) {
+				m.ApplyOrder(od)
+			}
+		}
+		for _, od := range ods {
+			if od.ID != "" && openIds[od.ID] || od.ID == "" && openIds[od.ClientOrderID] {
+				continue
+			}
+			args := utils.SafeParams(m.Params)
+			if od.ID == "" {
+				args[banexg.ParamClientOrderId] = od.ClientOrderID
+			}
+			res, err := m.Exg.FetchOrder(symbol, od.ID, args)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			res.ClientOrderID = od.ClientOrderID
+			m.ApplyOrder(res)
+		}
+	}
+	return lastErr
+}
+
+// isTracked whether the order is placed by manager 订单是否由管理器跟踪
+func (m *Manager) isTracked(od *banexg.Order) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.find(od.ClientOrderID, od.ID) != nil
+}
@@ -0,0 +1,205 @@
+package odmgr
+
+import (
+	"github.com/banbox/banexg"
+	"github.com/banbox/banexg/errs"
+	"math"
+	"testing"
+	"time"
+)
+
+type fakeExg struct {
+	*banexg.Exchange
+	opens     []*banexg.Order
+	remote    map[string]*banexg.Order // order id or client id: order
+	createErr *errs.Error
+}
+
+func (e *fakeExg) CreateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*banexg.Order, *errs.Error) {
+	if e.createErr != nil {
+		return nil, e.createErr
+	}
+	cid := params[banexg.ParamClientOrderId].(string)
+	return &banexg.Order{ID: "1", ClientOrderID: cid, Symbol: symbol, Type: odType, Side: side, Amount: amount,
+		Price: price, Status: banexg.OdStatusOpen}, nil
+}
+
+func (e *fakeExg) FetchOpenOrders(symbol string, since int64, limit int, params map[string]interface{}) ([]*banexg.Order, *errs.Error) {
+	return e.opens, nil
+}
+
+func (e *fakeExg) FetchOrder(symbol, orderId string, params map[string]interface{}) (*banexg.Order, *errs.Error) {
+	if orderId == "" {
+		orderId, _ = params[banexg.ParamClientOrderId].(string)
+	}
+	if od, ok := e.remote[orderId]; ok {
+		return od, nil
+	}
+	return nil, errs.NewMsg(400, "order not found: %s", orderId)
+}
+
+func makeTrade(id string, amount, price float64, state string) *banexg.MyTrade {
+	res := &banexg.MyTrade{State: state}
+	res.ID = id
+	res.Order = "1"
+	res.Symbol = "BTC/USDT"
+	res.Amount = amount
+	res.Price = price
+	res.Fee = &banexg.Fee{Currency: "USDT", Cost: amount * 0.1}
+	return res
+}
+
+func drain(m *Manager) []*banexg.Order {
+	var res []*banexg.Order
+	for {
+		select {
+		case od := <-m.Out:
+			res = append(res, od)
+		default:
+			return res
+		}
+	}
+}
+
+func TestManagerFills(t *testing.T) {
+	exg := &fakeExg{Exchange: &banexg.Exchange{}}
+	m := New(exg, nil)
+	od, err := m.CreateOrder("BTC/USDT", banexg.OdTypeLimit, banexg.OdSideBuy, 2, 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cid := od.ClientOrderID
+	if cid == "" || od.Status != banexg.OdStatusOpen {
+		t.Fatalf("bad created order: %+v", od)
+	}
+	m.OnTrade(makeTrade("t1", 1, 100, banexg.OdStatusPartFilled))
+	m.OnTrade(makeTrade("t1", 1, 100, banexg.OdStatusPartFilled))
+	m.OnTrade(makeTrade("t2", 1, 110, banexg.OdStatusFilled))
+	// stale snapshot should not roll back
+	m.ApplyOrder(&banexg.Order{ID: "1", Status: banexg.OdStatusOpen, Filled: 1})
+	od = m.Get(cid)
+	if od.Status != banexg.OdStatusFilled || od.Filled != 2 || od.Average != 105 || od.Remaining != 0 {
+		t.Fatalf("bad order: %+v", od)
+	}
+	if od.Fee.Cost != 0.2 || len(od.Trades) != 2 || od.Cost != 210 {
+		t.Errorf("bad fee or trades: %+v %v", od.Fee, len(od.Trades))
+	}
+	var states []string
+	for _, o := range drain(m) {
+		states = append(states, o.Status)
+	}
+	want := []string{banexg.OdStatusOpen, banexg.OdStatusPartFilled, banexg.OdStatusFilled}
+	if len(states) != len(want) {
+		t.Fatalf("bad transitions: %v", states)
+	}
+	for i, s := range want {
+		if states[i] != s {
+			t.Errorf("transition %d: %v, expect %v", i, states[i], s)
+		}
+	}
+	if m.Remove(cid) != 1 || m.Get(cid) != nil {
+		t.Errorf("finished order should be removed")
+	}
+}
+
+func TestManagerReconcile(t *testing.T) {
+	exg := &fakeExg{Exchange: &banexg.Exchange{}}
+	m := New(exg, nil)
+	od, _ := m.CreateOrder("BTC/USDT", banexg.OdTypeLimit, banexg.OdSideBuy, 2, 100, nil)
+	exg.remote = map[string]*banexg.Order{
+		"1": {ID: "1", Symbol: "BTC/USDT", Status: banexg.OdStatusCanceled, Filled: 0.5, Average: 100, Amount: 2},
+	}
+	if err := m.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	res := m.Get(od.ClientOrderID)
+	if res.Status != banexg.OdStatusCanceled || res.Filled != 0.5 || res.Remaining != 1.5 {
+		t.Fatalf("bad reconciled order: %+v", res)
+	}
+	if len(m.OpenOrders()) != 0 {
+		t.Errorf("no open orders expected")
+	}
+	// orders placed by others are not tracked
+	m.CreateOrder("BTC/USDT", banexg.OdTypeLimit, banexg.OdSideBuy, 2, 100, nil)
+	exg.opens = []*banexg.Order{
+		{ID: "1", ClientOrderID: "", Symbol: "BTC/USDT", Status: banexg.OdStatusOpen, Amount: 2},
+		{ID: "9", ClientOrderID: "web_abc", Symbol: "BTC/USDT", Status: banexg.OdStatusOpen, Amount: 1},
+	}
+	if err := m.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Get("web_abc") != nil || len(m.OpenOrders()) != 1 {
+		t.Errorf("foreign order should not be tracked: %v", len(m.OpenOrders()))
+	}
+}
+
+func TestManagerOutFull(t *testing.T) {
+	exg := &fakeExg{Exchange: &banexg.Exchange{}}
+	m := New(exg, map[string]interface{}{banexg.ParamChanCap: 1})
+	od, _ := m.CreateOrder("BTC/USDT", banexg.OdTypeLimit, banexg.OdSideBuy, 2, 100, nil)
+	m.OnTrade(makeTrade("t1", 1, 100, banexg.OdStatusPartFilled))
+	m.OnTrade(makeTrade("t2", 1, 100, banexg.OdStatusFilled))
+	want := []string{banexg.OdStatusOpen, banexg.OdStatusPartFilled, banexg.OdStatusFilled}
+	for i, s := range want {
+		select {
+		case res := <-m.Out:
+			if res.ClientOrderID != od.ClientOrderID || res.Status != s {
+				t.Errorf("transition %d: %v, expect %v", i, res.Status, s)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("transition %d lost when out is full", i)
+		}
+	}
+}
+
+func TestManagerRestThenTrade(t *testing.T) {
+	exg := &fakeExg{Exchange: &banexg.Exchange{}}
+	m := New(exg, nil)
+	od, _ := m.CreateOrder("BTC/USDT", banexg.OdTypeLimit, banexg.OdSideBuy, 2, 100, nil)
+	// fill is applied by REST snapshot first, then the same fill arrives by websocket
+	m.ApplyOrder(&banexg.Order{ID: "1", Status: banexg.OdStatusPartFilled, Filled: 1, Average: 100, Cost: 100,
+		Fee: &banexg.Fee{Currency: "USDT", Cost: 0.1}})
+	trade := makeTrade("t1", 1, 100, banexg.OdStatusPartFilled)
+	trade.Filled = 1
+	m.OnTrade(trade)
+	trade = makeTrade("t2", 1, 110, banexg.OdStatusFilled)
+	trade.Filled = 2
+	m.OnTrade(trade)
+	res := m.Get(od.ClientOrderID)
+	if res.Status != banexg.OdStatusFilled || res.Filled != 2 || res.Cost != 210 || res.Average != 105 {
+		t.Fatalf("fill should not be counted twice: %+v", res)
+	}
+	if math.Abs(res.Fee.Cost-0.2) > 1e-9 {
+		t.Errorf("bad fee: %+v", res.Fee)
+	}
+}
+
+func TestManagerUnknownCreate(t *testing.T) {
+	exg := &fakeExg{Exchange: &banexg.Exchange{}, createErr: errs.NewMsg(errs.CodeNetFail, "timeout")}
+	m := New(exg, nil)
+	_, err := m.CreateOrder("BTC/USDT", banexg.OdTypeLimit, banexg.OdSideBuy, 2, 100, map[string]interface{}{
+		banexg.ParamClientOrderId: "c1",
+	})
+	if err == nil {
+		t.Fatal("expect create error")
+	}
+	if od := m.Get("c1"); od == nil || od.Status != "" {
+		t.Fatalf("order of unknown result should be kept without status: %+v", od)
+	}
+	exg.remote = map[string]*banexg.Order{
+		"c1": {ID: "5", ClientOrderID: "c1", Symbol: "BTC/USDT", Status: banexg.OdStatusOpen, Amount: 2},
+	}
+	if err = m.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	if od := m.Get("c1"); od.ID != "5" || od.Status != banexg.OdStatusOpen {
+		t.Fatalf("unknown order should be resolved by client id: %+v", od)
+	}
+	exg.createErr = errs.NewMsg(400, "insufficient balance")
+	_, _ = m.CreateOrder("BTC/USDT", banexg.OdTypeLimit, banexg.OdSideBuy, 2, 100, map[string]interface{}{
+		banexg.ParamClientOrderId: "c2",
+	})
+	if od := m.Get("c2"); od == nil || od.Status != banexg.OdStatusRejected {
+		t.Errorf("order refused by exchange should be rejected: %+v", od)
+	}
+}
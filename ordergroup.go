@@ -0,0 +1,456 @@
+package banexg
+
+import (
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
+	"github.com/banbox/banexg/utils"
+	"go.uber.org/zap"
+	"slices"
+	"sync"
+)
+
+/*
+OrderGroupMgr
+emulate OCO/bracket orders for exchanges without native support. Trades from WatchMyTrades drive the groups:
+when the entry fills exit legs are placed, when one leg fills the siblings are canceled.
+Trades are received by SubMyTrades, other consumers should also use SubMyTrades instead of WatchMyTrades.
+Groups are registered with client order ids of legs before legs are sent, so fast fills are not missed.
+为不支持原生OCO/括号订单的交易所模拟。由WatchMyTrades的成交驱动：入场成交后下出场订单，一个出场订单成交后撤销其他订单。
+通过SubMyTrades接收成交，其他消费者也应使用SubMyTrades而非WatchMyTrades。
+下单前先按出场订单的客户端ID注册订单组，避免遗漏快速成交。
+*/
+type OrderGroupMgr struct {
+	Exg     BanExchange
+	Params  map[string]interface{}                   // params for WatchMyTrades
+	OnError func(group *OrderGroup, err *errs.Error) // place legs or cancel siblings fail
+
+	groups    map[string]*OrderGroup // group id: group
+	orderMap  map[string]*OrderGroup // order id: group
+	clientMap map[string]*OrderGroup // client order id: group
+	lock      sync.Mutex
+	started   bool
+}
+
+func NewOrderGroupMgr(exg BanExchange, params map[string]interface{}) *OrderGroupMgr {
+	return &OrderGroupMgr{
+		Exg:       exg,
+		Params:    params,
+		groups:    make(map[string]*OrderGroup),
+		orderMap:  make(map[string]*OrderGroup),
+		clientMap: make(map[string]*OrderGroup),
+	}
+}
+
+/*
+GetOrderGroupMgr
+get or create the OrderGroupMgr of account in params
+获取或创建params中账户的OrderGroupMgr
+*/
+func GetOrderGroupMgr(exg BanExchange, params map[string]interface{}) *OrderGroupMgr {
+	e := exg.GetExg()
+	accName := e.GetAccName(params)
+	e.odGroupLock.Lock()
+	defer e.odGroupLock.Unlock()
+	if e.OdGroupMgrs == nil {
+		e.OdGroupMgrs = make(map[string]*OrderGroupMgr)
+	}
+	mgr, ok := e.OdGroupMgrs[accName]
+	if !ok {
+		var args map[string]interface{}
+		if accName != "" {
+			args = map[string]interface{}{ParamAccount: accName}
+		}
+		mgr = NewOrderGroupMgr(exg, args)
+		e.OdGroupMgrs[accName] = mgr
+	}
+	return mgr
+}
+
+/*
+Start
+subscribe trades by SubMyTrades and process them in background, called automatically when creating groups
+通过SubMyTrades订阅成交并在后台处理，创建订单组时自动调用
+*/
+func (m *OrderGroupMgr) Start() *errs.Error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.started {
+		return nil
+	}
+	out, err := SubMyTrades(m.Exg, m.Params)
+	if err != nil {
+		return err
+	}
+	m.started = true
+	go func() {
+		for trade := range out {
+			m.OnTrade(trade)
+		}
+		m.lock.Lock()
+		m.started = false
+		m.lock.Unlock()
+	}()
+	return nil
+}
+
+/*
+CreateOCO
+place take profit limit order at price and stop loss order at stopPrice, linked by client
+下止盈限价单和止损单，由客户端关联
+*/
+func (m *OrderGroupMgr) CreateOCO(symbol, side string, amount, price, stopPrice float64, params map[string]interface{}) (*OrderGroup, *errs.Error) {
+	err := m.Start()
+	if err != nil {
+		return nil, err
+	}
+	return m.createOCO(symbol, side, amount, price, stopPrice, params)
+}
+
+func (m *OrderGroupMgr) createOCO(symbol, side string, amount, price, stopPrice float64, params map[string]interface{}) (*OrderGroup, *errs.Error) {
+	if price <= 0 || stopPrice <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "price and stopPrice are required for OCO")
+	}
+	args := utils.SafeParams(params)
+	stopLimit := utils.PopMapVal(args, ParamStopLimitPrice, float64(0))
+	delete(args, ParamClientOrderId)
+	group := &OrderGroup{
+		ID:     "oco" + utils.UUID(12),
+		Symbol: symbol,
+		Status: OdStatusOpen,
+	}
+	tpCli, slCli := group.ID+"tp", group.ID+"sl"
+	group.clientIds = []string{tpCli, slCli}
+	// register before sending, so that fills of legs are matched by client order id
+	m.lock.Lock()
+	m.addGroup(group)
+	m.lock.Unlock()
+	tpArgs := utils.SafeParams(args)
+	tpArgs[ParamClientOrderId] = tpCli
+	tp, err := m.Exg.CreateOrder(symbol, OdTypeLimit, side, amount, price, tpArgs)
+	if err != nil {
+		m.lock.Lock()
+		m.removeGroup(group)
+		m.lock.Unlock()
+		return nil, err
+	}
+	if m.addLeg(group, tp, tpCli) {
+		// take profit filled before stop loss is placed
+		return group, nil
+	}
+	stopType := OdTypeMarket
+	if stopLimit > 0 {
+		stopType = OdTypeLimit
+	}
+	args[ParamStopLossPrice] = stopPrice
+	args[ParamClientOrderId] = slCli
+	sl, err := m.Exg.CreateOrder(symbol, stopType, side, amount, stopLimit, args)
+	if err != nil {
+		m.lock.Lock()
+		m.removeGroup(group)
+		m.lock.Unlock()
+		_, err2 := m.Exg.CancelOrder(tp.ID, symbol, utils.SafeParams(params))
+		if err2 != nil {
+			log.Error("cancel take profit of failed OCO fail", zap.String("id", tp.ID), zap.Error(err2))
+		}
+		return nil, err
+	}
+	m.addLeg(group, sl, slCli)
+	return group, nil
+}
+
+/*
+addLeg
+add a placed leg to registered group. If the group is already finished, the leg is canceled unless it's the filled one.
+return whether the group is finished
+将已下单的出场订单加入已注册的订单组。如果订单组已结束，除非是已成交的订单，否则撤销它。返回订单组是否已结束
+*/
+func (m *OrderGroupMgr) addLeg(group *OrderGroup, od *Order, clientId string) bool {
+	m.lock.Lock()
+	group.Legs = append(group.Legs, od)
+	done := group.Status != OdStatusOpen
+	isHit := group.hitId == od.ID || group.hitId == clientId
+	if !done {
+		m.orderMap[od.ID] = group
+	}
+	m.lock.Unlock()
+	if done && !isHit {
+		m.cancelOrders(group, []*Order{od})
+	}
+	return done
+}
+
+/*
+CreateBracket
+place entry order, exit legs are placed by exchange's CreateOCO (emulated if not implemented) after entry filled
+下入场订单，入场成交后通过交易所CreateOCO下出场订单（未实现时模拟）
+*/
+func (m *OrderGroupMgr) CreateBracket(req *BracketReq, params map[string]interface{}) (*OrderGroup, *errs.Error) {
+	if req.StopLoss <= 0 || req.TakeProfit <= 0 {
+		return nil, errs.NewMsg(errs.CodeParamRequired, "StopLoss and TakeProfit are required for bracket")
+	}
+	err := m.Start()
+	if err != nil {
+		return nil, err
+	}
+	group := &OrderGroup{
+		ID:     "bkt" + utils.UUID(12),
+		Symbol: req.Symbol,
+		Status: OdStatusOpen,
+		req:    req,
+		args:   MergeOrderParams(params, &OrderReq{Params: req.Params}),
+	}
+	if req.Type == "" {
+		err = m.placeLegs(group, req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		return group, nil
+	}
+	args := utils.SafeParams(group.args)
+	delete(group.args, ParamClientOrderId)
+	group.entryCli = utils.GetMapVal(args, ParamClientOrderId, "")
+	if group.entryCli == "" {
+		group.entryCli = group.ID + "en"
+		args[ParamClientOrderId] = group.entryCli
+	}
+	// register before sending, so that trades of entry are matched by client order id
+	m.lock.Lock()
+	m.addGroup(group)
+	m.lock.Unlock()
+	entry, err := m.Exg.CreateOrder(req.Symbol, req.Type, req.Side, req.Amount, req.Price, args)
+	m.lock.Lock()
+	if err != nil {
+		m.removeGroup(group)
+		m.lock.Unlock()
+		return nil, err
+	}
+	if group.Entry != nil {
+		// filled trade of entry arrived before response
+		entry.Status = group.Entry.Status
+		entry.Filled = max(entry.Filled, group.Entry.Filled)
+	}
+	group.Entry = entry
+	canceled := group.Status == OdStatusCanceled
+	place := !group.placed && entry.Status == OdStatusFilled
+	if canceled {
+		place = false
+	} else {
+		m.orderMap[entry.ID] = group
+		group.placed = group.placed || place
+	}
+	m.lock.Unlock()
+	if canceled && entry.Status != OdStatusFilled {
+		// group canceled before entry is placed
+		m.cancelOrders(group, []*Order{entry})
+	}
+	if place {
+		err = m.placeLegs(group, entry.Filled)
+		if err != nil {
+			return group, err
+		}
+	}
+	return group, nil
+}
+
+/*
+placeLegs
+place stop loss and take profit legs of bracket on the opposite side
+在反方向下括号订单的止损止盈出场订单
+*/
+func (m *OrderGroupMgr) placeLegs(group *OrderGroup, amount float64) *errs.Error {
+	req := group.req
+	side := OdSideSell
+	if req.Side == OdSideSell {
+		side = OdSideBuy
+	}
+	if amount <= 0 {
+		amount = req.Amount
+	}
+	args := utils.SafeParams(group.args)
+	if market, err := m.Exg.GetMarket(req.Symbol); err == nil && market.Contract {
+		args[ParamReduceOnly] = true
+	}
+	sub, err := m.Exg.CreateOCO(req.Symbol, side, amount, req.TakeProfit, req.StopLoss, utils.SafeParams(args))
+	if err != nil && err.Code == errs.CodeNotImplement {
+		sub, err = m.createOCO(req.Symbol, side, amount, req.TakeProfit, req.StopLoss, args)
+	}
+	if err != nil {
+		return err
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	group.Native = sub.Native
+	group.Legs = sub.Legs
+	if group.Native {
+		m.removeGroup(group)
+		return nil
+	}
+	// take over legs from emulated OCO group, which may be finished already
+	m.removeGroup(sub)
+	group.clientIds = sub.clientIds
+	group.hitId = sub.hitId
+	if sub.Status != OdStatusOpen {
+		group.Status = sub.Status
+		m.removeGroup(group)
+		return nil
+	}
+	m.addGroup(group)
+	return nil
+}
+
+// addGroup require lock
+func (m *OrderGroupMgr) addGroup(group *OrderGroup) {
+	m.groups[group.ID] = group
+	if group.Entry != nil {
+		m.orderMap[group.Entry.ID] = group
+	}
+	for _, od := range group.Legs {
+		m.orderMap[od.ID] = group
+	}
+	for _, id := range group.clientIds {
+		m.clientMap[id] = group
+	}
+	if group.entryCli != "" {
+		m.clientMap[group.entryCli] = group
+	}
+}
+
+// removeGroup require lock
+func (m *OrderGroupMgr) removeGroup(group *OrderGroup) {
+	delete(m.groups, group.ID)
+	if group.Entry != nil {
+		delete(m.orderMap, group.Entry.ID)
+	}
+	for _, od := range group.Legs {
+		delete(m.orderMap, od.ID)
+	}
+	for _, id := range group.clientIds {
+		delete(m.clientMap, id)
+	}
+	if group.entryCli != "" {
+		delete(m.clientMap, group.entryCli)
+	}
+}
+
+/*
+OnTrade
+process a trade of account, called by Start; call it directly if trades are consumed elsewhere
+处理账户的一个成交，由Start调用；如果在别处消费成交，可直接调用
+*/
+func (m *OrderGroupMgr) OnTrade(trade *MyTrade) {
+	m.lock.Lock()
+	group, ok := m.orderMap[trade.Order]
+	if !ok && trade.ClientID != "" {
+		group, ok = m.clientMap[trade.ClientID]
+	}
+	if !ok || group.Status != OdStatusOpen {
+		m.lock.Unlock()
+		return
+	}
+	isEntry := group.Entry != nil && group.Entry.ID == trade.Order
+	if group.entryCli != "" && trade.ClientID == group.entryCli {
+		isEntry = true
+	}
+	if isEntry {
+		if trade.State != OdStatusFilled || group.placed {
+			m.lock.Unlock()
+			return
+		}
+		group.placed = true
+		if group.Entry == nil {
+			// response of CreateOrder not arrived yet, it takes status from here
+			group.Entry = &Order{ID: trade.Order, ClientOrderID: trade.ClientID, Symbol: group.Symbol}
+		}
+		group.Entry.Status = trade.State
+		group.Entry.Filled = trade.Filled
+		m.lock.Unlock()
+		go func() {
+			if err := m.placeLegs(group, trade.Filled); err != nil {
+				m.onError(group, err)
+			}
+		}()
+		return
+	}
+	if trade.State != OdStatusPartFilled && trade.State != OdStatusFilled {
+		m.lock.Unlock()
+		return
+	}
+	group.Status = OdStatusFilled
+	group.hitId = trade.Order
+	if trade.ClientID != "" && slices.Contains(group.clientIds, trade.ClientID) {
+		group.hitId = trade.ClientID
+	}
+	var siblings []*Order
+	for _, od := range group.Legs {
+		if od.ID == trade.Order {
+			od.Status = trade.State
+			od.Filled = trade.Filled
+		} else {
+			siblings = append(siblings, od)
+		}
+	}
+	m.removeGroup(group)
+	m.lock.Unlock()
+	m.cancelOrders(group, siblings)
+}
+
+func (m *OrderGroupMgr) cancelOrders(group *OrderGroup, ods []*Order) {
+	for _, od := range ods {
+		res, err := m.Exg.CancelOrder(od.ID, group.Symbol, utils.SafeParams(m.Params))
+		if err != nil {
+			m.onError(group, err)
+			continue
+		}
+		if res != nil {
+			od.Status = res.Status
+		}
+	}
+}
+
+func (m *OrderGroupMgr) onError(group *OrderGroup, err *errs.Error) {
+	if m.OnError != nil {
+		m.OnError(group, err)
+	} else {
+		log.Error("order group fail", zap.String("id", group.ID), zap.String("symbol", group.Symbol), zap.Error(err))
+	}
+}
+
+// Get return emulated group by id, nil if not found or finished 根据ID获取模拟订单组，不存在或已结束返回nil
+func (m *OrderGroupMgr) Get(id string) *OrderGroup {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.groups[id]
+}
+
+/*
+Cancel
+cancel all open orders of emulated group
+撤销模拟订单组的所有挂单
+*/
+func (m *OrderGroupMgr) Cancel(id string) *errs.Error {
+	m.lock.Lock()
+	group, ok := m.groups[id]
+	if !ok {
+		m.lock.Unlock()
+		return errs.NewMsg(errs.CodeParamInvalid, "order group not found: %s", id)
+	}
+	group.Status = OdStatusCanceled
+	m.removeGroup(group)
+	ods := append([]*Order{}, group.Legs...)
+	if group.Entry != nil && group.Entry.Status != OdStatusFilled {
+		ods = append(ods, group.Entry)
+	}
+	m.lock.Unlock()
+	var lastErr *errs.Error
+	for _, od := range ods {
+		res, err := m.Exg.CancelOrder(od.ID, group.Symbol, utils.SafeParams(m.Params))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if res != nil {
+			od.Status = res.Status
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,180 @@
+package banexg
+
+import (
+	"github.com/banbox/banexg/errs"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type groupExg struct {
+	*Exchange
+	lock     sync.Mutex
+	nextId   int
+	orders   map[string]*Order
+	canceled []string
+	trades   chan *MyTrade
+	onCreate func(od *Order) // called before CreateOrder returns, e.g. simulate fills arrived before response
+}
+
+func (e *groupExg) CreateOrder(symbol, odType, side string, amount, price float64, params map[string]interface{}) (*Order, *errs.Error) {
+	e.lock.Lock()
+	e.nextId += 1
+	od := &Order{ID: strconv.Itoa(e.nextId), Symbol: symbol, Type: odType, Side: side, Amount: amount,
+		Price: price, Status: OdStatusOpen}
+	if val, ok := params[ParamStopLossPrice]; ok {
+		od.StopLossPrice = val.(float64)
+	}
+	od.ClientOrderID, _ = params[ParamClientOrderId].(string)
+	e.orders[od.ID] = od
+	e.lock.Unlock()
+	if e.onCreate != nil {
+		e.onCreate(od)
+	}
+	return od, nil
+}
+
+func (e *groupExg) CancelOrder(id string, symbol string, params map[string]interface{}) (*Order, *errs.Error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.canceled = append(e.canceled, id)
+	return &Order{ID: id, Symbol: symbol, Status: OdStatusCanceled}, nil
+}
+
+func (e *groupExg) GetMarket(symbol string) (*Market, *errs.Error) {
+	return &Market{Symbol: symbol, Contract: true}, nil
+}
+
+func (e *groupExg) WatchMyTrades(params map[string]interface{}) (chan *MyTrade, *errs.Error) {
+	return e.trades, nil
+}
+
+func (e *groupExg) fill(id, state string) {
+	trade := &MyTrade{State: state, Filled: 1}
+	trade.Order = id
+	e.trades <- trade
+}
+
+func TestOrderGroupBracket(t *testing.T) {
+	exg := &groupExg{Exchange: &Exchange{}, orders: map[string]*Order{}, trades: make(chan *MyTrade, 10)}
+	mgr := NewOrderGroupMgr(exg, nil)
+	group, err := mgr.CreateBracket(&BracketReq{Symbol: "BTC/USDT:USDT", Type: OdTypeLimit, Side: OdSideBuy,
+		Amount: 1, Price: 100, StopLoss: 90, TakeProfit: 120}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.Entry == nil || len(group.Legs) != 0 {
+		t.Fatalf("legs should be placed after entry filled")
+	}
+	exg.fill(group.Entry.ID, OdStatusFilled)
+	for i := 0; i < 100; i++ {
+		mgr.lock.Lock()
+		num := len(group.Legs)
+		mgr.lock.Unlock()
+		if num > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+	mgr.lock.Lock()
+	legs := group.Legs
+	mgr.lock.Unlock()
+	if len(legs) != 2 {
+		t.Fatalf("expect 2 legs, got %v", len(legs))
+	}
+	tp, sl := legs[0], legs[1]
+	if tp.Side != OdSideSell || tp.Price != 120 || sl.StopLossPrice != 90 {
+		t.Errorf("bad legs: %+v %+v", tp, sl)
+	}
+	exg.fill(tp.ID, OdStatusFilled)
+	for i := 0; i < 100 && mgr.Get(group.ID) != nil; i++ {
+		time.Sleep(time.Millisecond * 5)
+	}
+	for i := 0; i < 100; i++ {
+		exg.lock.Lock()
+		num := len(exg.canceled)
+		exg.lock.Unlock()
+		if num > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+	exg.lock.Lock()
+	defer exg.lock.Unlock()
+	if len(exg.canceled) != 1 || exg.canceled[0] != sl.ID {
+		t.Errorf("expect stop loss canceled, got %v", exg.canceled)
+	}
+	if group.Status != OdStatusFilled {
+		t.Errorf("expect group filled, got %v", group.Status)
+	}
+}
+
+func TestOrderGroupBracketFastFill(t *testing.T) {
+	exg := &groupExg{Exchange: &Exchange{}, orders: map[string]*Order{}, trades: make(chan *MyTrade, 10)}
+	mgr := NewOrderGroupMgr(exg, nil)
+	// entry fill arrives before CreateOrder returns, should not wait for the lock
+	exg.onCreate = func(od *Order) {
+		if strings.HasSuffix(od.ClientOrderID, "en") {
+			trade := &MyTrade{State: OdStatusFilled, Filled: 1, ClientID: od.ClientOrderID}
+			trade.Order = od.ID
+			mgr.OnTrade(trade)
+		}
+	}
+	group, err := mgr.CreateBracket(&BracketReq{Symbol: "BTC/USDT:USDT", Type: OdTypeMarket, Side: OdSideBuy,
+		Amount: 1, StopLoss: 90, TakeProfit: 120}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		mgr.lock.Lock()
+		num := len(group.Legs)
+		mgr.lock.Unlock()
+		if num > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+	time.Sleep(time.Millisecond * 20)
+	mgr.lock.Lock()
+	defer mgr.lock.Unlock()
+	if group.Entry == nil || group.Entry.ID != "1" || group.Entry.Status != OdStatusFilled || len(group.Legs) != 2 {
+		t.Errorf("bad bracket: %+v %v", group.Entry, len(group.Legs))
+	}
+	exg.lock.Lock()
+	defer exg.lock.Unlock()
+	if len(exg.orders) != 3 {
+		t.Errorf("legs should be placed once, got %v orders", len(exg.orders))
+	}
+}
+
+func TestOrderGroupFastFill(t *testing.T) {
+	for _, leg := range []string{"tp", "sl"} {
+		exg := &groupExg{Exchange: &Exchange{}, orders: map[string]*Order{}, trades: make(chan *MyTrade, 10)}
+		mgr := NewOrderGroupMgr(exg, nil)
+		// fill arrives by websocket before CreateOrder returns
+		exg.onCreate = func(od *Order) {
+			if strings.HasSuffix(od.ClientOrderID, leg) {
+				trade := &MyTrade{State: OdStatusFilled, Filled: 1, ClientID: od.ClientOrderID}
+				trade.Order = od.ID
+				mgr.OnTrade(trade)
+			}
+		}
+		group, err := mgr.CreateOCO("BTC/USDT:USDT", OdSideSell, 1, 120, 90, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if group.Status != OdStatusFilled || mgr.Get(group.ID) != nil {
+			t.Errorf("%s: expect group filled, got %v", leg, group.Status)
+		}
+		exg.lock.Lock()
+		if leg == "tp" && (len(exg.orders) != 1 || len(exg.canceled) != 0) {
+			t.Errorf("tp: stop loss should not be placed, orders: %v, canceled: %v", len(exg.orders), exg.canceled)
+		}
+		if leg == "sl" && (len(exg.canceled) != 1 || exg.canceled[0] != group.Legs[0].ID) {
+			t.Errorf("sl: expect take profit canceled, got %v", exg.canceled)
+		}
+		exg.lock.Unlock()
+	}
+}
@@ -34,14 +34,15 @@ type Exchange struct {
 	*ExgInfo
 	Hosts   *ExgHosts
 	Fees    *ExgFee
-	Apis    map[string]*Entry         // 所有API的路径
-	Has     map[string]map[string]int // 是否定义了某个API
-	Options map[string]interface{}    // 用户传入的配置
+	Apis    map[string]*Entry               // 所有API的路径
+	Has     map[string]map[string]int       // 是否定义了某个API
+	Pages   map[string]map[string]*PageConf // market: api: pagination rules of history api 历史数据接口的分页规则
+	Options map[string]interface{}          // 用户传入的配置
 	Proxy   *url.URL
 	onHost  func(name string) string
 
 	CredKeys   map[string]bool     // cred keys required for exchange
-	Accounts   map[string]*Account // name: account
+	Accounts   map[string]*Account // name: account, access by GetAccount after init 初始化后通过GetAccount访问
 	DefAccName string              // default account name
 
 	EnableRateLimit     int        // 是否启用请求速率控制:BoolNull/BoolTrue/BoolFalse
@@ -82,6 +83,12 @@ type Exchange struct {
 
 	KeyTimeStamps map[string]int64 // key: int64 更新的时间戳
 
+	OdGroupMgrs map[string]*OrderGroupMgr // account: emulated OCO/bracket order groups
+	odGroupLock sync.Mutex
+	watchHubs   map[string]interface{} // key: *WatchHub, see SubMyTrades
+	hubLock     sync.Mutex
+	accLock     sync.Mutex // guard Accounts and DefAccName after init, see AddAccount
+
 	// for calling sub struct func in parent struct
 	Sign            FuncSign
 	FetchCurrencies FuncFetchCurr
@@ -96,6 +103,9 @@ type Exchange struct {
 	OnWsReCon FuncOnWsReCon
 	OnWsChan  FuncOnWsChan
 
+	wsReConCbs  map[string]func(client *WsClient) // listeners of websocket reconnection
+	wsReConLock sync.Mutex
+
 	Flags map[string]string
 }
 
@@ -135,13 +145,32 @@ type Account struct {
 	MarPositions map[string][]*Position // marketType: Position List
 	MarBalances  map[string]*Balances   // marketType: Balances
 	Leverages    map[string]int         // 币种当前的杠杆倍数
+	PosModes     map[string]bool        // marketType: hedge mode(dual position side) 是否双向持仓
+	MarginModes  map[string]string      // symbol: MarginCross/MarginIsolated 币种保证金模式
+	MultiAssets  map[string]bool        // marketType: multi-assets margin mode 是否联合保证金
+	TradingFees  map[string]*TradingFee // symbol: fee rates of account, used by CalculateFee 账户手续费率
+	Fees         *ExgFee                // fee rates of account by market type, override Exchange.Fees 账户级别手续费
+	MarginMode   string                 // default margin mode of account, override ExgInfo.MarginMode 账户默认保证金模式
 	Data         map[string]interface{}
 	LockPos      *sync.Mutex
 	LockBalance  *sync.Mutex
-	LockLeverage *sync.Mutex
+	LockLeverage *sync.Mutex // for Leverages, PosModes, MarginModes, MultiAssets, TradingFees, MarginMode
 	LockData     *sync.Mutex
 }
 
+/*
+PageConf
+pagination rules of a history api, used by PageIter
+历史数据接口的分页规则，供PageIter使用
+*/
+type PageConf struct {
+	DefLimit int   // items of a full page when limit is 0 limit为0时一页的数量
+	MaxLimit int   // max items per request, 0 for no limit 单次请求最大数量
+	MaxRange int64 // max time range per request in ms 单次请求最大时间范围(毫秒)
+	Interval int64 // min interval between requests in ms 请求最小间隔(毫秒)
+	Backward bool  // return the latest items first in range 范围内优先返回最新数据
+}
+
 type ExgHosts struct {
 	TestNet bool
 	Logo    string
@@ -453,6 +482,65 @@ type Order struct {
 	Fee                 *Fee        `json:"fee"`
 }
 
+/*
+OrderReq
+one item of batch orders. ID is required for EditOrders/CancelOrders; Params override the batch params
+批量订单的单项。EditOrders/CancelOrders时ID必填；Params覆盖批量参数
+*/
+type OrderReq struct {
+	Symbol string
+	ID     string
+	Type   string
+	Side   string
+	Amount float64
+	Price  float64
+	Params map[string]interface{}
+}
+
+// OrderRes result of one item of batch orders, in the same order as requests 批量订单单项的结果，与请求顺序一致
+type OrderRes struct {
+	Order *Order
+	Err   *errs.Error
+}
+
+/*
+BracketReq
+entry order with stop loss and take profit exit legs, exit legs are linked as OCO after entry filled.
+Entry order is skipped when Type is empty, then exit legs are placed immediately.
+入场订单及止损止盈出场订单，入场成交后出场订单以OCO关联。Type为空时跳过入场订单，立即下出场订单。
+*/
+type BracketReq struct {
+	Symbol     string
+	Type       string // entry order type 入场订单类型
+	Side       string // entry order side 入场方向
+	Amount     float64
+	Price      float64 // entry price 入场价格
+	StopLoss   float64 // trigger price of stop loss leg 止损触发价
+	TakeProfit float64 // limit price of take profit leg 止盈限价
+	Params     map[string]interface{}
+}
+
+/*
+OrderGroup
+linked orders of OCO/bracket, when one leg fills the siblings are canceled
+OCO/括号订单的关联订单组，一个出场订单成交时撤销其他订单
+*/
+type OrderGroup struct {
+	ID     string
+	Symbol string
+	Native bool     // linked by exchange, else emulated by OrderGroupMgr 交易所原生关联，否则由OrderGroupMgr模拟
+	Status string   // OdStatusOpen/OdStatusFilled/OdStatusCanceled
+	Entry  *Order   // entry order of bracket, nil for OCO 括号订单的入场订单
+	Legs   []*Order // exit legs: take profit, stop loss 出场订单：止盈、止损
+	req    *BracketReq
+	args   map[string]interface{}
+	placed bool // exit legs of bracket are placing or placed
+	// client order ids of emulated legs, registered before sending 模拟出场订单的客户端ID，下单前注册
+	clientIds []string
+	entryCli  string // client order id of bracket entry, registered before sending 入场订单的客户端ID，下单前注册
+	hitId     string // order id or client id of filled leg 成交的出场订单ID或客户端ID
+}
+
 type Trade struct {
 	ID        string      `json:"id"`        // 交易ID
 	Symbol    string      `json:"symbol"`    // 币种ID
@@ -520,6 +608,255 @@ type Income struct {
 	TradeID    string  `json:"tradeId"`
 }
 
+/*
+MarginChange
+isolated margin added to or reduced from a position
+逐仓仓位的保证金增加或减少记录
+*/
+type MarginChange struct {
+	Symbol    string      `json:"symbol"`
+	Type      string      `json:"type"`      // MarginAdd/MarginReduce
+	Amount    float64     `json:"amount"`    // 变动的保证金数量
+	Code      string      `json:"code"`      // currency of margin 保证金币种
+	PosSide   string      `json:"posSide"`   // long/short/both
+	DeltaType string      `json:"deltaType"` // reason of change from exchange 变动原因
+	Timestamp int64       `json:"timestamp"`
+	Info      interface{} `json:"info"`
+}
+
+/*
+MarginLoan
+result of margin Borrow/Repay
+杠杆借币/还币的结果
+*/
+type MarginLoan struct {
+	ID        string      `json:"id"`
+	Code      string      `json:"code"`
+	Amount    float64     `json:"amount"`
+	Symbol    string      `json:"symbol"` // isolated symbol, empty for cross margin 逐仓交易对，全仓为空
+	Timestamp int64       `json:"timestamp"`
+	Info      interface{} `json:"info"`
+}
+
+/*
+BorrowInterest
+interest charged for margin borrowing
+杠杆借币的利息记录
+*/
+type BorrowInterest struct {
+	Symbol    string      `json:"symbol"` // isolated symbol, empty for cross margin 逐仓交易对，全仓为空
+	Code      string      `json:"code"`
+	Interest  float64     `json:"interest"`
+	Rate      float64     `json:"rate"`   // interest rate of the period 此次计息利率
+	Amount    float64     `json:"amount"` // borrowed principal 借币本金
+	Type      string      `json:"type"`
+	Timestamp int64       `json:"timestamp"`
+	Info      interface{} `json:"info"`
+}
+
+/*
+BorrowRate
+borrow interest rate of currency
+币种的借币利率
+*/
+type BorrowRate struct {
+	Code      string      `json:"code"`
+	Rate      float64     `json:"rate"`
+	Period    int64       `json:"period"` // milliseconds of rate period 利率周期毫秒数
+	Timestamp int64       `json:"timestamp"`
+	Info      interface{} `json:"info"`
+}
+
+/*
+Transfer
+internal transfer of asset between wallets of market types
+不同市场钱包之间的内部资产划转
+*/
+type Transfer struct {
+	ID        string      `json:"id"`
+	Code      string      `json:"code"`
+	Amount    float64     `json:"amount"`
+	From      string      `json:"from"` // market type of source wallet 转出钱包的市场类型
+	To        string      `json:"to"`   // market type of target wallet 转入钱包的市场类型
+	Status    string      `json:"status"`
+	Timestamp int64       `json:"timestamp"`
+	Info      interface{} `json:"info"`
+}
+
+/*
+DepositAddress
+deposit address of currency on network
+币种在某个网络上的充值地址
+*/
+type DepositAddress struct {
+	Code    string      `json:"code"`
+	Network string      `json:"network"`
+	Address string      `json:"address"`
+	Tag     string      `json:"tag"` // memo or tag required by some chains 部分链需要的memo/tag
+	Info    interface{} `json:"info"`
+}
+
+/*
+Transaction
+deposit or withdrawal record
+充值或提现记录
+*/
+type Transaction struct {
+	ID        string      `json:"id"`
+	TxID      string      `json:"txid"` // hash on chain 链上哈希
+	Type      string      `json:"type"` // TxTypeDeposit/TxTypeWithdrawal
+	Code      string      `json:"code"`
+	Amount    float64     `json:"amount"`
+	Network   string      `json:"network"`
+	Address   string      `json:"address"`
+	Tag       string      `json:"tag"`
+	Status    string      `json:"status"` // TxStatusPending/TxStatusOk/TxStatusFailed/TxStatusCanceled
+	Fee       float64     `json:"fee"`
+	Timestamp int64       `json:"timestamp"`
+	Info      interface{} `json:"info"`
+}
+
+/*
+SubAccount
+sub-account under master account, ID is email for normal sub-account and subaccountId for broker
+主账户下的子账户，普通子账户ID为邮箱，经纪商子账户为subaccountId
+*/
+type SubAccount struct {
+	ID        string      `json:"id"`
+	Email     string      `json:"email"`
+	Frozen    bool        `json:"frozen"`
+	Managed   bool        `json:"managed"`
+	Timestamp int64       `json:"timestamp"`
+	Info      interface{} `json:"info"`
+}
+
+/*
+SubAccApiKey
+api key of sub-account, Secret is only returned on creation
+子账户的api key，Secret仅在创建时返回
+*/
+type SubAccApiKey struct {
+	SubID  string      `json:"subId"`
+	ApiKey string      `json:"apiKey"`
+	Secret string      `json:"secret"`
+	Perms  []string    `json:"perms"` // market types allowed to trade 允许交易的市场类型
+	Info   interface{} `json:"info"`
+}
+
+/*
+TradingFee
+maker/taker fee rates of symbol for account
+账户在某个品种上的挂单/吃单费率
+*/
+type TradingFee struct {
+	Symbol string      `json:"symbol"`
+	Maker  float64     `json:"maker"`
+	Taker  float64     `json:"taker"`
+	Info   interface{} `json:"info"`
+}
+
+/*
+ConvertQuote
+price quote to convert currency, should be accepted before Expire
+闪兑报价，需在Expire前确认
+*/
+type ConvertQuote struct {
+	ID         string      `json:"id"`
+	From       string      `json:"from"`
+	To         string      `json:"to"`
+	FromAmount float64     `json:"fromAmount"`
+	ToAmount   float64     `json:"toAmount"`
+	Price      float64     `json:"price"`  // amount of To for one From 1个From可兑换的To数量
+	Expire     int64       `json:"expire"` // timestamp in ms when quote expires 报价过期时间戳
+	Info       interface{} `json:"info"`
+}
+
+/*
+ConvertTrade
+result of convert or dust conversion
+闪兑或小额资产兑换的结果
+*/
+type ConvertTrade struct {
+	ID         string      `json:"id"`
+	QuoteID    string      `json:"quoteId"`
+	From       string      `json:"from"`
+	To         string      `json:"to"`
+	FromAmount float64     `json:"fromAmount"`
+	ToAmount   float64     `json:"toAmount"`
+	Price      float64     `json:"price"`
+	Fee        float64     `json:"fee"` // in To currency 以To币种计价
+	Status     string      `json:"status"`
+	Timestamp  int64       `json:"timestamp"`
+	Info       interface{} `json:"info"`
+}
+
+/*
+LedgerEntry
+one cash flow of account, Amount is positive for income and negative for expense
+账户的一条资金流水，收入为正，支出为负
+*/
+type LedgerEntry struct {
+	ID        string      `json:"id"`
+	RefID     string      `json:"refId"` // id of trade/order/transfer/transaction 关联的成交/订单/划转/充提ID
+	Type      string      `json:"type"`  // LedgerTrade/LedgerPnl/LedgerFee/LedgerFunding...
+	Code      string      `json:"code"`
+	Symbol    string      `json:"symbol"`
+	Amount    float64     `json:"amount"`
+	Balance   float64     `json:"balance"` // balance after this entry, 0 if not provided by exchange 变动后余额
+	Timestamp int64       `json:"timestamp"`
+	Info      interface{} `json:"info"`
+}
+
+/*
+Liquidation
+forced close order by liquidation or ADL, ID and Type are empty for public stream
+强平或自动减仓的订单，公共推送时ID和Type为空
+*/
+type Liquidation struct {
+	ID        string      `json:"id"`
+	Symbol    string      `json:"symbol"`
+	Type      string      `json:"type"` // LiqTypeLiquidation/LiqTypeADL
+	Side      string      `json:"side"`
+	Price     float64     `json:"price"`
+	Average   float64     `json:"average"`
+	Amount    float64     `json:"amount"`
+	Filled    float64     `json:"filled"`
+	Status    string      `json:"status"`
+	Timestamp int64       `json:"timestamp"`
+	Info      interface{} `json:"info"`
+}
+
+/*
+ADLQuantile
+ADL queue position of symbol, from 0 to 4, higher is more likely to be auto-deleveraged
+自动减仓队列位置，0-4，越高越优先被减仓
+*/
+type ADLQuantile struct {
+	Symbol string      `json:"symbol"`
+	Long   int         `json:"long"`
+	Short  int         `json:"short"`
+	Both   int         `json:"both"` // one-way position mode 单向持仓模式
+	Info   interface{} `json:"info"`
+}
+
+/*
+MarginCall
+position which is close to liquidation
+接近强平的仓位
+*/
+type MarginCall struct {
+	Symbol        string      `json:"symbol"`
+	PosSide       string      `json:"posSide"`
+	MarginMode    string      `json:"marginMode"`
+	Amount        float64     `json:"amount"`
+	MarkPrice     float64     `json:"markPrice"`
+	UnrealizedPnl float64     `json:"unrealizedPnl"`
+	MaintMargin   float64     `json:"maintMargin"`
+	CrossWallet   float64     `json:"crossWallet"` // wallet balance of cross margin 全仓钱包余额
+	Timestamp     int64       `json:"timestamp"`
+	Info          interface{} `json:"info"`
+}
+
 type FundingRate struct {
 	Symbol      string      `json:"symbol"`
 	FundingRate float64     `json:"fundingRate"`
@@ -582,9 +919,16 @@ type WsMsg struct {
 	List    []map[string]string
 }
 
+/*
+AccountConfig
+account config update, Symbol is empty when MultiAssets changed
+账户配置更新，联合保证金模式变化时Symbol为空
+*/
 type AccountConfig struct {
-	Symbol   string
-	Leverage int
+	Symbol      string
+	Leverage    int
+	MarketType  string
+	MultiAssets bool
 }
 
 type WsLog struct {
@@ -260,6 +260,29 @@ func ByteToStruct[T any](byteChan <-chan []byte, outChan chan<- T, numType int)
 	}
 }
 
+/*
+WriteChan
+send msg to out without blocking. When out is full, drop the oldest msg if popIfNeed, or return false
+非阻塞发送msg到out。通道已满时，popIfNeed为true则丢弃最旧的消息，否则返回false
+*/
+func WriteChan[T any](out chan T, msg T, popIfNeed bool) bool {
+	for {
+		select {
+		case out <- msg:
+			return true
+		default:
+			if !popIfNeed {
+				return false
+			}
+			// 通道已满，弹出最早的消息后重试；其他协程可能同时读取，故不阻塞
+			select {
+			case <-out:
+			default:
+			}
+		}
+	}
+}
+
 const (
 	JsonNumDefault = 0 // equal to JsonNumFloat
 	JsonNumFloat   = 0 // parse number in json to float64
@@ -0,0 +1,214 @@
+package banexg
+
+import (
+	"fmt"
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/utils"
+	"math"
+	"strings"
+)
+
+const (
+	RulePrecision = "precision" // 不符合精度
+	RuleMin       = "min"       // 低于最小值
+	RuleMax       = "max"       // 超过最大值
+	RuleLeverage  = "leverage"  // 超过杠杆档位的最大杠杆
+	RuleBalance   = "balance"   // 余额不足
+)
+
+/*
+OrderViolation
+a market rule that the order breaks, Field is amount/price/cost/leverage/balance
+订单违反的一条市场规则
+*/
+type OrderViolation struct {
+	Field string
+	Rule  string
+	Value float64
+	Limit float64
+}
+
+func (v *OrderViolation) String() string {
+	return fmt.Sprintf("%s %v breaks %s %v", v.Field, v.Value, v.Rule, v.Limit)
+}
+
+/*
+OrderCheck
+result of ValidateOrder. Amount/Price are rounded when ParamAutoRound is set
+ValidateOrder的结果，设置ParamAutoRound时Amount/Price为取整后的值
+*/
+type OrderCheck struct {
+	Symbol     string
+	Amount     float64
+	Price      float64
+	Violations []*OrderViolation
+}
+
+func (c *OrderCheck) OK() bool {
+	return len(c.Violations) == 0
+}
+
+// Error return nil if no violations 无违规时返回nil
+func (c *OrderCheck) Error() *errs.Error {
+	if len(c.Violations) == 0 {
+		return nil
+	}
+	texts := make([]string, 0, len(c.Violations))
+	for _, v := range c.Violations {
+		texts = append(texts, v.String())
+	}
+	return errs.NewMsg(errs.CodeParamInvalid, "invalid order for %s: %s", c.Symbol, strings.Join(texts, "; "))
+}
+
+func (c *OrderCheck) add(field, rule string, value, limit float64) {
+	c.Violations = append(c.Violations, &OrderViolation{Field: field, Rule: rule, Value: value, Limit: limit})
+}
+
+func (c *OrderCheck) checkRange(field string, value float64, rg *LimitRange) {
+	if rg == nil || value == 0 {
+		return
+	}
+	if rg.Min > 0 && value < rg.Min {
+		c.add(field, RuleMin, value, rg.Min)
+	} else if rg.Max > 0 && value > rg.Max {
+		c.add(field, RuleMax, value, rg.Max)
+	}
+}
+
+func sameFloat(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*max(math.Abs(a), math.Abs(b), 1e-12)
+}
+
+/*
+CheckOrder
+validate order against Market.Precision, Market.Limits, leverage brackets and balance.
+Balance is fetched only when ParamCheckBalance is true, and skipped when FetchBalance is not implemented.
+
+	:param bool [params.autoRound]: round amount (truncate) and price to precision instead of reporting violations
+	:param float [params.leverage]: leverage to be used, checked against max leverage of bracket
+	:param bool [params.checkBalance]: check free balance is enough
+
+检查订单是否符合市场精度、限制、杠杆档位和余额。仅当ParamCheckBalance为true时获取余额，FetchBalance未实现时跳过。
+*/
+func CheckOrder(exg BanExchange, symbol, odType, side string, amount, price float64, params map[string]interface{}) (*OrderCheck, *errs.Error) {
+	market, err := exg.GetMarket(symbol)
+	if err != nil {
+		return nil, err
+	}
+	args := utils.SafeParams(params)
+	autoRound := utils.PopMapVal(args, ParamAutoRound, false)
+	leverage := utils.PopMapVal(args, ParamLeverage, float64(0))
+	checkBalance := utils.PopMapVal(args, ParamCheckBalance, false)
+	res := &OrderCheck{Symbol: symbol, Amount: amount, Price: price}
+	if amount <= 0 {
+		res.add("amount", RuleMin, amount, 0)
+		return res, nil
+	}
+	isMarket := odType == OdTypeMarket
+	if market.Precision != nil {
+		precAmt, err := exg.PrecAmount(market, amount)
+		if err != nil {
+			return nil, err
+		}
+		if autoRound {
+			res.Amount = precAmt
+			if res.Amount <= 0 {
+				// 小于步长时向下取整为0
+				res.add("amount", RuleMin, amount, 0)
+			}
+		} else if !sameFloat(precAmt, amount) {
+			res.add("amount", RulePrecision, amount, precAmt)
+		}
+		if price > 0 {
+			precPrice, err := exg.PrecPrice(market, price)
+			if err != nil {
+				return nil, err
+			}
+			if autoRound {
+				res.Price = precPrice
+			} else if !isMarket && !sameFloat(precPrice, price) {
+				res.add("price", RulePrecision, price, precPrice)
+			}
+		}
+	}
+	amount, price = res.Amount, res.Price
+	notional := market.Notional(amount, price)
+	if limits := market.Limits; limits != nil {
+		if isMarket && limits.Market != nil {
+			res.checkRange("amount", amount, limits.Market)
+		} else {
+			res.checkRange("amount", amount, limits.Amount)
+		}
+		if !isMarket {
+			res.checkRange("price", price, limits.Price)
+		}
+		res.checkRange("cost", notional, limits.Cost)
+	}
+	if market.Contract {
+		accName := exg.GetExg().GetAccName(params)
+		curLev, maxLev := exg.GetLeverage(symbol, notional, accName)
+		if leverage == 0 {
+			leverage = curLev
+			if maxLev > 0 && curLev > maxLev {
+				res.add("leverage", RuleLeverage, curLev, maxLev)
+			}
+		} else if maxLev > 0 && leverage > maxLev {
+			res.add("leverage", RuleLeverage, leverage, maxLev)
+		}
+	}
+	if checkBalance {
+		err = checkOrderBalance(exg, market, res, side, notional, leverage, args)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+/*
+checkOrderBalance
+spot buy requires quote, spot sell requires base, contract requires margin of settle currency.
+Market orders without price and reduce only orders are skipped.
+现货买入需要报价币，卖出需要基础币，合约需要结算币保证金。无价格的市价单和只减仓订单跳过。
+*/
+func checkOrderBalance(exg BanExchange, market *Market, res *OrderCheck, side string, notional, leverage float64, params map[string]interface{}) *errs.Error {
+	if utils.GetMapVal(params, ParamReduceOnly, false) {
+		return nil
+	}
+	var code string
+	var need float64
+	if market.Contract {
+		code, need = market.Settle, notional/max(leverage, 1)
+		if market.Inverse {
+			// margin of inverse contract is in coin
+			if res.Price <= 0 {
+				return nil
+			}
+			need /= res.Price
+		}
+	} else if market.Spot && utils.GetMapVal(params, ParamMarginMode, "") == "" {
+		if side == OdSideBuy {
+			code, need = market.Quote, notional
+		} else {
+			code, need = market.Base, res.Amount
+		}
+	}
+	if code == "" || need <= 0 {
+		return nil
+	}
+	bal, err := exg.FetchBalance(params)
+	if err != nil {
+		if err.Code == errs.CodeNotImplement {
+			return nil
+		}
+		return err
+	}
+	free := float64(0)
+	if asset, ok := bal.Assets[code]; ok {
+		free = asset.Free
+	}
+	if free < need {
+		res.add("balance", RuleBalance, need, free)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package banexg
+
+import (
+	"github.com/banbox/banexg/errs"
+	"testing"
+)
+
+type validateExg struct {
+	*Exchange
+	market *Market
+	free   float64
+}
+
+func (e *validateExg) GetMarket(symbol string) (*Market, *errs.Error) {
+	return e.market, nil
+}
+
+func (e *validateExg) GetLeverage(symbol string, notional float64, account string) (float64, float64) {
+	if notional > 100000 {
+		return 10, 5
+	}
+	if notional > 10000 {
+		return 10, 20
+	}
+	return 10, 50
+}
+
+func (e *validateExg) FetchBalance(params map[string]interface{}) (*Balances, *errs.Error) {
+	return &Balances{Assets: map[string]*Asset{"USDT": {Code: "USDT", Free: e.free}}}, nil
+}
+
+func TestCheckOrder(t *testing.T) {
+	exg := &validateExg{Exchange: &Exchange{}, free: 100, market: &Market{
+		Symbol: "BTC/USDT:USDT", Settle: "USDT", Contract: true, Linear: true, ContractSize: 1,
+		Precision: &Precision{Amount: 0.001, Price: 0.1, ModeAmount: PrecModeTickSize, ModePrice: PrecModeTickSize},
+		Limits: &MarketLimits{
+			Amount: &LimitRange{Min: 0.001, Max: 100},
+			Market: &LimitRange{Min: 0.001, Max: 10},
+			Price:  &LimitRange{Min: 100, Max: 1000000},
+			Cost:   &LimitRange{Min: 5},
+		},
+	}}
+	symbol := exg.market.Symbol
+	res, err := CheckOrder(exg, symbol, OdTypeLimit, OdSideBuy, 0.0105, 30000.05, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Violations) != 2 || res.Violations[0].Field != "amount" || res.Violations[1].Field != "price" {
+		t.Fatalf("expect precision violations, got %v", res.Error())
+	}
+	res, _ = CheckOrder(exg, symbol, OdTypeLimit, OdSideBuy, 0.0105, 30000.05, map[string]interface{}{
+		ParamAutoRound: true})
+	if !res.OK() || res.Amount != 0.01 || res.Price != 30000.1 {
+		t.Fatalf("expect auto rounded, got %+v %v", res, res.Error())
+	}
+	res, err = CheckOrder(exg, symbol, OdTypeLimit, OdSideBuy, 0.0004, 30000, map[string]interface{}{
+		ParamAutoRound: true})
+	if err != nil || res.OK() || res.Violations[0].Field != "amount" || res.Violations[0].Rule != RuleMin {
+		t.Fatalf("expect amount rounded to zero fail, got %+v %v", res, err)
+	}
+	res, _ = CheckOrder(exg, symbol, OdTypeMarket, OdSideBuy, 20, 0, nil)
+	if res.OK() || res.Violations[0].Rule != RuleMax {
+		t.Errorf("expect market amount max violation, got %v", res.Error())
+	}
+	res, _ = CheckOrder(exg, symbol, OdTypeLimit, OdSideBuy, 0.001, 3000, nil)
+	if res.OK() || res.Violations[0].Field != "cost" {
+		t.Errorf("expect min cost violation, got %v", res.Error())
+	}
+	res, _ = CheckOrder(exg, symbol, OdTypeLimit, OdSideBuy, 1, 30000, map[string]interface{}{
+		ParamLeverage: float64(25), ParamCheckBalance: true})
+	if len(res.Violations) != 2 || res.Violations[0].Rule != RuleLeverage || res.Violations[1].Rule != RuleBalance {
+		t.Errorf("expect leverage and balance violations, got %v", res.Error())
+	}
+	if res.Violations[1].Value != 1200 {
+		t.Errorf("required margin should be 1200, got %v", res.Violations[1].Value)
+	}
+	// current leverage exceeds max leverage of the bracket
+	res, _ = CheckOrder(exg, symbol, OdTypeLimit, OdSideBuy, 5, 30000, nil)
+	if len(res.Violations) != 1 || res.Violations[0].Rule != RuleLeverage || res.Violations[0].Value != 10 {
+		t.Errorf("expect current leverage violation, got %v", res.Error())
+	}
+}
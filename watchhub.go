@@ -0,0 +1,307 @@
+package banexg
+
+import (
+	"github.com/banbox/banexg/errs"
+	"github.com/banbox/banexg/log"
+	"github.com/banbox/banexg/utils"
+	"go.uber.org/zap"
+	"sync"
+)
+
+/*
+WatchHub
+fan out a shared watch channel of exchange to multiple subscribers. The source channel is read by one goroutine,
+every subscriber gets its own channel. Sends never block the source: for market data, messages are dropped with
+an error log when a subscriber is full; for Lossless hubs (my trades), each subscriber has an unbounded queue so
+no message is lost. Symbols are ref-counted, and are unwatched only when no subscriber uses them.
+将交易所共享的watch通道分发给多个订阅者。源通道由一个协程读取，每个订阅者有独立的通道。
+发送不会阻塞源通道：行情数据在订阅者通道已满时丢弃并记录错误；Lossless的hub(账户成交)为每个订阅者使用无界队列，
+不丢失消息。品种按引用计数，没有订阅者使用时才取消订阅。
+*/
+type WatchHub[T any] struct {
+	Key      string
+	Lossless bool
+	subs     map[chan T]*hubSub[T]
+	refs     map[string]int // symbol: subscriber num
+	running  bool
+	lock     sync.Mutex
+}
+
+// hubSub 订阅者；Lossless时消息先写入队列，由pump协程发送到out
+type hubSub[T any] struct {
+	out    chan T
+	queue  []T
+	done   bool // 源通道已关闭，发送完队列后关闭out
+	notify chan struct{}
+	stop   chan struct{}
+	lock   sync.Mutex
+}
+
+func getWatchHub[T any](e *Exchange, key string, lossless bool) *WatchHub[T] {
+	e.hubLock.Lock()
+	defer e.hubLock.Unlock()
+	if e.watchHubs == nil {
+		e.watchHubs = make(map[string]interface{})
+	}
+	if raw, ok := e.watchHubs[key]; ok {
+		if hub, ok := raw.(*WatchHub[T]); ok {
+			return hub
+		}
+	}
+	hub := &WatchHub[T]{
+		Key:      key,
+		Lossless: lossless,
+		subs:     make(map[chan T]*hubSub[T]),
+		refs:     make(map[string]int),
+	}
+	e.watchHubs[key] = hub
+	return hub
+}
+
+/*
+Sub
+add a subscriber with channel capacity chanCap, watch is called for symbols not watched yet, or to start the hub
+添加一个通道容量为chanCap的订阅者，对尚未订阅的品种或启动时调用watch
+*/
+func (h *WatchHub[T]) Sub(symbols []string, chanCap int, watch func(symbols []string) (chan T, *errs.Error)) (chan T, *errs.Error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	var newSymbols []string
+	for _, s := range symbols {
+		if h.refs[s] == 0 {
+			newSymbols = append(newSymbols, s)
+		}
+	}
+	if !h.running || len(newSymbols) > 0 {
+		src, err := watch(newSymbols)
+		if err != nil {
+			return nil, err
+		}
+		if !h.running {
+			h.running = true
+			go h.run(src)
+		}
+	}
+	for _, s := range symbols {
+		h.refs[s] += 1
+	}
+	out := make(chan T, chanCap)
+	sub := &hubSub[T]{out: out}
+	if h.Lossless {
+		sub.notify = make(chan struct{}, 1)
+		sub.stop = make(chan struct{})
+		go sub.pump()
+	}
+	h.subs[out] = sub
+	return out, nil
+}
+
+/*
+UnSub
+remove subscriber and close its channel, unwatch is called for symbols no longer used
+移除订阅者并关闭其通道，对不再使用的品种调用unwatch
+*/
+func (h *WatchHub[T]) UnSub(out chan T, symbols []string, unwatch func(symbols []string) *errs.Error) *errs.Error {
+	h.lock.Lock()
+	sub, ok := h.subs[out]
+	if !ok {
+		h.lock.Unlock()
+		return nil
+	}
+	delete(h.subs, out)
+	sub.close(true)
+	var unused []string
+	for _, s := range symbols {
+		if h.refs[s] <= 1 {
+			delete(h.refs, s)
+			unused = append(unused, s)
+		} else {
+			h.refs[s] -= 1
+		}
+	}
+	h.lock.Unlock()
+	if len(unused) > 0 && unwatch != nil {
+		return unwatch(unused)
+	}
+	return nil
+}
+
+func (h *WatchHub[T]) run(src chan T) {
+	for msg := range src {
+		h.lock.Lock()
+		for _, sub := range h.subs {
+			sub.send(msg, h.Key)
+		}
+		h.lock.Unlock()
+	}
+	// 源通道关闭，关闭所有订阅者
+	h.lock.Lock()
+	h.running = false
+	for _, sub := range h.subs {
+		sub.close(false)
+	}
+	clear(h.subs)
+	clear(h.refs)
+	h.lock.Unlock()
+}
+
+func (s *hubSub[T]) send(msg T, key string) {
+	if s.notify == nil {
+		select {
+		case s.out <- msg:
+		default:
+			log.Error("watch hub subscriber full, drop msg", zap.String("key", key))
+		}
+		return
+	}
+	s.lock.Lock()
+	s.queue = append(s.queue, msg)
+	s.lock.Unlock()
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// close 关闭订阅者；unSub为true时丢弃未发送的队列，否则发送完队列后关闭
+func (s *hubSub[T]) close(unSub bool) {
+	if s.notify == nil {
+		close(s.out)
+		return
+	}
+	if unSub {
+		close(s.stop)
+		return
+	}
+	s.lock.Lock()
+	s.done = true
+	s.lock.Unlock()
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pump 将队列中的消息按顺序发送到out，订阅者阻塞时不影响源通道
+func (s *hubSub[T]) pump() {
+	defer close(s.out)
+	var zero T
+	for {
+		s.lock.Lock()
+		if len(s.queue) == 0 {
+			done := s.done
+			s.lock.Unlock()
+			if done {
+				return
+			}
+			select {
+			case <-s.notify:
+			case <-s.stop:
+				return
+			}
+			continue
+		}
+		msg := s.queue[0]
+		s.queue[0] = zero
+		s.queue = s.queue[1:]
+		s.lock.Unlock()
+		select {
+		case s.out <- msg:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+/*
+SubMyTrades
+subscribe trades of account in params through a WatchHub, so that multiple consumers of WatchMyTrades
+(OrderGroupMgr, order manager, strategies) each receive all trades. Trades are never dropped, a slow subscriber
+only queues up in memory. Close with UnSubMyTrades.
+通过WatchHub订阅params中账户的成交，使WatchMyTrades的多个消费者(订单组、订单管理器、策略)都能收到全部成交。
+成交不会被丢弃，慢的订阅者仅在内存中排队。使用UnSubMyTrades取消。
+*/
+func SubMyTrades(exg BanExchange, params map[string]interface{}) (chan *MyTrade, *errs.Error) {
+	args := utils.SafeParams(params)
+	chanCap := utils.PopMapVal(args, ParamChanCap, 1000)
+	hub := getWatchHub[*MyTrade](exg.GetExg(), myTradesHubKey(exg, args), true)
+	return hub.Sub(nil, chanCap, func(_ []string) (chan *MyTrade, *errs.Error) {
+		return exg.WatchMyTrades(args)
+	})
+}
+
+// UnSubMyTrades remove subscriber of SubMyTrades and close out 取消SubMyTrades的订阅并关闭out
+func UnSubMyTrades(exg BanExchange, params map[string]interface{}, out chan *MyTrade) {
+	hub := getWatchHub[*MyTrade](exg.GetExg(), myTradesHubKey(exg, params), true)
+	_ = hub.UnSub(out, nil, nil)
+}
+
+func myTradesHubKey(exg BanExchange, params map[string]interface{}) string {
+	e := exg.GetExg()
+	marketType := utils.GetMapVal(params, ParamMarket, "")
+	if marketType == "" && e.ExgInfo != nil {
+		marketType = e.MarketType
+	}
+	return ApiWatchMyTrades + "@" + e.GetAccName(params) + "@" + marketType
+}
+
+/*
+SubTrades
+subscribe public trades of symbols through a WatchHub, the channel of WatchTrades is shared by all symbols of
+the market type, so trades of other symbols are also received. Close with UnSubTrades.
+通过WatchHub订阅品种的公开成交。WatchTrades的通道由同市场类型的所有品种共享，故也会收到其他品种的成交。
+使用UnSubTrades取消。
+*/
+func SubTrades(exg BanExchange, symbols []string, params map[string]interface{}) (chan *Trade, *errs.Error) {
+	args := utils.SafeParams(params)
+	chanCap := utils.PopMapVal(args, ParamChanCap, 100)
+	hub := getWatchHub[*Trade](exg.GetExg(), marketHubKey(exg, ApiWatchTrades, symbols, args), false)
+	return hub.Sub(symbols, chanCap, func(symbols []string) (chan *Trade, *errs.Error) {
+		return exg.WatchTrades(symbols, utils.SafeParams(args))
+	})
+}
+
+// UnSubTrades remove subscriber of SubTrades, unwatch symbols not used 取消SubTrades的订阅，取消不再使用的品种
+func UnSubTrades(exg BanExchange, symbols []string, params map[string]interface{}, out chan *Trade) *errs.Error {
+	hub := getWatchHub[*Trade](exg.GetExg(), marketHubKey(exg, ApiWatchTrades, symbols, params), false)
+	return hub.UnSub(out, symbols, func(symbols []string) *errs.Error {
+		return exg.UnWatchTrades(symbols, utils.SafeParams(params))
+	})
+}
+
+/*
+SubMarkPrices
+subscribe mark prices of symbols through a WatchHub. Close with UnSubMarkPrices.
+通过WatchHub订阅品种的标记价格。使用UnSubMarkPrices取消。
+*/
+func SubMarkPrices(exg BanExchange, symbols []string, params map[string]interface{}) (chan map[string]float64, *errs.Error) {
+	args := utils.SafeParams(params)
+	chanCap := utils.PopMapVal(args, ParamChanCap, 100)
+	hub := getWatchHub[map[string]float64](exg.GetExg(), marketHubKey(exg, ApiWatchMarkPrices, symbols, args), false)
+	return hub.Sub(symbols, chanCap, func(symbols []string) (chan map[string]float64, *errs.Error) {
+		return exg.WatchMarkPrices(symbols, utils.SafeParams(args))
+	})
+}
+
+// UnSubMarkPrices remove subscriber of SubMarkPrices, unwatch symbols not used 取消SubMarkPrices的订阅，取消不再使用的品种
+func UnSubMarkPrices(exg BanExchange, symbols []string, params map[string]interface{}, out chan map[string]float64) *errs.Error {
+	hub := getWatchHub[map[string]float64](exg.GetExg(), marketHubKey(exg, ApiWatchMarkPrices, symbols, params), false)
+	return hub.UnSub(out, symbols, func(symbols []string) *errs.Error {
+		return exg.UnWatchMarkPrices(symbols, utils.SafeParams(params))
+	})
+}
+
+// marketHubKey market type is from params, market of first symbol, or default market type 市场类型取自参数、首个品种或默认值
+func marketHubKey(exg BanExchange, api string, symbols []string, params map[string]interface{}) string {
+	e := exg.GetExg()
+	marketType := utils.GetMapVal(params, ParamMarket, "")
+	if marketType == "" && len(symbols) > 0 {
+		if market, err := exg.GetMarket(symbols[0]); err == nil {
+			marketType = market.Type
+		}
+	}
+	if marketType == "" && e.ExgInfo != nil {
+		marketType = e.MarketType
+	}
+	return api + "@" + marketType
+}
@@ -0,0 +1,60 @@
+package banexg
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSubMyTrades(t *testing.T) {
+	exg := &groupExg{Exchange: &Exchange{}, orders: map[string]*Order{}, trades: make(chan *MyTrade, 10)}
+	out1, err := SubMyTrades(exg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out2, err := SubMyTrades(exg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exg.fill("1", OdStatusFilled)
+	for _, out := range []chan *MyTrade{out1, out2} {
+		if trade := <-out; trade.Order != "1" {
+			t.Errorf("bad trade: %+v", trade)
+		}
+	}
+	UnSubMyTrades(exg, nil, out1)
+	if _, ok := <-out1; ok {
+		t.Errorf("out1 should be closed")
+	}
+	exg.fill("2", OdStatusFilled)
+	if trade := <-out2; trade.Order != "2" {
+		t.Errorf("bad trade: %+v", trade)
+	}
+	close(exg.trades)
+	if _, ok := <-out2; ok {
+		t.Errorf("out2 should be closed after source closed")
+	}
+}
+
+func TestSubMyTradesNoDrop(t *testing.T) {
+	exg := &groupExg{Exchange: &Exchange{}, orders: map[string]*Order{}, trades: make(chan *MyTrade, 10)}
+	out, err := SubMyTrades(exg, map[string]interface{}{ParamChanCap: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 订阅者未读取时，成交应排队而非丢弃
+	num := 50
+	for i := 0; i < num; i++ {
+		exg.fill(strconv.Itoa(i), OdStatusFilled)
+	}
+	close(exg.trades)
+	i := 0
+	for trade := range out {
+		if trade.Order != strconv.Itoa(i) {
+			t.Fatalf("bad trade order at %d: %v", i, trade.Order)
+		}
+		i += 1
+	}
+	if i != num {
+		t.Errorf("expect %d trades, got %d", num, i)
+	}
+}
@@ -45,6 +45,7 @@ type WsClient struct {
 	connSubs      map[int]int
 	connLock      sync.Mutex
 	LimitsLock    sync.Mutex // for OdBookLimits
+	jobLock       sync.Mutex // for JobInfos
 }
 
 type AsyncConn struct {
@@ -253,7 +254,23 @@ func (e *Exchange) GetClient(wsUrl string, marketType, accName string) (*WsClien
 		num := e.handleWsClientClosed(client)
 		log.Info("closed out chan for ws client", zap.Int("num", num))
 	}
-	client, err := newWsClient(wsUrl, e.OnWsMsg, e.OnWsErr, onClosed, e.OnWsReCon, params, e.DebugWS)
+	onReCon := func(client *WsClient, connID int) *errs.Error {
+		var err *errs.Error
+		if e.OnWsReCon != nil {
+			err = e.OnWsReCon(client, connID)
+		}
+		e.wsReConLock.Lock()
+		cbs := make([]func(client *WsClient), 0, len(e.wsReConCbs))
+		for _, cb := range e.wsReConCbs {
+			cbs = append(cbs, cb)
+		}
+		e.wsReConLock.Unlock()
+		for _, cb := range cbs {
+			cb(client)
+		}
+		return err
+	}
+	client, err := newWsClient(wsUrl, e.OnWsMsg, e.OnWsErr, onClosed, onReCon, params, e.DebugWS)
 	if err != nil {
 		return nil, err
 	}
@@ -294,21 +311,32 @@ func WriteOutChan[T any](e *Exchange, chanKey string, msg T, popIfNeed bool) boo
 			log.Error("out chan type error", zap.String("k", chanKey))
 			return false
 		}
-		select {
-		case out <- msg:
-		default:
-			if !popIfNeed {
-				log.Error("out chan full", zap.String("k", chanKey))
-				return false
-			}
-			// chan通道满了，弹出最早的消息，重新发送
-			<-out
-			out <- msg
+		if !utils.WriteChan(out, msg, popIfNeed) {
+			log.Error("out chan full", zap.String("k", chanKey))
+			return false
 		}
 	}
 	return outOk
 }
 
+/*
+SetWsReConListener
+listen reconnection of all websocket clients after exchange's OnWsReCon, cb=nil to remove
+监听所有websocket客户端的重连，在交易所OnWsReCon之后调用，cb为nil时移除
+*/
+func (e *Exchange) SetWsReConListener(key string, cb func(client *WsClient)) {
+	e.wsReConLock.Lock()
+	defer e.wsReConLock.Unlock()
+	if cb == nil {
+		delete(e.wsReConCbs, key)
+		return
+	}
+	if e.wsReConCbs == nil {
+		e.wsReConCbs = make(map[string]func(client *WsClient))
+	}
+	e.wsReConCbs[key] = cb
+}
+
 func (e *Exchange) AddWsChanRefs(chanKey string, keys ...string) {
 	data, ok := e.WsChanRefs[chanKey]
 	if !ok {
@@ -405,9 +433,11 @@ func (c *WsClient) Write(conn *AsyncConn, msg interface{}, info *WsJobInfo) *err
 		if info.ID == "" {
 			return errs.NewMsg(errs.CodeParamRequired, "WsJobInfo.ID is required")
 		}
+		c.jobLock.Lock()
 		if _, ok := c.JobInfos[info.ID]; !ok {
 			c.JobInfos[info.ID] = info
 		}
+		c.jobLock.Unlock()
 	}
 	if c.Debug {
 		log.Debug("write ws msg", zap.String("url", c.URL), zap.Int("id", conn.GetID()),
@@ -417,6 +447,13 @@ func (c *WsClient) Write(conn *AsyncConn, msg interface{}, info *WsJobInfo) *err
 	return nil
 }
 
+// DelJobInfo remove job info of request id, used when waiting result timeout 删除请求的任务信息，等待结果超时时使用
+func (c *WsClient) DelJobInfo(id string) {
+	c.jobLock.Lock()
+	delete(c.JobInfos, id)
+	c.jobLock.Unlock()
+}
+
 func (c *WsClient) Close() {
 	for _, conn := range c.Conns {
 		conn.control <- ctrlDoClose
@@ -531,10 +568,15 @@ func (c *WsClient) HandleRawMsg(msgRaw []byte) {
 		return
 	}
 	if !msg.IsArray && msg.ID != "" {
-		if sub, ok := c.JobInfos[msg.ID]; ok && sub.Method != nil {
+		c.jobLock.Lock()
+		sub, ok := c.JobInfos[msg.ID]
+		if ok && sub.Method != nil {
+			delete(c.JobInfos, msg.ID)
+		}
+		c.jobLock.Unlock()
+		if ok && sub.Method != nil {
 			// 订阅信息中提供了处理函数，则调用处理函数
 			sub.Method(c, msg.Object, sub)
-			delete(c.JobInfos, msg.ID)
 			return
 		}
 	}